@@ -62,6 +62,51 @@ const docTemplate = `{
                         "description": "RBAC üçün icazə adı (məs: DELETE_USER)",
                         "name": "privilege",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "privilege əvəzinə: route reyestrindəki servis (məs: users)",
+                        "name": "service",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "privilege əvəzinə: orijinal sorğunun HTTP metodu (path ilə tələb olunur)",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "privilege əvəzinə: orijinal sorğunun path-ı, tələb olunan permission-lar reyestrdən tapılır (məs: /users/7)",
+                        "name": "path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resurs tipi, verilərsə həmin resursa bağlı təyinatlar da nəzərə alınır (məs: invoice)",
+                        "name": "resource",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resurs ID-si (məs: tenant/42/invoices/7)",
+                        "name": "resource_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Qiymətləndirmə izi qaytarılsın? (token yoxlamaları, rolların təyinatları, müddətlər)",
+                        "name": "explain",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Şərtli təyinatlar üçün sorğu konteksti, məs: {\\",
+                        "name": "context",
+                        "in": "body",
+                        "schema": {
+                            "type": "object"
+                        }
                     }
                 ],
                 "responses": {
@@ -72,7 +117,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Privilege is required for RBAC check",
+                        "description": "Privilege or method and path are required for RBAC check",
                         "schema": {
                             "type": "string"
                         }
@@ -90,11 +135,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/authz/logout": {
+            },
             "post": {
-                "description": "İstifadəçi tokenini blackliste əlavə edir (logout əməliyyatı).",
+                "description": "Token JWT ilə doğrulanır. İstəyə əsasən blacklist və RBAC permission da yoxlanır.",
                 "consumes": [
                     "application/json"
                 ],
@@ -104,7 +147,7 @@ const docTemplate = `{
                 "tags": [
                     "Authorization"
                 ],
-                "summary": "Logout (Tokeni deaktiv edir)",
+                "summary": "JWT və RBAC yoxlama",
                 "parameters": [
                     {
                         "type": "string",
@@ -112,11 +155,89 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "JWT yoxlanılsın?",
+                        "name": "check_jwt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Blacklist yoxlanılsın?",
+                        "name": "check_blacklist",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "RBAC yoxlanılsın?",
+                        "name": "check_rbac",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RBAC üçün icazə adı (məs: DELETE_USER)",
+                        "name": "privilege",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "privilege əvəzinə: route reyestrindəki servis (məs: users)",
+                        "name": "service",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "privilege əvəzinə: orijinal sorğunun HTTP metodu (path ilə tələb olunur)",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "privilege əvəzinə: orijinal sorğunun path-ı, tələb olunan permission-lar reyestrdən tapılır (məs: /users/7)",
+                        "name": "path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resurs tipi, verilərsə həmin resursa bağlı təyinatlar da nəzərə alınır (məs: invoice)",
+                        "name": "resource",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resurs ID-si (məs: tenant/42/invoices/7)",
+                        "name": "resource_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Qiymətləndirmə izi qaytarılsın? (token yoxlamaları, rolların təyinatları, müddətlər)",
+                        "name": "explain",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Şərtli təyinatlar üçün sorğu konteksti, məs: {\\",
+                        "name": "context",
+                        "in": "body",
+                        "schema": {
+                            "type": "object"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Logged out",
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Privilege or method and path are required for RBAC check",
                         "schema": {
                             "type": "string"
                         }
@@ -126,78 +247,162 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "403": {
+                        "description": "Permission denied",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
         },
-        "/api/v1/authz/logout-all": {
+        "/api/v1/authz/check/batch": {
             "post": {
-                "description": "Verilən ` + "`" + `user_id` + "`" + `-yə aid olan bütün JWT-lərin JTI-lərini blackliste əlavə edir və bütün instansiyalara yayır.",
+                "description": "Token bir dəfə doğrulanır, checks siyahısındakı hər privilege (və resurs) üçün ayrıca qərar qaytarılır.\nmode=all (default) bütün, mode=any isə ən azı bir yoxlamanın keçməsini tələb edir; qərarlar hər iki halda bütün elementlər üçün hesablanır.\nCavabdakı açar elementin key-i, verilməzsə privilege və ya privilege@resource:resource_id olur.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
                     "Authorization"
                 ],
-                "summary": "İstifadəçinin bütün tokenlərini bloklayır",
+                "summary": "Bir neçə privilege-in RBAC yoxlaması",
                 "parameters": [
                     {
-                        "description": "Bloklanacaq istifadəçinin ID-si",
+                        "type": "string",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "JWT yoxlanılsın?",
+                        "name": "check_jwt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Blacklist yoxlanılsın?",
+                        "name": "check_blacklist",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Yoxlanılacaq privilege-lər",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.LogoutAllRequest"
+                            "$ref": "#/definitions/handler.AuthzBatchCheckRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "All user tokens blacklisted",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handler.AuthzBatchCheckResponse"
                         }
                     },
                     "400": {
-                        "description": "user_id is required",
+                        "description": "Invalid body",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/handler.AuthzBatchCheckResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AuthzBatchCheckResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/authz/permissions": {
+        "/api/v1/authz/forward-auth": {
             "get": {
+                "description": "Orijinal metod X-Forwarded-Method (və ya X-Original-Method), URI isə X-Forwarded-Uri (və ya X-Original-URI) header-indən oxunur\nvə route reyestri ilə tələb olunan permission-lara çevrilir (service verilməzsə GATEWAY_SERVICE). Token /check-dəki kimi (JWT + blacklist) yoxlanılır.\nURI percent-decode və path.Clean ilə normallaşdırılır, bundan sonra \"..\" seqmenti qalan path rədd edilir.\nToken-siz yalnız default tenant-ın public route-ları keçir, tokenli sorğu token-in tenant-ının reyestri ilə həll olunur.\nİcazə verildikdə 200 və X-User-Id, X-User-Role, X-Tenant-Id header-ləri qaytarılır.\nŞərtlərdəki ip /check-dəki kimi müştəri IP-sidir: PROXY_HEADER yalnız TRUSTED_PROXIES-dən gələn sorğularda nəzərə alınır, əks halda bağlantının ünvanı.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Permission"
+                    "Authorization"
+                ],
+                "summary": "Ingress üçün forward-auth",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Route reyestrindəki servis (verilməzsə GATEWAY_SERVICE)",
+                        "name": "service",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Orijinal metod (Traefik)",
+                        "name": "X-Forwarded-Method",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Orijinal URI (Traefik)",
+                        "name": "X-Forwarded-Uri",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Orijinal metod (nginx)",
+                        "name": "X-Original-Method",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Orijinal URI (nginx)",
+                        "name": "X-Original-URI",
+                        "in": "header"
+                    }
                 ],
-                "summary": "Bütün permission-ları qaytarır",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Permission"
-                            }
+                            "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Server error",
+                    "400": {
+                        "description": "Missing X-Forwarded-Uri / X-Original-URI header",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AuthzCheckResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Permission denied or no route rule",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AuthzCheckResponse"
+                        }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/authz/logout": {
             "post": {
+                "description": "İstifadəçi tokenini blackliste əlavə edir (logout əməliyyatı).",
                 "consumes": [
                     "application/json"
                 ],
@@ -205,29 +410,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Permission"
+                    "Authorization"
                 ],
-                "summary": "Yeni permission yaradır",
+                "summary": "Logout (Tokeni deaktiv edir)",
                 "parameters": [
                     {
-                        "description": "Yeni permission",
-                        "name": "permission",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.Permission"
-                        }
+                        "type": "string",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Logged out",
                         "schema": {
-                            "$ref": "#/definitions/model.Permission"
+                            "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "Invalid body",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "string"
                         }
@@ -241,23 +444,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/authz/permissions/permissions-with-roles": {
-            "get": {
-                "produces": [
+        "/api/v1/authz/logout-all": {
+            "post": {
+                "description": "Verilən ` + "`" + `tenant_id` + "`" + ` və ` + "`" + `user_id` + "`" + ` üçün indiyə qədər buraxılmış bütün JWT-ləri ləğv edir (iat \u003c= indiki an). Cutoff DB-də saxlanılır və bütün instansiyalara yayılır. Başqa tenant-da eyni ` + "`" + `user_id` + "`" + `-li tokenlərə təsir etmir.",
+                "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "text/plain"
+                ],
                 "tags": [
-                    "Permission"
+                    "Authorization"
+                ],
+                "summary": "İstifadəçinin bütün tokenlərini bloklayır",
+                "parameters": [
+                    {
+                        "description": "Bloklanacaq istifadəçinin tenant-ı və ID-si",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.LogoutAllRequest"
+                        }
+                    }
                 ],
-                "summary": "Permission-ları və aid olduqları rolları qaytarır",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "All user tokens blacklisted",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Permission"
-                            }
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "user_id is required",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "500": {
@@ -269,9 +490,131 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/authz/permissions/{id}": {
-            "put": {
-                "consumes": [
+        "/api/v1/authz/permissions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Permission"
+                ],
+                "summary": "Bütün permission-ları qaytarır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Permission"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Permission"
+                ],
+                "summary": "Yeni permission yaradır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Yeni permission",
+                        "name": "permission",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PermissionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Permission"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body or permission pattern",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/permissions/permissions-with-roles": {
+            "get": {
+                "description": "Hər təyinat effekti (allow/deny), resurs scope-u və şərti ilə göstərilir.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Permission"
+                ],
+                "summary": "Permission-ları və təyin olunduqları rolları qaytarır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PermissionWithRolesDTO"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/permissions/{id}": {
+            "put": {
+                "consumes": [
                     "application/json"
                 ],
                 "produces": [
@@ -282,6 +625,12 @@ const docTemplate = `{
                 ],
                 "summary": "Mövcud permission-u yeniləyir",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
                     {
                         "type": "integer",
                         "description": "Permission ID",
@@ -295,7 +644,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Permission"
+                            "$ref": "#/definitions/dto.PermissionRequest"
                         }
                     }
                 ],
@@ -332,6 +681,12 @@ const docTemplate = `{
                 ],
                 "summary": "Permission-u ID ilə silir",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
                     {
                         "type": "integer",
                         "description": "Permission ID",
@@ -358,14 +713,21 @@ const docTemplate = `{
         },
         "/api/v1/authz/permissions/{id}/roles": {
             "get": {
+                "description": "Hər təyinat effekti (allow/deny), resurs scope-u və şərti ilə göstərilir.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Permission"
                 ],
-                "summary": "Verilmiş permission ID üçün aid olduğu rolları qaytarır",
+                "summary": "Verilmiş permission ID üçün təyin olunduğu rolları qaytarır",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
                     {
                         "type": "integer",
                         "description": "Permission ID",
@@ -380,7 +742,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.Role"
+                                "$ref": "#/definitions/dto.RoleGrantDTO"
                             }
                         }
                     },
@@ -399,34 +761,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/authz/roles": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Role"
-                ],
-                "summary": "Mövcud bütün rolları qaytarır",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Role"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Server error",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            },
+        "/api/v1/authz/relations/check": {
             "post": {
+                "description": "Birbaşa tuple-lar, userset-lər (group:eng#member) və namespace konfiqurasiyasındakı rewrite-lar\n(computed_userset, tuple_to_userset) nəzərə alınır.",
                 "consumes": [
                     "application/json"
                 ],
@@ -434,17 +771,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Role"
+                    "Relation"
                 ],
-                "summary": "Yeni rol yaradır",
+                "summary": "Subject-in obyekt üzərində relation-a malik olduğunu yoxlayır",
                 "parameters": [
                     {
-                        "description": "Yeni rol",
-                        "name": "role",
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Yoxlanılan tuple",
+                        "name": "check",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Role"
+                            "$ref": "#/definitions/dto.RelationTupleDTO"
                         }
                     }
                 ],
@@ -452,11 +795,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Role"
+                            "$ref": "#/definitions/dto.RelationCheckResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid body",
+                        "description": "Invalid tuple or unknown namespace/relation",
                         "schema": {
                             "type": "string"
                         }
@@ -470,23 +813,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/authz/roles/roles-with-permissions": {
+        "/api/v1/authz/relations/expand": {
             "get": {
+                "description": "Birbaşa tuple-lardakı userset-lər yarpaq kimi qalır, onları ayrıca expand etmək olar.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Role"
+                    "Relation"
+                ],
+                "summary": "object#relation-ın userset ağacını qaytarır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Obyekt, məs. document:123",
+                        "name": "object",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Relation, məs. viewer",
+                        "name": "relation",
+                        "in": "query",
+                        "required": true
+                    }
                 ],
-                "summary": "Rolları və onlara bağlı permission-ları qaytarır",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Role"
-                            }
+                            "$ref": "#/definitions/rebac.Node"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid object or unknown namespace/relation",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "500": {
@@ -498,8 +867,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/authz/roles/{id}": {
-            "put": {
+        "/api/v1/authz/relations/list-objects": {
+            "post": {
                 "consumes": [
                     "application/json"
                 ],
@@ -507,24 +876,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Role"
+                    "Relation"
                 ],
-                "summary": "Mövcud rolu yeniləyir",
+                "summary": "Namespace-də subject-in relation-a malik olduğu obyektləri qaytarır",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Role ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
                     },
                     {
-                        "description": "Yenilənmiş rol məlumatı",
-                        "name": "role",
+                        "description": "Namespace, relation və subject",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Role"
+                            "$ref": "#/definitions/dto.ListObjectsRequest"
                         }
                     }
                 ],
@@ -532,46 +900,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Role"
+                            "$ref": "#/definitions/dto.ListObjectsResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid input",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "404": {
-                        "description": "Role not found",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "500": {
-                        "description": "Server error",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            },
-            "delete": {
-                "tags": [
-                    "Role"
-                ],
-                "summary": "Rolu ID-yə görə silir",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Role ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content",
+                        "description": "Invalid subject or unknown namespace/relation",
                         "schema": {
                             "type": "string"
                         }
@@ -585,22 +918,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/authz/roles/{id}/permissions": {
+        "/api/v1/authz/relations/tuples": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Role"
+                    "Relation"
                 ],
-                "summary": "Verilmiş role ID üçün permission-ları qaytarır",
+                "summary": "Obyektin birbaşa yazılmış tuple-larını qaytarır",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Role ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Obyekt, məs. folder:9",
+                        "name": "object",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Relation (verilməzsə hamısı)",
+                        "name": "relation",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -609,12 +954,12 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.Permission"
+                                "$ref": "#/definitions/dto.RelationTupleDTO"
                             }
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Invalid object",
                         "schema": {
                             "type": "string"
                         }
@@ -626,28 +971,31 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/authz/roles/{roleID}/permissions/{permID}": {
+            },
             "post": {
-                "tags": [
-                    "Role-Permission"
+                "description": "Relation konfiqurasiyada birbaşa tuple qəbul etməlidir (\"this\"). Tuple artıq varsa heç nə dəyişmir.",
+                "consumes": [
+                    "application/json"
                 ],
-                "summary": "Role-a permission təyin edir",
+                "tags": [
+                    "Relation"
+                ],
+                "summary": "Relation tuple yazır",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Role ID",
-                        "name": "roleID",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
                     },
                     {
-                        "type": "integer",
-                        "description": "Permission ID",
-                        "name": "permID",
-                        "in": "path",
-                        "required": true
+                        "description": "Yeni tuple",
+                        "name": "tuple",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RelationTupleDTO"
+                        }
                     }
                 ],
                 "responses": {
@@ -657,6 +1005,12 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "400": {
+                        "description": "Invalid tuple or unknown namespace/relation",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
@@ -666,22 +1020,253 @@ const docTemplate = `{
                 }
             },
             "delete": {
+                "consumes": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Role-Permission"
+                    "Relation"
                 ],
-                "summary": "Role-dan permission silir",
+                "summary": "Relation tuple silir",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Silinəcək tuple",
+                        "name": "tuple",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RelationTupleDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid tuple",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/roles": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Mövcud bütün rolları qaytarır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Role"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Yeni rol yaradır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Yeni rol",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/roles/roles-with-permissions": {
+            "get": {
+                "description": "Hər təyinat effekti (allow/deny), resurs scope-u və şərti ilə göstərilir; miras alınanlar üçün effective-permissions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Rolları və onlara birbaşa təyin olunmuş permission-ları qaytarır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.RoleWithPermissionsDTO"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/roles/{id}": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Mövcud rolu yeniləyir",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
                     {
                         "type": "integer",
                         "description": "Role ID",
-                        "name": "roleID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "description": "Yenilənmiş rol məlumatı",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Rolu ID-yə görə silir",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
                     {
                         "type": "integer",
-                        "description": "Permission ID",
-                        "name": "permID",
+                        "description": "Role ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -701,22 +1286,2008 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.LogoutAllRequest": {
-            "type": "object",
-            "properties": {
-                "user_id": {
-                    "type": "string"
-                }
-            }
-        },
-        "model.Permission": {
-            "type": "object"
         },
-        "model.Role": {
-            "type": "object"
+        "/api/v1/authz/roles/{id}/effective-permissions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role-Hierarchy"
+                ],
+                "summary": "Rolun effektiv allow və deny permission-larını (miras alınanlar daxil) qaytarır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EffectivePermissionsDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/roles/{id}/parents": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role-Hierarchy"
+                ],
+                "summary": "Rolun birbaşa valideyn rollarını qaytarır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.RoleDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/roles/{id}/parents/{parentID}": {
+            "post": {
+                "tags": [
+                    "Role-Hierarchy"
+                ],
+                "summary": "Rola valideyn rol əlavə edir (rol valideynin permission-larını miras alır)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Parent Role ID",
+                        "name": "parentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Role inheritance would create a cycle",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "Role-Hierarchy"
+                ],
+                "summary": "Roldan valideyn rol əlaqəsini silir",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Parent Role ID",
+                        "name": "parentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/roles/{id}/permissions": {
+            "get": {
+                "description": "Hər təyinat effekti (allow/deny), resurs scope-u və şərti ilə göstərilir; miras alınanlar üçün effective-permissions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Verilmiş role ID üçün birbaşa təyin olunmuş permission-ları qaytarır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PermissionGrantDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/roles/{roleID}/permissions/{permID}": {
+            "post": {
+                "description": "effect=deny ilə təyin olunan permission rolun (və ondan miras alan rolların) bütün allow-larından üstündür.\nresource_type verilərsə təyinat yalnız həmin tipdə və ID-si resource_pattern-ə uyğun resurslara aiddir\n(path.Match sintaksisi, məs. invoice + tenant/42/invoices/*). resource_pattern boşdursa tipin bütün resursları.\ncondition verilərsə təyinat yalnız şərt doğru olduqda tətbiq olunur, məs. ip in 10.0.0.0/8 və ya claims.department == resource.department.\nEyni scope-lu təyinat artıq varsa onun effekti və şərti yenilənir.",
+                "tags": [
+                    "Role-Permission"
+                ],
+                "summary": "Role-a permission təyin edir",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Permission ID",
+                        "name": "permID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "allow",
+                            "deny"
+                        ],
+                        "type": "string",
+                        "default": "allow",
+                        "description": "allow və ya deny",
+                        "name": "effect",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resurs tipi, məs. invoice (* – istənilən tip)",
+                        "name": "resource_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resurs ID pattern-i, məs. tenant/42/invoices/*",
+                        "name": "resource_pattern",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ABAC şərti, məs. time between 08:00-18:00",
+                        "name": "condition",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid effect, resource scope or condition",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Role or permission not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "resource_type verilməzsə permission-un rola bütün təyinatları (scope-lu olanlar daxil) silinir,\nverilərsə yalnız həmin scope-lu təyinat.",
+                "tags": [
+                    "Role-Permission"
+                ],
+                "summary": "Role-dan permission silir",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Permission ID",
+                        "name": "permID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resurs tipi",
+                        "name": "resource_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resurs ID pattern-i",
+                        "name": "resource_pattern",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid resource scope",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/routes": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Route"
+                ],
+                "summary": "Route reyestrini uyğunlaşma sırası ilə qaytarır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Yalnız bu servisin route-ları",
+                        "name": "service",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.RouteDTO"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "path pkg/route sintaksisindədir: \"*\" bir seqment, sonuncu \"**\" qalan bütün seqmentlər (məs. /api/users/*).\npermissions tenant-ın kataloqundakı konkret (wildcard-sız) permission-lardır və hamısı tələb olunur;\nboşdursa etibarlı token kifayətdir, public route isə token tələb etmir.\nBir neçə route uyğun gələrsə priority-si böyük olan, bərabər olduqda əvvəl yaradılan qalib gəlir.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Route"
+                ],
+                "summary": "Servis route-una tələb olunan permission-ları təyin edir",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Yeni route",
+                        "name": "route",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RouteDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RouteDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid route or unknown permission",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Route already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/routes/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Route"
+                ],
+                "summary": "Route-u ID ilə qaytarır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Route ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RouteDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Route not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Route"
+                ],
+                "summary": "Mövcud route-u yeniləyir",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Route ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Yenilənmiş route",
+                        "name": "route",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RouteDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RouteDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid route or unknown permission",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Route not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Route already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Silinmiş route-a uyğun gələn sorğular (başqa route uyğun gəlmirsə) /check-də rədd edilir.",
+                "tags": [
+                    "Route"
+                ],
+                "summary": "Route-u ID ilə silir",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Route ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/users": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "İstifadəçiləri qaytarır, username və ya email verilərsə həmin istifadəçini axtarır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Username ilə axtarış",
+                        "name": "username",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email ilə axtarış",
+                        "name": "email",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.UserDTO"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Rollar /users/{id}/roles endpoint-ləri ilə təyin olunur, role_id köhnə tək rol sahəsidir.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Yeni istifadəçi yaradır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Yeni istifadəçi",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body or unknown role_id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Username or email already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/users/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "İstifadəçini ID-yə görə qaytarır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Mövcud istifadəçini yeniləyir",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Yenilənmiş istifadəçi məlumatı",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or unknown role_id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Username or email already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "User"
+                ],
+                "summary": "İstifadəçini silir (soft-delete, restore ilə bərpa oluna bilər)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/users/{id}/effective-permissions": {
+            "get": {
+                "description": "Token-dəki rollar burada nəzərə alınmır.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "İstifadəçinin server-side rollarından (miras alınanlar daxil) toplanmış permission-ları qaytarır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserEffectivePermissionsDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/users/{id}/restore": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Silinmiş istifadəçini bərpa edir",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/users/{id}/roles": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User-Role"
+                ],
+                "summary": "İstifadəçiyə server-side təyin olunmuş rolları qaytarır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.RoleDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/users/{id}/roles/{roleID}": {
+            "post": {
+                "description": "/authz/check token-dəki rollarla bu təyinatların birləşməsini yoxlayır.",
+                "tags": [
+                    "User-Role"
+                ],
+                "summary": "İstifadəçiyə rol təyin edir",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "User or role not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "User-Role"
+                ],
+                "summary": "İstifadəçidən rolu geri alır",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (verilməzsə default tenant)",
+                        "name": "X-Tenant-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/authz/whoami": {
+            "get": {
+                "description": "Token Authorize ilə eyni qaydalarla doğrulanır; istifadəçi, rollar, effektiv permission-lar və tokenin bitmə vaxtı qaytarılır.\npermissions tenant-ın permission kataloqundakı konkret adlardır (wildcard-lar açılmış, deny-lar çıxılmış);\nşərtli təyinatlar müştərinin IP-si və cari vaxt üzərində qiymətləndirilir.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authorization"
+                ],
+                "summary": "Tokenin effektiv icazələri",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer {token}",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "JWT yoxlanılsın?",
+                        "name": "check_jwt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Blacklist yoxlanılsın?",
+                        "name": "check_blacklist",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.WhoAmIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AuthzCheckResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.EffectivePermissionsDTO": {
+            "type": "object",
+            "properties": {
+                "allow": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "deny": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role": {
+                    "type": "string"
+                },
+                "scoped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ScopedPermissionsDTO"
+                    }
+                }
+            }
+        },
+        "dto.ListObjectsRequest": {
+            "type": "object",
+            "properties": {
+                "namespace": {
+                    "type": "string"
+                },
+                "relation": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ListObjectsResponse": {
+            "type": "object",
+            "properties": {
+                "namespace": {
+                    "type": "string"
+                },
+                "objects": {
+                    "description": "obyekt ID-ləri",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "relation": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PermissionGrantDTO": {
+            "type": "object",
+            "properties": {
+                "condition": {
+                    "type": "string"
+                },
+                "effect": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "resource_pattern": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PermissionRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PermissionWithRolesDTO": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RoleGrantDTO"
+                    }
+                }
+            }
+        },
+        "dto.RelationCheckResponse": {
+            "type": "object",
+            "properties": {
+                "allowed": {
+                    "type": "boolean"
+                },
+                "object": {
+                    "type": "string"
+                },
+                "relation": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RelationTupleDTO": {
+            "type": "object",
+            "properties": {
+                "object": {
+                    "type": "string"
+                },
+                "relation": {
+                    "type": "string"
+                },
+                "subject": {
+                    "description": "namespace:id və ya namespace:id#relation",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RoleDTO": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RoleGrantDTO": {
+            "type": "object",
+            "properties": {
+                "condition": {
+                    "type": "string"
+                },
+                "effect": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "resource_pattern": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RoleRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RoleWithPermissionsDTO": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PermissionGrantDTO"
+                    }
+                }
+            }
+        },
+        "dto.RouteDTO": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "public": {
+                    "type": "boolean"
+                },
+                "service": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ScopedPermissionsDTO": {
+            "type": "object",
+            "properties": {
+                "allow": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "condition": {
+                    "type": "string"
+                },
+                "deny": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "resource_pattern": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.UserDTO": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "role_id": {
+                    "type": "integer"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RoleDTO"
+                    }
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.UserEffectivePermissionsDTO": {
+            "type": "object",
+            "properties": {
+                "allow": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "deny": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scoped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ScopedPermissionsDTO"
+                    }
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.UserRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "role_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AuthzBatchCheckItem": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "description": "cavabdakı açar; verilməzsə privilege[@resource:resource_id]",
+                    "type": "string"
+                },
+                "privilege": {
+                    "type": "string"
+                },
+                "resource": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AuthzBatchCheckRequest": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.AuthzBatchCheckItem"
+                    }
+                },
+                "context": {
+                    "description": "şərtli təyinatlar üçün sorğu konteksti",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "mode": {
+                    "description": "all və ya any",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AuthzBatchCheckResponse": {
+            "type": "object",
+            "properties": {
+                "decisions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/handler.AuthzBatchDecision"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "result": {
+                    "description": "mode üzrə birləşdirilmiş nəticə",
+                    "type": "boolean"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "description": "token etibarlıdır və mode üzrə nəticə müsbətdir",
+                    "type": "boolean"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AuthzBatchDecision": {
+            "type": "object",
+            "properties": {
+                "allowed": {
+                    "type": "boolean"
+                },
+                "condition": {
+                    "type": "string"
+                },
+                "effect": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AuthzCheckResponse": {
+            "type": "object",
+            "properties": {
+                "blacklist_checked": {
+                    "type": "boolean"
+                },
+                "blacklisted": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "description": "Əgər status=false olsa, səbəb burda olur",
+                    "type": "string"
+                },
+                "explain": {
+                    "description": "explain=true olduqda qiymətləndirmə izi",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handler.AuthzExplanation"
+                        }
+                    ]
+                },
+                "jwt_validated": {
+                    "type": "boolean"
+                },
+                "privilege_checked": {
+                    "type": "string"
+                },
+                "rbac_checked": {
+                    "type": "boolean"
+                },
+                "rbac_condition": {
+                    "description": "qalib qaydanın şərti (varsa)",
+                    "type": "string"
+                },
+                "rbac_effect": {
+                    "description": "qalib gələn qaydanın effekti (allow/deny)",
+                    "type": "string"
+                },
+                "rbac_result": {
+                    "type": "boolean"
+                },
+                "rbac_role": {
+                    "description": "qaydanın tapıldığı rol",
+                    "type": "string"
+                },
+                "rbac_rule": {
+                    "description": "qalib gələn permission pattern-i",
+                    "type": "string"
+                },
+                "rbac_scope": {
+                    "description": "qalib qayda resursa bağlıdırsa onun scope-u (tip:pattern)",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Token rədd edilibsə, hansı yoxlamadan keçmədiyi (expired, audience_mismatch, ...)",
+                    "type": "string"
+                },
+                "resource": {
+                    "description": "yoxlanılan resurs tipi",
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "roles": {
+                    "description": "RBAC-da nəzərə alınan bütün rollar (token + user_roles)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "route": {
+                    "description": "uyğun gələn reyestr qaydası (metod və path pattern-i)",
+                    "type": "string"
+                },
+                "service": {
+                    "description": "path ilə yoxlamada servis",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "boolean"
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.AuthzExplanation": {
+            "type": "object",
+            "properties": {
+                "claims": {
+                    "$ref": "#/definitions/handler.ExplainClaims"
+                },
+                "rbac_duration_us": {
+                    "type": "integer"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ExplainRole"
+                    }
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ExplainTokenStep"
+                    }
+                },
+                "total_duration_us": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.ExplainClaims": {
+            "type": "object",
+            "properties": {
+                "aud": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "exp": {
+                    "type": "string"
+                },
+                "iat": {
+                    "type": "string"
+                },
+                "iss": {
+                    "type": "string"
+                },
+                "jti": {
+                    "type": "string"
+                },
+                "nbf": {
+                    "type": "string"
+                },
+                "roles": {
+                    "description": "tokendəki role və roles",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "sub": {
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ExplainGrant": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "boolean"
+                },
+                "condition": {
+                    "type": "string"
+                },
+                "decisive": {
+                    "type": "boolean"
+                },
+                "effect": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "description": "matched, no_match, out_of_scope, condition_false, condition_error",
+                    "type": "string"
+                },
+                "pattern": {
+                    "type": "string"
+                },
+                "resource_pattern": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ExplainRole": {
+            "type": "object",
+            "properties": {
+                "grants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ExplainGrant"
+                    }
+                },
+                "known": {
+                    "description": "rol tenant-da mövcuddur",
+                    "type": "boolean"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ExplainTokenStep": {
+            "type": "object",
+            "properties": {
+                "check": {
+                    "description": "jwt, blacklist, revocation",
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "duration_us": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "passed, failed, skipped",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.LogoutAllRequest": {
+            "type": "object",
+            "properties": {
+                "tenant_id": {
+                    "description": "Boş olarsa default tenant; cutoff yalnız həmin tenant-ın tokenlərinə (claims.tenant_id) tətbiq olunur",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.WhoAmIResponse": {
+            "type": "object",
+            "properties": {
+                "allow": {
+                    "description": "irsiyyət daxil scope-suz allow pattern-ləri",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "deny": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "issued_at": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "description": "kataloqdakı konkret permission-lardan icazə veriləni (wildcard-lar açılmış, deny-lar çıxılmış)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role": {
+                    "type": "string"
+                },
+                "roles": {
+                    "description": "token + server-side rollar",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scoped": {
+                    "description": "resursa və ya şərtə bağlı təyinatlar",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ScopedPermissionsDTO"
+                    }
+                },
+                "tenant_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Permission": {
+            "type": "object"
+        },
+        "model.Role": {
+            "type": "object"
+        },
+        "rebac.Node": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rebac.Node"
+                    }
+                },
+                "kind": {
+                    "description": "union, this, computed_userset, tuple_to_userset",
+                    "type": "string"
+                },
+                "object": {
+                    "type": "string"
+                },
+                "relation": {
+                    "type": "string"
+                },
+                "subjects": {
+                    "description": "this: birbaşa yazılmış subject-lər (userset-lər açılmır)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
         }
     }
 }`
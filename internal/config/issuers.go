@@ -0,0 +1,50 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// IssuerConfig JWT_ISSUERS_FILE-dakı tək etibarlı issuer.
+// Açar mənbəyi kimi jwks_url və ya public_key_dir verilə bilər,
+// heç biri verilməyibsə qlobal PUBLIC_KEY_DIR / JWKS_URL istifadə olunur.
+//
+//	[
+//	  {"issuer": "https://idp.example.com", "audiences": ["ms-authz"], "jwks_url": "https://idp.example.com/jwks.json"},
+//	  {"issuer": "legacy-auth", "public_key_dir": "/app/keys"}
+//	]
+type IssuerConfig struct {
+	Issuer       string   `json:"issuer"`
+	Audiences    []string `json:"audiences"`
+	JWKSURL      string   `json:"jwks_url"`
+	PublicKeyDir string   `json:"public_key_dir"`
+}
+
+func LoadIssuers(path string) ([]IssuerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read issuers file: %w", err)
+	}
+
+	var issuers []IssuerConfig
+	if err := json.Unmarshal(data, &issuers); err != nil {
+		return nil, fmt.Errorf("invalid issuers file: %w", err)
+	}
+
+	seen := make(map[string]bool, len(issuers))
+	for _, iss := range issuers {
+		if iss.Issuer == "" {
+			return nil, errors.New("issuer must not be empty")
+		}
+		if seen[iss.Issuer] {
+			return nil, fmt.Errorf("duplicate issuer %q", iss.Issuer)
+		}
+		if iss.JWKSURL != "" && iss.PublicKeyDir != "" {
+			return nil, fmt.Errorf("issuer %q: jwks_url and public_key_dir are mutually exclusive", iss.Issuer)
+		}
+		seen[iss.Issuer] = true
+	}
+	return issuers, nil
+}
@@ -0,0 +1,16 @@
+package config
+
+import (
+	"fmt"
+	"ms-authz/pkg/rebac"
+	"os"
+)
+
+// LoadRelationConfig REBAC_CONFIG_FILE-dakı namespace/relation konfiqurasiyasını oxuyur (format rebac.Config-də)
+func LoadRelationConfig(path string) (*rebac.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read relation config file: %w", err)
+	}
+	return rebac.ParseConfig(data)
+}
@@ -1,60 +1,178 @@
 package consumer
 
 import (
-"encoding/json"
-"log"
-"ms-authz/internal/service"
-"github.com/rabbitmq/amqp091-go"
+	"encoding/json"
+	"fmt"
+	"log"
+	"ms-authz/internal/dto"
+	"ms-authz/internal/infrastructure/mq"
+	"ms-authz/internal/service"
+
+	"github.com/rabbitmq/amqp091-go"
 )
 
-func StartConsumers(ch *amqp091.Channel, authSvc *service.AuthService, rbacSvc *service.RBACService) {
-	declareExchangeAndQueue(ch, "auth.tokens.fanout", "auth.tokens.queue", func(d amqp091.Delivery) {
-		var e struct {
-			Event string `json:"event"`
-			JTI   string `json:"jti"`
-			Exp   int64  `json:"exp"`
-		}
-		if err := json.Unmarshal(d.Body, &e); err == nil && e.Event == "TOKEN_BLACKLISTED" {
-			log.Println("✅ Received TOKEN_BLACKLISTED")
-			authSvc.HandleBlacklistEvent(e.JTI, e.Exp)
-		}
+// Eyni anda emal olunmamış (ack gözləyən) maksimum mesaj sayı
+const prefetchCount = 32
+
+// Mesajın parse oluna bilməməsi – yenidən göndərilməsinin mənası yoxdur
+type poisonError struct {
+	err error
+}
+
+func (e poisonError) Error() string {
+	return e.err.Error()
+}
+
+// StartConsumers hər instansiya üçün fanout exchange-lərə ayrıca (exclusive) queue bağlayır.
+// Beləliklə hər event bütün instansiyalara çatır (broadcast), paylanmır (load-balance).
+// Bağlantı bərpa olunduqda MQ setup-ı yeni channel üzərində təkrarlayır.
+func StartConsumers(m *mq.MQ, authSvc *service.AuthService, rbacSvc *service.RBACService) error {
+	return m.Subscribe(func(ch mq.Channel) error {
+		return setup(ch, authSvc, rbacSvc)
 	})
+}
+
+func setup(ch mq.Channel, authSvc *service.AuthService, rbacSvc *service.RBACService) error {
+	if err := ch.Qos(prefetchCount, 0, false); err != nil {
+		return fmt.Errorf("failed to set QoS: %w", err)
+	}
 
-	declareExchangeAndQueue(ch, "rbac.update.fanout", "rbac.update.queue", func(d amqp091.Delivery) {
-		var e struct {
-			Event string `json:"event"`
+	if err := consume(ch, "auth.tokens.fanout", func(body []byte) error {
+		return handleTokenEvent(body, authSvc)
+	}); err != nil {
+		return err
+	}
+
+	if err := consume(ch, "rbac.update.fanout", func(body []byte) error {
+		return handleRBACEvent(body, rbacSvc)
+	}); err != nil {
+		return err
+	}
+
+	// Bağlantı qırıq olduğu müddətdə gələn event-lər itmiş ola bilər – DB-dən yenidən yükləyirik
+	if err := rbacSvc.ReloadCache(); err != nil {
+		log.Println("❌ failed to reload RBAC cache:", err)
+	}
+	if err := authSvc.LoadRevocations(); err != nil {
+		log.Println("❌ failed to reload user revocations:", err)
+	}
+	return nil
+}
+
+func handleTokenEvent(body []byte, authSvc *service.AuthService) error {
+	var e struct {
+		dto.TokenBlacklistedEvent
+		TenantID      string `json:"tenant_id"`
+		UserID        string `json:"user_id"`
+		RevokedBefore int64  `json:"revoked_before"`
+	}
+	if err := json.Unmarshal(body, &e); err != nil {
+		return poisonError{err}
+	}
+
+	switch e.Event {
+	case dto.EventTokenBlacklisted:
+		if e.JTI == "" {
+			return poisonError{fmt.Errorf("%s without jti", e.Event)}
 		}
-		if err := json.Unmarshal(d.Body, &e); err == nil && e.Event == "RBAC_CACHE_RELOAD" {
-			log.Println("✅ Received RBAC_CACHE_RELOAD")
-			rbacSvc.ReloadCache()
+		log.Println("✅ Received TOKEN_BLACKLISTED")
+		authSvc.HandleBlacklistEvent(e.JTI, e.Exp)
+	case dto.EventTokenBlacklistedAll:
+		if e.UserID == "" {
+			return poisonError{fmt.Errorf("%s without user_id", e.Event)}
 		}
-	})
+		log.Println("✅ Received TOKEN_BLACKLISTED_ALL")
+		authSvc.HandleRevokeAllEvent(e.TenantID, e.UserID, e.RevokedBefore)
+	default:
+		log.Printf("⚠️ ignoring unknown token event %q", e.Event)
+	}
+	return nil
+}
+
+func handleRBACEvent(body []byte, rbacSvc *service.RBACService) error {
+	var e struct {
+		Event string `json:"event"`
+	}
+	if err := json.Unmarshal(body, &e); err != nil {
+		return poisonError{err}
+	}
+
+	switch e.Event {
+	case dto.EventRBACCacheReload,
+		dto.EventRBACRoleCreated,
+		dto.EventRBACRoleUpdated,
+		dto.EventRBACRoleDeleted,
+		dto.EventRBACRoleParentAdded,
+		dto.EventRBACRoleParentRemoved,
+		dto.EventRBACPermissionCreated,
+		dto.EventRBACPermissionUpdated,
+		dto.EventRBACPermissionDeleted,
+		dto.EventRBACPermissionAssigned,
+		dto.EventRBACPermissionRemoved,
+		dto.EventRBACUserRoleAssigned,
+		dto.EventRBACUserRoleUnassigned,
+		dto.EventRBACUserCreated,
+		dto.EventRBACUserUpdated,
+		dto.EventRBACUserDeleted,
+		dto.EventRBACUserRestored,
+		dto.EventRBACRouteCreated,
+		dto.EventRBACRouteUpdated,
+		dto.EventRBACRouteDeleted:
+		log.Printf("✅ Received %s", e.Event)
+		return rbacSvc.ReloadCache()
+	default:
+		log.Printf("⚠️ ignoring unknown RBAC event %q", e.Event)
+	}
+	return nil
 }
 
-func declareExchangeAndQueue(ch *amqp091.Channel, exchangeName, queueName string, handle func(amqp091.Delivery)) {
+func consume(ch mq.Channel, exchangeName string, handle func(body []byte) error) error {
 	// Declare durable fanout exchange
-	must(ch.ExchangeDeclare(exchangeName, "fanout", true, false, false, false, nil))
+	if err := ch.ExchangeDeclare(exchangeName, "fanout", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare exchange '%s': %w", exchangeName, err)
+	}
 
-	// Declare durable queue
-	queue, err := ch.QueueDeclare(queueName, true, false, false, false, nil)
-	must(err)
+	// Server-named, exclusive, auto-delete queue – yalnız bu instansiyaya məxsusdur
+	queue, err := ch.QueueDeclare("", false, true, true, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to declare queue for '%s': %w", exchangeName, err)
+	}
 
-	// Bind queue to exchange
-	must(ch.QueueBind(queue.Name, "", exchangeName, false, nil))
+	if err := ch.QueueBind(queue.Name, "", exchangeName, false, nil); err != nil {
+		return fmt.Errorf("failed to bind queue to '%s': %w", exchangeName, err)
+	}
 
-	// Start consumer
-	msgs, err := ch.Consume(queue.Name, "", true, false, false, false, nil)
-	must(err)
+	// Manual ack: mesaj yalnız uğurla emal olunduqdan sonra təsdiqlənir
+	msgs, err := ch.Consume(queue.Name, "", false, true, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume from '%s': %w", exchangeName, err)
+	}
 
 	go func() {
 		for d := range msgs {
-			handle(d)
+			dispatch(exchangeName, d, handle)
 		}
+		log.Printf("⚠️ consumer for %s stopped", exchangeName)
 	}()
+
+	log.Printf("[MQ] Consuming %s via %s", exchangeName, queue.Name)
+	return nil
 }
 
-func must(err error) {
-	if err != nil {
-		log.Fatalf("❌ %v", err)
+func dispatch(exchangeName string, d amqp091.Delivery, handle func(body []byte) error) {
+	err := handle(d.Body)
+	if err == nil {
+		_ = d.Ack(false)
+		return
 	}
+
+	if _, poison := err.(poisonError); poison {
+		log.Printf("❌ dropping malformed message from %s: %v", exchangeName, err)
+		_ = d.Nack(false, false)
+		return
+	}
+
+	// Müvəqqəti xəta (məs. DB) – bir dəfə yenidən cəhd edirik, sonsuz dövrəyə düşməmək üçün
+	log.Printf("❌ failed to handle message from %s: %v", exchangeName, err)
+	_ = d.Nack(false, !d.Redelivered)
 }
@@ -0,0 +1,90 @@
+package consumer
+
+import (
+	"errors"
+	"ms-authz/internal/infrastructure/cache"
+	"ms-authz/internal/service"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+type recordingAcker struct {
+	acked   bool
+	nacked  bool
+	requeue bool
+}
+
+func (a *recordingAcker) Ack(tag uint64, multiple bool) error {
+	a.acked = true
+	return nil
+}
+
+func (a *recordingAcker) Nack(tag uint64, multiple, requeue bool) error {
+	a.nacked = true
+	a.requeue = requeue
+	return nil
+}
+
+func (a *recordingAcker) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+func TestDispatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		redelivered bool
+		wantAck     bool
+		wantRequeue bool
+	}{
+		{"handled", nil, false, true, false},
+		{"malformed", poisonError{errors.New("bad json")}, false, false, false},
+		{"transient failure", errors.New("db down"), false, false, true},
+		{"transient failure after retry", errors.New("db down"), true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acker := &recordingAcker{}
+			d := amqp091.Delivery{Acknowledger: acker, Redelivered: tt.redelivered}
+			dispatch("test.fanout", d, func([]byte) error { return tt.err })
+
+			if acker.acked != tt.wantAck || acker.nacked == tt.wantAck {
+				t.Errorf("acked = %v, nacked = %v, want ack = %v", acker.acked, acker.nacked, tt.wantAck)
+			}
+			if acker.requeue != tt.wantRequeue {
+				t.Errorf("requeue = %v, want %v", acker.requeue, tt.wantRequeue)
+			}
+		})
+	}
+}
+
+func TestHandleTokenEvent(t *testing.T) {
+	repo := cache.NewTokenRepository()
+	authSvc := service.NewAuthService(nil, repo, nil, service.AuthConfig{})
+	exp := time.Now().Add(time.Hour).Unix()
+
+	if err := handleTokenEvent([]byte(`{"event":"TOKEN_BLACKLISTED","jti":"j1","exp":`+strconv.FormatInt(exp, 10)+`}`), authSvc); err != nil {
+		t.Fatal(err)
+	}
+	if !repo.IsBlacklisted("j1") {
+		t.Error("TOKEN_BLACKLISTED did not blacklist the jti")
+	}
+
+	if err := handleTokenEvent([]byte(`{"event":"TOKEN_BLACKLISTED_ALL","tenant_id":"acme","user_id":"7","revoked_before":1700000000}`), authSvc); err != nil {
+		t.Fatal(err)
+	}
+	if cutoff, ok := repo.RevokedBefore("acme", "7"); !ok || cutoff != 1700000000 {
+		t.Errorf("RevokedBefore(acme, 7) = %d, %v", cutoff, ok)
+	}
+	if _, ok := repo.RevokedBefore("", "7"); ok {
+		t.Error("cutoff leaked into the default tenant")
+	}
+
+	var poison poisonError
+	if err := handleTokenEvent([]byte(`{"event":"TOKEN_BLACKLISTED"}`), authSvc); !errors.As(err, &poison) {
+		t.Errorf("expected poison error for event without jti, got %v", err)
+	}
+}
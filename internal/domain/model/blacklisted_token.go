@@ -0,0 +1,14 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BlacklistedToken ləğv olunmuş token (jti və ya token hash-i), ExpiresAt-ə qədər saxlanılır
+type BlacklistedToken struct {
+	gorm.Model
+	JTI       string    `gorm:"uniqueIndex;size:128;not null"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OutboxEvent RBAC/blacklist dəyişikliyi ilə eyni tranzaksiyada yazılan event.
+// Relay onu RabbitMQ-ya göndərir və SentAt-i doldurur (at-least-once). Cəhdləri bitən event
+// ParkedAt ilə kənara qoyulur; parked_at = NULL edildikdə yenidən göndərilir.
+type OutboxEvent struct {
+	gorm.Model
+	Exchange      string     `gorm:"size:255;not null"`
+	Payload       string     `gorm:"type:jsonb;not null"`
+	PassiveQueues string     // vergüllə ayrılmış, publish-dən əvvəl declare/bind olunan queue-lar
+	SentAt        *time.Time `gorm:"index"`
+	ParkedAt      *time.Time `gorm:"index"`
+	LockedUntil   *time.Time // relay-in lease-i: bu ana qədər event-i başqa relay götürmür
+	Attempts      int
+	LastError     string
+}
@@ -2,9 +2,11 @@ package model
 
 import "gorm.io/gorm"
 
+// Permission adı tenant daxilində unikaldır
 type Permission struct {
 	gorm.Model
-	Name        string `gorm:"uniqueIndex;not null"`
+	TenantID    string `gorm:"size:64;not null;default:'';uniqueIndex:idx_permissions_tenant_name"`
+	Name        string `gorm:"not null;uniqueIndex:idx_permissions_tenant_name"`
 	Description string
 
 	Roles []Role `gorm:"many2many:role_permissions"`
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// RelationTuple "object#relation@subject" əlaqəsi, məs. document:123#parent@folder:9
+// və ya folder:9#viewer@group:eng#member. SubjectRelation boşdursa subject birbaşa obyektdir (məs. user:alice).
+type RelationTuple struct {
+	ID               uint   `gorm:"primaryKey"`
+	TenantID         string `gorm:"size:64;not null;default:'';uniqueIndex:idx_relation_tuple"`
+	Namespace        string `gorm:"size:64;not null;uniqueIndex:idx_relation_tuple"`
+	ObjectID         string `gorm:"size:255;not null;uniqueIndex:idx_relation_tuple"`
+	Relation         string `gorm:"size:64;not null;uniqueIndex:idx_relation_tuple"`
+	SubjectNamespace string `gorm:"size:64;not null;uniqueIndex:idx_relation_tuple"`
+	SubjectID        string `gorm:"size:255;not null;uniqueIndex:idx_relation_tuple"`
+	SubjectRelation  string `gorm:"size:64;not null;default:'';uniqueIndex:idx_relation_tuple"`
+	CreatedAt        time.Time
+}
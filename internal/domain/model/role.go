@@ -2,11 +2,16 @@ package model
 
 import "gorm.io/gorm"
 
+// Role adı tenant daxilində unikaldır – hər tenant öz "admin" rolunu yarada bilər.
+// Boş TenantID default tenant-dır.
 type Role struct {
 	gorm.Model
-	Name        string `gorm:"uniqueIndex;not null"`
+	TenantID    string `gorm:"size:64;not null;default:'';uniqueIndex:idx_roles_tenant_name"`
+	Name        string `gorm:"not null;uniqueIndex:idx_roles_tenant_name"`
 	Description string
 
 	Permissions []Permission `gorm:"many2many:role_permissions"`
 	Users       []User       `gorm:"many2many:user_roles"`
+	// Parents – bu rolun permission-larını miras aldığı rollar
+	Parents []Role `gorm:"many2many:role_parents;joinForeignKey:RoleID;joinReferences:ParentID"`
 }
@@ -2,8 +2,23 @@ package model
 
 import "gorm.io/gorm"
 
+// Permission təyinatının effekti: deny həmişə allow-dan üstündür (deny-overrides)
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// RolePermission rola permission təyinatı. ResourceType boşdursa təyinat bütün resurslara aiddir,
+// əks halda yalnız həmin tipdə və ID-si ResourcePattern-ə (path.Match, məs. "tenant/42/invoices/*") uyğun resurslara.
+// Condition verilibsə təyinat yalnız şərt (pkg/condition) token claim-ləri və sorğu konteksti üzərində doğru olduqda tətbiq olunur.
 type RolePermission struct {
 	gorm.Model
-	RoleID       uint `gorm:"not null;index"`
-	PermissionID uint `gorm:"not null;index"`
+	RoleID          uint   `gorm:"not null;index;uniqueIndex:idx_role_permission"`
+	PermissionID    uint   `gorm:"not null;index;uniqueIndex:idx_role_permission"`
+	Effect          string `gorm:"size:8;not null;default:allow"`
+	ResourceType    string `gorm:"size:64;not null;default:'';uniqueIndex:idx_role_permission"`
+	ResourcePattern string `gorm:"size:255;not null;default:'';uniqueIndex:idx_role_permission"`
+	Condition       string `gorm:"type:text;not null;default:''"`
+
+	Permission Permission
 }
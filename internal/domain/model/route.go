@@ -0,0 +1,17 @@
+package model
+
+import "gorm.io/gorm"
+
+// Route servisin HTTP metod + path pattern-inə (pkg/route sintaksisi, məs. /api/users/*) tələb olunan permission-lar.
+// Permissions-ın hamısı tələb olunur; boşdursa etibarlı token kifayətdir, Public route isə token tələb etmir.
+// Eyni servisdə bir neçə route uyğun gələrsə Priority-si böyük olan, bərabər olduqda əvvəl yaradılan qalib gəlir.
+type Route struct {
+	gorm.Model
+	TenantID    string   `gorm:"size:64;not null;default:'';uniqueIndex:idx_routes_tenant_service_method_path"`
+	Service     string   `gorm:"size:64;not null;default:'';uniqueIndex:idx_routes_tenant_service_method_path"`
+	Method      string   `gorm:"size:16;not null;default:'*';uniqueIndex:idx_routes_tenant_service_method_path"`
+	Path        string   `gorm:"size:255;not null;uniqueIndex:idx_routes_tenant_service_method_path"`
+	Permissions []string `gorm:"type:text;serializer:json"`
+	Public      bool     `gorm:"not null;default:false"`
+	Priority    int      `gorm:"not null;default:0"`
+}
@@ -2,10 +2,14 @@ package model
 
 import "gorm.io/gorm"
 
+// User username və email-i tenant daxilində unikaldır
 type User struct {
 	gorm.Model
-	Username string `gorm:"uniqueIndex;size:100;not null"`
-	Email    string `gorm:"uniqueIndex;size:150"`
-	RoleID   uint
+	TenantID string `gorm:"size:64;not null;default:'';uniqueIndex:idx_users_tenant_username;uniqueIndex:idx_users_tenant_email"`
+	Username string `gorm:"size:100;not null;uniqueIndex:idx_users_tenant_username"`
+	Email    string `gorm:"size:150;uniqueIndex:idx_users_tenant_email"`
+	RoleID   uint   // köhnə tək rol sahəsi, Roles ilə birlikdə nəzərə alınır
 	Role     Role
+
+	Roles []Role `gorm:"many2many:user_roles"`
 }
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserRevocation istifadəçinin RevokedBefore anına qədər (daxil olmaqla) buraxılmış
+// bütün tokenlərini etibarsız sayır ("logout-all"). Cutoff tenant daxilindədir – eyni user_id
+// başqa tenant-da başqa istifadəçidir.
+type UserRevocation struct {
+	gorm.Model
+	TenantID      string    `gorm:"size:64;not null;default:'';uniqueIndex:idx_user_revocations_tenant_user"`
+	UserID        string    `gorm:"size:100;not null;uniqueIndex:idx_user_revocations_tenant_user"`
+	RevokedBefore time.Time `gorm:"not null"`
+}
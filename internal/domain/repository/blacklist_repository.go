@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"ms-authz/internal/domain/model"
+	"time"
+)
+
+type BlacklistRepository interface {
+	Add(jti string, expiresAt time.Time) error
+	GetActive(now time.Time) ([]model.BlacklistedToken, error)
+	DeleteExpired(now time.Time) error
+}
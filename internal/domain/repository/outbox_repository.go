@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"ms-authz/internal/domain/model"
+	"time"
+)
+
+type OutboxRepository interface {
+	Add(exchange string, payload any, passiveQueues []string) error
+	// Claim göndərilməmiş, park edilməmiş və lease-i olmayan (və ya bitmiş) event-ləri sıra ilə qaytarır
+	// və onlara lockedUntil-ə qədər lease verir. Tranzaksiya daxilində çağırılmalıdır; publish commit-dən
+	// sonra edilir, ona görə sətir kilidləri və bağlantı broker-i gözləmir.
+	Claim(limit int, now, lockedUntil time.Time) ([]model.OutboxEvent, error)
+	// Release göndərilməmiş qalan event-lərin lease-ini götürür, növbəti dövrədə yenidən götürülürlər
+	Release(ids []uint) error
+	MarkSent(id uint, sentAt time.Time) error
+	MarkFailed(id uint, cause error) error
+	// Park cəhdləri bitmiş event-i növbədən çıxarır, sonrakı event-lər onu gözləmir
+	Park(id uint, parkedAt time.Time, cause error) error
+	DeleteSentBefore(before time.Time) error
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "ms-authz/internal/domain/model"
+
+type RelationTupleRepository interface {
+	// Read obyektin tuple-larını qaytarır, relation boşdursa bütün relation-lar üzrə
+	Read(namespace, objectID, relation string) ([]model.RelationTuple, error)
+	// ObjectIDs namespace-də ən azı bir tuple-ı olan obyektlərin ID-lərini qaytarır
+	ObjectIDs(namespace string) ([]string, error)
+	// Write tuple artıq varsa heç nə etmir
+	Write(tuple *model.RelationTuple) error
+	Delete(tuple *model.RelationTuple) error
+}
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"ms-authz/internal/domain/model"
+	"time"
+)
+
+type RevocationRepository interface {
+	GetByUserID(tenantID, userID string) (*model.UserRevocation, error)
+	GetAll() ([]model.UserRevocation, error)
+	Upsert(tenantID, userID string, revokedBefore time.Time) error
+}
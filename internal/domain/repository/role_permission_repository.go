@@ -4,7 +4,15 @@ import "ms-authz/internal/domain/model"
 
 type RolePermissionRepository interface {
 	GetPermissionsByRoleID(roleID uint) ([]model.Permission, error)
-	AddPermission(roleID, permissionID uint) error
+	// GetAll bütün təyinatları effekti və permission-u ilə birlikdə qaytarır
+	GetAll() ([]model.RolePermission, error)
+	// GetByRoleID rolun öz təyinatlarını (irsiyyətsiz), GetByPermissionID permission-un bütün təyinatlarını qaytarır
+	GetByRoleID(roleID uint) ([]model.RolePermission, error)
+	GetByPermissionID(permissionID uint) ([]model.RolePermission, error)
+	// AddPermission eyni scope-lu təyinat artıq varsa onun effektini və şərtini yeniləyir
+	AddPermission(grant *model.RolePermission) error
+	// RemovePermission permission-un rola bütün (scope-lu və scope-suz) təyinatlarını silir
 	RemovePermission(roleID, permissionID uint) error
+	RemoveScopedPermission(roleID, permissionID uint, resourceType, resourcePattern string) error
 	ClearPermissions(roleID uint) error
 }
@@ -8,6 +8,10 @@ type RoleRepository interface {
 	GetAllWithPermissions() ([]model.Role, error)
 	GetPermissionsByRoleID(id uint) ([]model.Permission, error)
 	GetAll() ([]model.Role, error)
+	GetAllWithParents() ([]model.Role, error)
+	GetParents(roleID uint) ([]model.Role, error)
+	AddParent(roleID, parentID uint) error
+	RemoveParent(roleID, parentID uint) error
 	Create(role *model.Role) error
 	Update(role *model.Role) error
 	Delete(id uint) error
@@ -0,0 +1,13 @@
+package repository
+
+import "ms-authz/internal/domain/model"
+
+type RouteRepository interface {
+	GetByID(id uint) (*model.Route, error)
+	// GetAll route-ları servis, sonra uyğunlaşma sırası (Priority azalan, ID artan) ilə qaytarır
+	GetAll() ([]model.Route, error)
+	GetByService(service string) ([]model.Route, error)
+	Create(route *model.Route) error
+	Update(route *model.Route) error
+	Delete(id uint) error
+}
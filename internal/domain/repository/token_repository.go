@@ -5,8 +5,11 @@ import "ms-authz/internal/infrastructure/cache"
 type TokenRepository interface {
 	IsBlacklisted(jti string) bool
 	Add(jti string, exp int64)
-	AddWithUser(token string, exp int64, userID string, role string)
+	AddCached(jti string, exp int64) // DB-yə yazmadan, yalnız cache
+	AddWithUser(jti string, exp int64, userID string, role string)
 	GetAllJTIsByUser(userID string) []cache.TokenInfo
 	GetAllTokensByUser(userID string) []cache.TokenInfo
 	CleanupExpired()
+	SetRevokedBefore(tenantID, userID string, revokedBefore int64)
+	RevokedBefore(tenantID, userID string) (int64, bool)
 }
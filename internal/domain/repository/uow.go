@@ -5,4 +5,16 @@ type UnitOfWork interface {
 	PermissionRepo() PermissionRepository
 	RolePermissionRepo() RolePermissionRepository
 	UserRepo() UserRepository
+	RevocationRepo() RevocationRepository
+	BlacklistRepo() BlacklistRepository
+	OutboxRepo() OutboxRepository
+	RelationTupleRepo() RelationTupleRepository
+	RouteRepo() RouteRepository
+
+	// ForTenant rol, permission, təyinat, istifadəçi, relation tuple və route repository-lərini bir tenant-a məhdudlaşdırır.
+	// Boş tenantID default tenant-dır. Filtrsiz UnitOfWork bütün tenant-ları görür.
+	ForTenant(tenantID string) UnitOfWork
+
+	// Transaction fn-i bir DB tranzaksiyasında icra edir; fn xəta qaytararsa rollback olunur
+	Transaction(fn func(tx UnitOfWork) error) error
 }
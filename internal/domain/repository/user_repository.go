@@ -5,7 +5,15 @@ import "ms-authz/internal/domain/model"
 type UserRepository interface {
 	GetByID(id uint) (*model.User, error)
 	GetByUsername(username string) (*model.User, error)
+	GetByEmail(email string) (*model.User, error)
 	GetAll() ([]model.User, error)
+	GetAllWithRoles() ([]model.User, error)
+	GetRoles(userID uint) ([]model.Role, error)
+	AssignRole(userID, roleID uint) error
+	UnassignRole(userID, roleID uint) error
 	Create(user *model.User) error
+	Update(user *model.User) error
 	Delete(id uint) error
+	// Restore soft-delete olunmuş istifadəçini bərpa edir, tapılmadıqda gorm.ErrRecordNotFound qaytarır
+	Restore(id uint) error
 }
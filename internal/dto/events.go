@@ -0,0 +1,47 @@
+package dto
+
+// auth.tokens.fanout exchange-inin event-ləri.
+// Eyni struct-lar həm publisher, həm consumer tərəfindən istifadə olunur.
+const (
+	EventTokenBlacklisted    = "TOKEN_BLACKLISTED"
+	EventTokenBlacklistedAll = "TOKEN_BLACKLISTED_ALL"
+)
+
+// TokenBlacklistedEvent – xam token deyil, yalnız jti (və ya token hash-i) göndərilir
+type TokenBlacklistedEvent struct {
+	Event string `json:"event"`
+	JTI   string `json:"jti"`
+	Exp   int64  `json:"exp"`
+}
+
+// TokenBlacklistedAllEvent – tenant-ın istifadəçisinin RevokedBefore (unix) anına qədər buraxılmış bütün tokenləri etibarsızdır
+type TokenBlacklistedAllEvent struct {
+	Event         string `json:"event"`
+	TenantID      string `json:"tenant_id"`
+	UserID        string `json:"user_id"`
+	RevokedBefore int64  `json:"revoked_before"`
+}
+
+// rbac.update.fanout exchange-inin event-ləri – hamısı RBAC cache-in yenilənməsinə səbəb olur
+const (
+	EventRBACCacheReload        = "RBAC_CACHE_RELOAD"
+	EventRBACRoleCreated        = "RBAC_ROLE_CREATED"
+	EventRBACRoleUpdated        = "RBAC_ROLE_UPDATED"
+	EventRBACRoleDeleted        = "RBAC_ROLE_DELETED"
+	EventRBACRoleParentAdded    = "RBAC_ROLE_PARENT_ADDED"
+	EventRBACRoleParentRemoved  = "RBAC_ROLE_PARENT_REMOVED"
+	EventRBACPermissionCreated  = "RBAC_PERMISSION_CREATED"
+	EventRBACPermissionUpdated  = "RBAC_PERMISSION_UPDATED"
+	EventRBACPermissionDeleted  = "RBAC_PERMISSION_DELETED"
+	EventRBACPermissionAssigned = "RBAC_PERMISSION_ASSIGNED"
+	EventRBACPermissionRemoved  = "RBAC_PERMISSION_REMOVED"
+	EventRBACUserRoleAssigned   = "RBAC_USER_ROLE_ASSIGNED"
+	EventRBACUserRoleUnassigned = "RBAC_USER_ROLE_UNASSIGNED"
+	EventRBACUserCreated        = "RBAC_USER_CREATED"
+	EventRBACUserUpdated        = "RBAC_USER_UPDATED"
+	EventRBACUserDeleted        = "RBAC_USER_DELETED"
+	EventRBACUserRestored       = "RBAC_USER_RESTORED"
+	EventRBACRouteCreated       = "RBAC_ROUTE_CREATED"
+	EventRBACRouteUpdated       = "RBAC_ROUTE_UPDATED"
+	EventRBACRouteDeleted       = "RBAC_ROUTE_DELETED"
+)
@@ -10,14 +10,100 @@ type PermissionDTO struct {
 	Name string `json:"name"`
 }
 
+// RoleRequest rol yaratmaq və yeniləmək üçün. Valideynlər və permission-lar yalnız ayrıca endpoint-lərlə təyin olunur.
+type RoleRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// PermissionRequest permission yaratmaq və yeniləmək üçün
+type PermissionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
 type RoleWithPermissionsDTO struct {
 	ID          uint           `json:"id"`
 	Name        string         `json:"name"`
-	Permissions []PermissionDTO `json:"permissions"`
+	Permissions []PermissionGrantDTO `json:"permissions"`
 }
 
 type PermissionWithRolesDTO struct {
 	ID    uint       `json:"id"`
 	Name  string     `json:"name"`
-	Roles []RoleDTO  `json:"roles"`
+	Roles []RoleGrantDTO `json:"roles"`
+}
+
+// EffectivePermissionsDTO rolun özünə və valideynlərinə təyin olunmuş permission pattern-ləri
+type EffectivePermissionsDTO struct {
+	Role   string                 `json:"role"`
+	Allow  []string               `json:"allow"`
+	Deny   []string               `json:"deny"`
+	Scoped []ScopedPermissionsDTO `json:"scoped"`
+}
+
+// ScopedPermissionsDTO yalnız müəyyən resurslara və ya şərtə bağlı təyinatlar
+type ScopedPermissionsDTO struct {
+	ResourceType    string   `json:"resource_type"`
+	ResourcePattern string   `json:"resource_pattern"`
+	Condition       string   `json:"condition,omitempty"`
+	Allow           []string `json:"allow"`
+	Deny            []string `json:"deny"`
+}
+
+type UserDTO struct {
+	ID       uint      `json:"id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email,omitempty"`
+	RoleID   uint      `json:"role_id,omitempty"`
+	Roles    []RoleDTO `json:"roles"`
+}
+
+// UserRequest istifadəçi yaratmaq və yeniləmək üçün
+type UserRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	RoleID   uint   `json:"role_id"`
+}
+
+// UserEffectivePermissionsDTO istifadəçinin bütün server-side rollarından toplanmış permission-lar
+type UserEffectivePermissionsDTO struct {
+	UserID uint                   `json:"user_id"`
+	Roles  []string               `json:"roles"`
+	Allow  []string               `json:"allow"`
+	Deny   []string               `json:"deny"`
+	Scoped []ScopedPermissionsDTO `json:"scoped"`
+}
+
+// RouteDTO servis route-unun tələb etdiyi permission-lar
+type RouteDTO struct {
+	ID          uint     `json:"id"`
+	Service     string   `json:"service"`
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Permissions []string `json:"permissions"`
+	Public      bool     `json:"public"`
+	Priority    int      `json:"priority"`
+}
+
+// GrantDTO təyinatın effekti və scope-u. Eyni permission rola müxtəlif scope-larla bir neçə dəfə təyin oluna bilər.
+type GrantDTO struct {
+	Effect          string `json:"effect"`
+	ResourceType    string `json:"resource_type,omitempty"`
+	ResourcePattern string `json:"resource_pattern,omitempty"`
+	Condition       string `json:"condition,omitempty"`
+}
+
+// PermissionGrantDTO rola təyin olunmuş permission
+type PermissionGrantDTO struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	GrantDTO
+}
+
+// RoleGrantDTO permission-un təyin olunduğu rol
+type RoleGrantDTO struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	GrantDTO
 }
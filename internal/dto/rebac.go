@@ -0,0 +1,28 @@
+package dto
+
+// RelationTupleDTO "object#relation@subject" tuple-ı, məs. document:123 + viewer + user:alice
+type RelationTupleDTO struct {
+	Object   string `json:"object"`
+	Relation string `json:"relation"`
+	Subject  string `json:"subject"` // namespace:id və ya namespace:id#relation
+}
+
+type RelationCheckResponse struct {
+	Allowed  bool   `json:"allowed"`
+	Object   string `json:"object"`
+	Relation string `json:"relation"`
+	Subject  string `json:"subject"`
+}
+
+type ListObjectsRequest struct {
+	Namespace string `json:"namespace"`
+	Relation  string `json:"relation"`
+	Subject   string `json:"subject"`
+}
+
+type ListObjectsResponse struct {
+	Namespace string   `json:"namespace"`
+	Relation  string   `json:"relation"`
+	Subject   string   `json:"subject"`
+	Objects   []string `json:"objects"` // obyekt ID-ləri
+}
@@ -0,0 +1,116 @@
+// Package extauthz Envoy-nin envoy.service.auth.v3.Authorization/Check gRPC API-sini həyata keçirir.
+// Qərarı service.GatewayAuthorizer verir (route → permission, token, RBAC); icazə verildikdə
+// upstream-ə istifadəçi header-ləri əlavə olunur.
+package extauthz
+
+import (
+	"context"
+	"encoding/json"
+	"ms-authz/internal/service"
+	"net"
+	"net/http"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// İcazə verilmiş sorğuya əlavə olunan header-lər. Müştərinin göndərdiyi eyni adlı header-lər həmişə əvəz və ya silinir.
+const (
+	HeaderUserID   = service.IdentityHeaderUserID
+	HeaderUserRole = service.IdentityHeaderUserRole
+	HeaderTenantID = service.IdentityHeaderTenantID
+)
+
+// ContextExtensionService Envoy route-unun per_filter_config-də route reyestrindəki servis adını verən açar
+const ContextExtensionService = "service"
+
+type Server struct {
+	authv3.UnimplementedAuthorizationServer
+	gateway *service.GatewayAuthorizer
+}
+
+func NewServer(gateway *service.GatewayAuthorizer) *Server {
+	return &Server{gateway: gateway}
+}
+
+// Register serveri gRPC serverində qeydiyyatdan keçirir
+func (s *Server) Register(g *grpc.Server) {
+	authv3.RegisterAuthorizationServer(g, s)
+}
+
+// Check Envoy-nin hər sorğu üçün çağırdığı metod. Rədd də daxil olmaqla qərar həmişə CheckResponse ilə
+// qaytarılır (gRPC xətası Envoy-da failure_mode_allow-a düşər). Route reyestrində olmayan sorğular rədd edilir.
+// Servis adı route-un context_extensions-undakı "service" açarından götürülür, yoxdursa gateway-in default servisi.
+func (s *Server) Check(ctx context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	httpReq := req.GetAttributes().GetRequest().GetHttp()
+	if httpReq == nil {
+		return denied(codes.InvalidArgument, typev3.StatusCode_Forbidden, "missing http request attributes", ""), nil
+	}
+
+	d := s.gateway.Authorize(service.GatewayRequest{
+		Service:       req.GetAttributes().GetContextExtensions()[ContextExtensionService],
+		Method:        httpReq.GetMethod(),
+		Path:          httpReq.GetPath(),
+		Authorization: httpReq.GetHeaders()["authorization"],
+		ClientIP:      sourceIP(req),
+	})
+	switch d.Status {
+	case http.StatusOK:
+		return allowed(d.Identity()), nil
+	case http.StatusUnauthorized:
+		return denied(codes.Unauthenticated, typev3.StatusCode_Unauthorized, d.Error, string(d.Reason)), nil
+	default:
+		return denied(codes.PermissionDenied, typev3.StatusCode_Forbidden, d.Error, ""), nil
+	}
+}
+
+// Müştərinin IP-si – Envoy-un gördüyü mənbə ünvanı
+func sourceIP(req *authv3.CheckRequest) string {
+	addr := req.GetAttributes().GetSource().GetAddress().GetSocketAddress().GetAddress()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+func allowed(identity map[string]string) *authv3.CheckResponse {
+	ok := &authv3.OkHttpResponse{}
+	for _, key := range service.IdentityHeaders {
+		if identity[key] == "" {
+			ok.HeadersToRemove = append(ok.HeadersToRemove, key)
+			continue
+		}
+		ok.Headers = append(ok.Headers, &corev3.HeaderValueOption{
+			Header:       &corev3.HeaderValue{Key: key, Value: identity[key]},
+			AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+		})
+	}
+	return &authv3.CheckResponse{
+		Status:       &rpcstatus.Status{Code: int32(codes.OK)},
+		HttpResponse: &authv3.CheckResponse_OkResponse{OkResponse: ok},
+	}
+}
+
+// denied müştəriyə /check cavabı ilə eyni formada JSON body qaytarır
+func denied(code codes.Code, httpStatus typev3.StatusCode, message, reason string) *authv3.CheckResponse {
+	payload := map[string]any{"status": false, "error": message}
+	if reason != "" {
+		payload["reason"] = reason
+	}
+	body, _ := json.Marshal(payload)
+	return &authv3.CheckResponse{
+		Status: &rpcstatus.Status{Code: int32(code), Message: message},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{DeniedResponse: &authv3.DeniedHttpResponse{
+			Status: &typev3.HttpStatus{Code: httpStatus},
+			Headers: []*corev3.HeaderValueOption{{
+				Header:       &corev3.HeaderValue{Key: "content-type", Value: "application/json"},
+				AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+			}},
+			Body: string(body),
+		}},
+	}
+}
@@ -0,0 +1,221 @@
+package extauthz
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/golang-jwt/jwt/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+	"ms-authz/internal/domain/model"
+	"ms-authz/internal/domain/repository"
+	"ms-authz/internal/infrastructure/cache"
+	"ms-authz/internal/service"
+	"ms-authz/pkg/jwtutil"
+	"ms-authz/pkg/route"
+)
+
+type staticKeyProvider map[string]*jwtutil.VerificationKey
+
+func (p staticKeyProvider) GetPublicKey(kid string) (*jwtutil.VerificationKey, error) {
+	return p[kid], nil
+}
+
+// fakeUoW RBAC cache-in yüklənməsi üçün lazım olan repository-ləri yaddaşdan təmin edir
+type fakeUoW struct {
+	repository.UnitOfWork
+	roles  []model.Role
+	grants []model.RolePermission
+	routes []model.Route
+}
+
+func (u *fakeUoW) RoleRepo() repository.RoleRepository { return fakeRoleRepo{roles: u.roles} }
+func (u *fakeUoW) RolePermissionRepo() repository.RolePermissionRepository {
+	return fakeRolePermissionRepo{grants: u.grants}
+}
+func (u *fakeUoW) UserRepo() repository.UserRepository             { return fakeUserRepo{} }
+func (u *fakeUoW) PermissionRepo() repository.PermissionRepository { return fakePermissionRepo{} }
+func (u *fakeUoW) RouteRepo() repository.RouteRepository           { return fakeRouteRepo{routes: u.routes} }
+
+type fakeRoleRepo struct {
+	repository.RoleRepository
+	roles []model.Role
+}
+
+func (r fakeRoleRepo) GetAllWithParents() ([]model.Role, error) { return r.roles, nil }
+
+type fakeRolePermissionRepo struct {
+	repository.RolePermissionRepository
+	grants []model.RolePermission
+}
+
+func (r fakeRolePermissionRepo) GetAll() ([]model.RolePermission, error) { return r.grants, nil }
+
+type fakeUserRepo struct{ repository.UserRepository }
+
+func (fakeUserRepo) GetAllWithRoles() ([]model.User, error) { return nil, nil }
+
+type fakePermissionRepo struct {
+	repository.PermissionRepository
+}
+
+func (fakePermissionRepo) GetAll() ([]model.Permission, error) { return nil, nil }
+
+type fakeRouteRepo struct {
+	repository.RouteRepository
+	routes []model.Route
+}
+
+func (r fakeRouteRepo) GetAll() ([]model.Route, error) { return r.routes, nil }
+
+// startServer ext_authz serverini bufconn üzərində qaldırır və ona qoşulmuş klient qaytarır
+func startServer(t *testing.T, srv *Server) authv3.AuthorizationClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	g := grpc.NewServer()
+	srv.Register(g)
+	go g.Serve(lis)
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return authv3.NewAuthorizationClient(conn)
+}
+
+func checkRequest(method, path, token string) *authv3.CheckRequest {
+	return checkServiceRequest("", method, path, token)
+}
+
+// checkServiceRequest servis adını Envoy-un context_extensions-u ilə ötürür
+func checkServiceRequest(svc, method, path, token string) *authv3.CheckRequest {
+	headers := map[string]string{HeaderUserID: "spoofed"}
+	if token != "" {
+		headers["authorization"] = "Bearer " + token
+	}
+	var extensions map[string]string
+	if svc != "" {
+		extensions = map[string]string{ContextExtensionService: svc}
+	}
+	return &authv3.CheckRequest{Attributes: &authv3.AttributeContext{
+		ContextExtensions: extensions,
+		Source: &authv3.AttributeContext_Peer{Address: &corev3.Address{Address: &corev3.Address_SocketAddress{
+			SocketAddress: &corev3.SocketAddress{Address: "10.0.0.7"},
+		}}},
+		Request: &authv3.AttributeContext_Request{Http: &authv3.AttributeContext_HttpRequest{
+			Method:  method,
+			Path:    path,
+			Headers: headers,
+		}},
+	}}
+}
+
+func TestServer_Check(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	vk, err := jwtutil.NewVerificationKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := service.NewAuthService(nil, cache.NewTokenRepository(), staticKeyProvider{"k": vk}, service.AuthConfig{})
+	rbac := service.NewRBACService(&fakeUoW{
+		roles: []model.Role{{Model: gorm.Model{ID: 1}, Name: "support"}},
+		grants: []model.RolePermission{
+			{RoleID: 1, Effect: model.EffectAllow, Permission: model.Permission{Model: gorm.Model{ID: 1}, Name: "orders:read"}},
+		},
+		routes: []model.Route{
+			{Service: "api", Method: "GET", Path: "/healthz", Public: true},
+			{Service: "api", Method: "GET", Path: "/api/orders/**", Permissions: []string{"orders:read"}},
+			{Service: "api", Method: "DELETE", Path: "/api/orders/*", Permissions: []string{"orders:delete"}},
+			{Service: "api", Method: route.AnyMethod, Path: "/api/me"},
+			{Service: "billing", Method: "GET", Path: "/invoices/*", Permissions: []string{"orders:read"}},
+		},
+	})
+	client := startServer(t, NewServer(service.NewGatewayAuthorizer(auth, rbac, "api")))
+
+	sign := func(claims jwtutil.Claims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		token.Header["kid"] = "k"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	valid := sign(jwtutil.Claims{
+		UserID:           "42",
+		Role:             "support",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	})
+	expired := sign(jwtutil.Claims{
+		UserID:           "42",
+		Role:             "support",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour))},
+	})
+
+	tests := []struct {
+		name       string
+		req        *authv3.CheckRequest
+		wantCode   codes.Code
+		wantHTTP   int32  // rədd cavabının HTTP statusu
+		wantUserID string // icazə verildikdə x-user-id
+	}{
+		{"public route", checkRequest("GET", "/healthz", ""), codes.OK, 0, ""},
+		{"permission granted", checkRequest("GET", "/api/orders/7?expand=items", valid), codes.OK, 0, "42"},
+		{"token only route", checkRequest("POST", "/api/me", valid), codes.OK, 0, "42"},
+		{"permission missing", checkRequest("DELETE", "/api/orders/7", valid), codes.PermissionDenied, 403, ""},
+		{"no token", checkRequest("GET", "/api/orders/7", ""), codes.Unauthenticated, 401, ""},
+		{"expired token", checkRequest("GET", "/api/orders/7", expired), codes.Unauthenticated, 401, ""},
+		{"unmapped route", checkRequest("GET", "/api/users", valid), codes.PermissionDenied, 403, ""},
+		{"traversal out of public route", checkRequest("GET", "/healthz/../api/orders/7", ""), codes.Unauthenticated, 401, ""},
+		{"service from context extensions", checkServiceRequest("billing", "GET", "/invoices/3", valid), codes.OK, 0, "42"},
+	}
+	for _, tt := range tests {
+		resp, err := client.Check(context.Background(), tt.req)
+		if err != nil {
+			t.Fatalf("%s: Check() error = %v", tt.name, err)
+		}
+		if got := codes.Code(resp.GetStatus().GetCode()); got != tt.wantCode {
+			t.Errorf("%s: status = %v, want %v (%s)", tt.name, got, tt.wantCode, resp.GetStatus().GetMessage())
+			continue
+		}
+
+		if tt.wantCode != codes.OK {
+			if got := int32(resp.GetDeniedResponse().GetStatus().GetCode()); got != tt.wantHTTP {
+				t.Errorf("%s: denied HTTP status = %d, want %d", tt.name, got, tt.wantHTTP)
+			}
+			continue
+		}
+
+		ok := resp.GetOkResponse()
+		headers := map[string]string{}
+		for _, h := range ok.GetHeaders() {
+			headers[h.GetHeader().GetKey()] = h.GetHeader().GetValue()
+		}
+		if headers[HeaderUserID] != tt.wantUserID {
+			t.Errorf("%s: %s = %q, want %q", tt.name, HeaderUserID, headers[HeaderUserID], tt.wantUserID)
+		}
+		if tt.wantUserID != "" && headers[HeaderUserRole] != "support" {
+			t.Errorf("%s: %s = %q, want support", tt.name, HeaderUserRole, headers[HeaderUserRole])
+		}
+		// Müştərinin göndərdiyi identity header-ləri upstream-ə çatmamalıdır
+		if tt.wantUserID == "" && !slices.Contains(ok.GetHeadersToRemove(), HeaderUserID) {
+			t.Errorf("%s: headers to remove = %v, want %s", tt.name, ok.GetHeadersToRemove(), HeaderUserID)
+		}
+	}
+}
@@ -1,41 +1,58 @@
 package handler
 
 import (
+	"encoding/json"
 	"github.com/gofiber/fiber/v2"
-	"ms-authz/internal/infrastructure/mq"
 	"ms-authz/internal/service"
+	"ms-authz/pkg/condition"
+	"ms-authz/pkg/jwtutil"
 	"strings"
+	"time"
 )
 
 type AuthorizeHandler struct {
-	Auth      *service.AuthService
-	RBAC      *service.RBACService
-	publisher mq.Publisher
+	Auth *service.AuthService
+	RBAC *service.RBACService
 }
 
 type AuthzCheckResponse struct {
-	Status           bool   `json:"status"`
-	UserID           string `json:"user_id,omitempty"`
-	Role             string `json:"role,omitempty"`
-	PrivilegeChecked string `json:"privilege_checked,omitempty"`
-	RBACChecked      bool   `json:"rbac_checked"`
-	RBACResult       bool   `json:"rbac_result"`
-	JWTValidated     bool   `json:"jwt_validated"`
-	BlacklistChecked bool   `json:"blacklist_checked"`
-	Blacklisted      bool   `json:"blacklisted"`
-	Error            string `json:"error,omitempty"` // Əgər status=false olsa, səbəb burda olur
+	Status           bool              `json:"status"`
+	UserID           string            `json:"user_id,omitempty"`
+	Role             string            `json:"role,omitempty"`
+	Roles            []string          `json:"roles,omitempty"` // RBAC-da nəzərə alınan bütün rollar (token + user_roles)
+	TenantID         string            `json:"tenant_id,omitempty"`
+	PrivilegeChecked string            `json:"privilege_checked,omitempty"`
+	RBACChecked      bool              `json:"rbac_checked"`
+	RBACResult       bool              `json:"rbac_result"`
+	RBACEffect       string            `json:"rbac_effect,omitempty"` // qalib gələn qaydanın effekti (allow/deny)
+	RBACRule         string            `json:"rbac_rule,omitempty"`   // qalib gələn permission pattern-i
+	RBACRole         string            `json:"rbac_role,omitempty"`   // qaydanın tapıldığı rol
+	Resource         string            `json:"resource,omitempty"`    // yoxlanılan resurs tipi
+	ResourceID       string            `json:"resource_id,omitempty"`
+	RBACScope        string            `json:"rbac_scope,omitempty"`     // qalib qayda resursa bağlıdırsa onun scope-u (tip:pattern)
+	RBACCondition    string            `json:"rbac_condition,omitempty"` // qalib qaydanın şərti (varsa)
+	Service          string            `json:"service,omitempty"`        // path ilə yoxlamada servis
+	Route            string            `json:"route,omitempty"`          // uyğun gələn reyestr qaydası (metod və path pattern-i)
+	JWTValidated     bool              `json:"jwt_validated"`
+	BlacklistChecked bool              `json:"blacklist_checked"`
+	Blacklisted      bool              `json:"blacklisted"`
+	Error            string            `json:"error,omitempty"`   // Əgər status=false olsa, səbəb burda olur
+	Reason           string            `json:"reason,omitempty"`  // Token rədd edilibsə, hansı yoxlamadan keçmədiyi (expired, audience_mismatch, ...)
+	Explain          *AuthzExplanation `json:"explain,omitempty"` // explain=true olduqda qiymətləndirmə izi
 }
 
-func NewAuthorizeHandler(auth *service.AuthService, rbac *service.RBACService, publisher mq.Publisher) *AuthorizeHandler {
+func NewAuthorizeHandler(auth *service.AuthService, rbac *service.RBACService) *AuthorizeHandler {
 	return &AuthorizeHandler{
-		Auth:      auth,
-		RBAC:      rbac,
-		publisher: publisher,
+		Auth: auth,
+		RBAC: rbac,
 	}
 }
 
 func (h *AuthorizeHandler) RegisterRoutes(app *fiber.App) {
 	app.Get("/api/v1/authz/check", h.Authorize)
+	app.Post("/api/v1/authz/check", h.Authorize)
+	app.Post("/api/v1/authz/check/batch", h.BatchAuthorize)
+	app.Get("/api/v1/authz/whoami", h.WhoAmI)
 	app.Post("/api/v1/authz/logout", h.Logout)
 	app.Post("/api/v1/authz/logout-all", h.LogoutAll)
 }
@@ -51,11 +68,19 @@ func (h *AuthorizeHandler) RegisterRoutes(app *fiber.App) {
 // @Param check_blacklist query bool false "Blacklist yoxlanılsın?" default(true)
 // @Param check_rbac query bool false "RBAC yoxlanılsın?" default(false)
 // @Param privilege query string false "RBAC üçün icazə adı (məs: DELETE_USER)"
+// @Param service query string false "privilege əvəzinə: route reyestrindəki servis (məs: users)"
+// @Param method query string false "privilege əvəzinə: orijinal sorğunun HTTP metodu (path ilə tələb olunur)"
+// @Param path query string false "privilege əvəzinə: orijinal sorğunun path-ı, tələb olunan permission-lar reyestrdən tapılır (məs: /users/7)"
+// @Param resource query string false "Resurs tipi, verilərsə həmin resursa bağlı təyinatlar da nəzərə alınır (məs: invoice)"
+// @Param resource_id query string false "Resurs ID-si (məs: tenant/42/invoices/7)"
+// @Param explain query bool false "Qiymətləndirmə izi qaytarılsın? (token yoxlamaları, rolların təyinatları, müddətlər)" default(false)
+// @Param context body object false "Şərtli təyinatlar üçün sorğu konteksti, məs: {\"resource\": {\"department\": \"sales\"}} (yalnız POST; claims, ip və time nəzərə alınmır)"
 // @Success 200 {string} string "OK"
-// @Failure 400 {string} string "Privilege is required for RBAC check"
+// @Failure 400 {string} string "Privilege or method and path are required for RBAC check"
 // @Failure 401 {string} string "Unauthorized"
 // @Failure 403 {string} string "Permission denied"
 // @Router /api/v1/authz/check [get]
+// @Router /api/v1/authz/check [post]
 func (h *AuthorizeHandler) Authorize(c *fiber.Ctx) error {
 	// 1. Token oxu
 	authHeader := c.Get("Authorization")
@@ -69,65 +94,211 @@ func (h *AuthorizeHandler) Authorize(c *fiber.Ctx) error {
 	checkBlacklist := c.QueryBool("check_blacklist", true)
 	checkRBAC := c.QueryBool("check_rbac", false)
 	privilege := c.Query("privilege", "")
+	routeService := strings.TrimSpace(c.Query("service"))
+	routeMethod := strings.TrimSpace(c.Query("method"))
+	routePath := strings.TrimSpace(c.Query("path"))
+	resource := service.Resource{Type: c.Query("resource"), ID: c.Query("resource_id")}
+	explain := newExplanation(c.QueryBool("explain", false))
+	started := time.Now()
 
 	// 3. Token parse və yoxlama
-	claims, err := h.Auth.Validate(token, checkJWT, checkBlacklist)
+	var claims *jwtutil.Claims
+	var err error
+	if explain != nil {
+		var steps []service.ValidationStep
+		claims, steps, err = h.Auth.ValidateExplain(token, checkJWT, checkBlacklist)
+		explain.token(steps, claims)
+	} else {
+		claims, err = h.Auth.Validate(token, checkJWT, checkBlacklist)
+	}
 	if err != nil {
+		reason := service.TokenErrorReasonOf(err)
 		return c.Status(fiber.StatusUnauthorized).JSON(AuthzCheckResponse{
 			Status:           false,
 			Error:            err.Error(),
+			Reason:           string(reason),
+			Blacklisted:      reason == service.ReasonBlacklisted || reason == service.ReasonRevoked,
 			JWTValidated:     checkJWT,
 			BlacklistChecked: checkBlacklist,
 			RBACChecked:      checkRBAC,
 			PrivilegeChecked: privilege,
+			Explain:          explain.done(started),
 		})
 	}
 
 	if claims.UserID != "" && claims.ExpiresAt != nil {
-		h.Auth.AddTokenForTracking(token, claims.ExpiresAt.Unix(), claims.UserID, claims.Role)
+		h.Auth.AddTokenForTracking(jwtutil.TokenID(claims, token), claims.ExpiresAt.Unix(), claims.UserID, claims.Role)
 	}
 
 	// 4. RBAC yoxlama
 	rbacOK := true
+	var decision service.Decision
+	var roles []string
+	var matched string
 	if checkRBAC {
-		if privilege == "" {
+		if msg := rbacTarget(privilege, routeMethod, routePath); msg != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(AuthzCheckResponse{
+				Status:           false,
+				UserID:           claims.UserID,
+				Role:             claims.Role,
+				Error:            msg,
+				JWTValidated:     checkJWT,
+				BlacklistChecked: checkBlacklist,
+				RBACChecked:      checkRBAC,
+				PrivilegeChecked: privilege,
+				Explain:          explain.done(started),
+			})
+		}
+		ctx, err := requestContext(c.Body())
+		if err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(AuthzCheckResponse{
 				Status:           false,
 				UserID:           claims.UserID,
 				Role:             claims.Role,
-				Error:            "Privilege is required for RBAC check",
+				Error:            err.Error(),
 				JWTValidated:     checkJWT,
 				BlacklistChecked: checkBlacklist,
 				RBACChecked:      checkRBAC,
 				PrivilegeChecked: privilege,
+				Explain:          explain.done(started),
 			})
 		}
-		if !h.RBAC.HasPermission(claims.Role, privilege) {
+
+		// path verilibsə tələb olunan permission-lar route reyestrindən götürülür, hamısı yoxlanılır
+		privileges := []string{privilege}
+		if routePath != "" {
+			rule, ok := h.RBAC.ResolveRoute(claims.TenantID, routeService, routeMethod, routePath)
+			if !ok {
+				return c.Status(fiber.StatusOK).JSON(AuthzCheckResponse{
+					Status:           false,
+					UserID:           claims.UserID,
+					Role:             claims.Role,
+					TenantID:         claims.TenantID,
+					Error:            "No route rule for " + strings.TrimSpace(routeService+" "+strings.ToUpper(routeMethod)+" "+routePath),
+					JWTValidated:     checkJWT,
+					BlacklistChecked: checkBlacklist,
+					RBACChecked:      checkRBAC,
+					RBACResult:       false,
+					Service:          routeService,
+					Explain:          explain.done(started),
+				})
+			}
+			matched = rule.Method + " " + rule.Path
+			privileges = rule.Required()
+			privilege = strings.Join(privileges, ",")
+		}
+
+		roles = h.RBAC.RolesForUser(claims.TenantID, claims.UserID, claims.AllRoles())
+		env := conditionEnv(c, claims, ctx)
+		rbacStarted := time.Now()
+		var traces []service.RoleTrace
+		var decisive string // son yoxlanılan permission: rədd olunan və ya sonuncu icazə verilən
+		for _, p := range privileges {
+			decisive = p
+			if explain != nil {
+				decision, traces = h.RBAC.Explain(claims.TenantID, roles, p, resource, env)
+			} else {
+				decision = h.RBAC.Decide(claims.TenantID, roles, p, resource, env)
+			}
+			if !decision.Allowed {
+				privilege = p
+				break
+			}
+		}
+		if decisive != "" {
+			explain.rbac(decisive, decision, traces, time.Since(rbacStarted))
+		}
+		if decisive != "" && !decision.Allowed {
 			rbacOK = false
 			return c.Status(fiber.StatusOK).JSON(AuthzCheckResponse{
 				Status:           false,
 				UserID:           claims.UserID,
 				Role:             claims.Role,
+				Roles:            roles,
+				TenantID:         claims.TenantID,
 				Error:            "Permission denied",
 				JWTValidated:     checkJWT,
 				BlacklistChecked: checkBlacklist,
 				RBACChecked:      checkRBAC,
 				PrivilegeChecked: privilege,
 				RBACResult:       false,
+				RBACEffect:       decision.Effect,
+				RBACRule:         decision.Rule,
+				RBACRole:         decision.Role,
+				Resource:         resource.Type,
+				ResourceID:       resource.ID,
+				RBACScope:        decisionScope(decision),
+				RBACCondition:    decision.Condition,
+				Service:          routeService,
+				Route:            matched,
+				Explain:          explain.done(started),
 			})
 		}
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+	resp := fiber.Map{
 		"status":            true,
 		"user_id":           claims.UserID,
 		"role":              claims.Role,
+		"roles":             roles,
+		"tenant_id":         claims.TenantID,
 		"privilege_checked": privilege,
 		"rbac_checked":      checkRBAC,
 		"rbac_result":       rbacOK,
+		"rbac_effect":       decision.Effect,
+		"rbac_rule":         decision.Rule,
+		"rbac_role":         decision.Role,
+		"resource":          resource.Type,
+		"resource_id":       resource.ID,
+		"rbac_scope":        decisionScope(decision),
+		"rbac_condition":    decision.Condition,
+		"service":           routeService,
+		"route":             matched,
 		"jwt_validated":     checkJWT,
 		"blacklist_checked": checkBlacklist,
-	})
+	}
+	if explain != nil {
+		resp["explain"] = explain.done(started)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// rbacTarget RBAC yoxlamasının hədəfini yoxlayır: ya privilege, ya da reyestr üzrə method + path
+func rbacTarget(privilege, method, path string) string {
+	switch {
+	case privilege != "" && path != "":
+		return "Privilege and path cannot be combined"
+	case path != "" && method == "":
+		return "Method is required with path"
+	case privilege == "" && path == "":
+		return "Privilege or method and path are required for RBAC check"
+	}
+	return ""
+}
+
+// Qalib qaydanın resurs scope-u, qayda qlobaldırsa boş
+func decisionScope(d service.Decision) string {
+	if d.ResourceType == "" {
+		return ""
+	}
+	return d.ResourceType + ":" + d.ResourcePattern
+}
+
+// requestContext /check-ə POST olunan şərt kontekstini (JSON obyekt) oxuyur
+func requestContext(body []byte) (map[string]any, error) {
+	ctx := map[string]any{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &ctx); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Context body must be a JSON object")
+		}
+	}
+	return ctx, nil
+}
+
+// conditionEnv şərtlərin qiymətləndirildiyi konteksti qurur; ip və time həmişə serverdən götürülür.
+// ip müştərinin ünvanıdır: PROXY_HEADER yalnız TRUSTED_PROXIES-dən gələn sorğularda nəzərə alınır, əks halda bağlantının ünvanı
+func conditionEnv(c *fiber.Ctx, claims *jwtutil.Claims, ctx map[string]any) condition.Env {
+	return service.ConditionEnv(claims, ctx, c.IP(), time.Now())
 }
 
 // Logout godoc
@@ -139,6 +310,7 @@ func (h *AuthorizeHandler) Authorize(c *fiber.Ctx) error {
 // @Param Authorization header string true "Bearer {token}"
 // @Success 200 {string} string "Logged out"
 // @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Server error"
 // @Router /api/v1/authz/logout [post]
 func (h *AuthorizeHandler) Logout(c *fiber.Ctx) error {
 	authHeader := c.Get("Authorization")
@@ -152,25 +324,29 @@ func (h *AuthorizeHandler) Logout(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
 	}
 
-	h.Auth.HandleBlacklistEvent(token, claims.ExpiresAt.Unix())
-	h.publishBlacklistEvent(token, claims.ExpiresAt.Unix())
+	if err := h.Auth.RevokeToken(jwtutil.TokenID(claims, token), service.RevocationExpiry(claims)); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
 
 	return c.SendStatus(fiber.StatusOK)
 }
 
 type LogoutAllRequest struct {
-	UserID string `json:"user_id"`
+	// Boş olarsa default tenant; cutoff yalnız həmin tenant-ın tokenlərinə (claims.tenant_id) tətbiq olunur
+	TenantID string `json:"tenant_id"`
+	UserID   string `json:"user_id"`
 }
 
 // LogoutAll godoc
 // @Summary İstifadəçinin bütün tokenlərini bloklayır
-// @Description Verilən `user_id`-yə aid olan bütün JWT-lərin JTI-lərini blackliste əlavə edir və bütün instansiyalara yayır.
+// @Description Verilən `tenant_id` və `user_id` üçün indiyə qədər buraxılmış bütün JWT-ləri ləğv edir (iat <= indiki an). Cutoff DB-də saxlanılır və bütün instansiyalara yayılır. Başqa tenant-da eyni `user_id`-li tokenlərə təsir etmir.
 // @Tags Authorization
 // @Accept json
 // @Produce plain
-// @Param body body LogoutAllRequest true "Bloklanacaq istifadəçinin ID-si"
+// @Param body body LogoutAllRequest true "Bloklanacaq istifadəçinin tenant-ı və ID-si"
 // @Success 200 {string} string "All user tokens blacklisted"
 // @Failure 400 {string} string "user_id is required"
+// @Failure 500 {string} string "Server error"
 // @Router /api/v1/authz/logout-all [post]
 func (h *AuthorizeHandler) LogoutAll(c *fiber.Ctx) error {
 	var req LogoutAllRequest
@@ -178,34 +354,11 @@ func (h *AuthorizeHandler) LogoutAll(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "user_id is required")
 	}
 
-	// local cache-ə əlavə et
-	tokens := h.Auth.GetAllTokensByUser(req.UserID)
-	for _, t := range tokens {
-		h.Auth.HandleBlacklistEvent(t.Token, t.Exp)
+	revokedBefore := time.Now()
+	// Cutoff local cache-ə də yazılır, ayrı-ayrı JTI-ləri blacklist-ə əlavə etməyə ehtiyac yoxdur
+	if err := h.Auth.RevokeAllForUser(req.TenantID, req.UserID, revokedBefore); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
-	// RabbitMQ ilə digər instansiyalara yayımlanır
-	_ = h.publisher.PublishEvent("auth.tokens.fanout", map[string]any{
-		"event":   "TOKEN_BLACKLISTED_ALL",
-		"user_id": req.UserID,
-	}, []string{
-		"blacklist.cache.queue",
-		"blacklist.audit.queue",
-	})
-
 	return c.SendString("All user tokens blacklisted")
 }
-
-func (h *AuthorizeHandler) publishBlacklistEvent(token string, exp int64) {
-	event := struct {
-		Event string `json:"event"`
-		Token string `json:"token"`
-		Exp   int64  `json:"exp"`
-	}{
-		Event: "TOKEN_BLACKLISTED",
-		Token: token,
-		Exp:   exp,
-	}
-
-	_ = h.publisher.PublishEvent("auth.tokens.fanout", event, []string{})
-}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ms-authz/internal/service"
+	"ms-authz/pkg/jwtutil"
+	"strings"
+)
+
+// Bir batch sorğusunda yoxlanıla bilən maksimum privilege sayı
+const maxBatchChecks = 200
+
+const (
+	BatchModeAll = "all" // bütün yoxlamalar keçməlidir (default)
+	BatchModeAny = "any" // ən azı biri keçməlidir
+)
+
+type AuthzBatchCheckItem struct {
+	Key        string `json:"key,omitempty"` // cavabdakı açar; verilməzsə privilege[@resource:resource_id]
+	Privilege  string `json:"privilege"`
+	Resource   string `json:"resource,omitempty"`
+	ResourceID string `json:"resource_id,omitempty"`
+}
+
+type AuthzBatchCheckRequest struct {
+	Mode    string                `json:"mode,omitempty"` // all və ya any
+	Checks  []AuthzBatchCheckItem `json:"checks"`
+	Context map[string]any        `json:"context,omitempty"` // şərtli təyinatlar üçün sorğu konteksti
+}
+
+type AuthzBatchDecision struct {
+	Allowed   bool   `json:"allowed"`
+	Effect    string `json:"effect,omitempty"`
+	Rule      string `json:"rule,omitempty"`
+	Role      string `json:"role,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	Condition string `json:"condition,omitempty"`
+}
+
+type AuthzBatchCheckResponse struct {
+	Status    bool                          `json:"status"` // token etibarlıdır və mode üzrə nəticə müsbətdir
+	UserID    string                        `json:"user_id,omitempty"`
+	Roles     []string                      `json:"roles,omitempty"`
+	TenantID  string                        `json:"tenant_id,omitempty"`
+	Mode      string                        `json:"mode,omitempty"`
+	Result    bool                          `json:"result"` // mode üzrə birləşdirilmiş nəticə
+	Decisions map[string]AuthzBatchDecision `json:"decisions,omitempty"`
+	Error     string                        `json:"error,omitempty"`
+	Reason    string                        `json:"reason,omitempty"`
+}
+
+// BatchAuthorize godoc
+// @Summary Bir neçə privilege-in RBAC yoxlaması
+// @Description Token bir dəfə doğrulanır, checks siyahısındakı hər privilege (və resurs) üçün ayrıca qərar qaytarılır.
+// @Description mode=all (default) bütün, mode=any isə ən azı bir yoxlamanın keçməsini tələb edir; qərarlar hər iki halda bütün elementlər üçün hesablanır.
+// @Description Cavabdakı açar elementin key-i, verilməzsə privilege və ya privilege@resource:resource_id olur.
+// @Tags Authorization
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param check_jwt query bool false "JWT yoxlanılsın?" default(true)
+// @Param check_blacklist query bool false "Blacklist yoxlanılsın?" default(true)
+// @Param body body AuthzBatchCheckRequest true "Yoxlanılacaq privilege-lər"
+// @Success 200 {object} AuthzBatchCheckResponse
+// @Failure 400 {object} AuthzBatchCheckResponse "Invalid body"
+// @Failure 401 {object} AuthzBatchCheckResponse "Unauthorized"
+// @Router /api/v1/authz/check/batch [post]
+func (h *AuthorizeHandler) BatchAuthorize(c *fiber.Ctx) error {
+	authHeader := c.Get("Authorization")
+	if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		return fiber.NewError(fiber.StatusUnauthorized, "Missing or invalid Authorization header")
+	}
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	var req AuthzBatchCheckRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid body")
+	}
+	if err := validateBatchRequest(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(AuthzBatchCheckResponse{Mode: req.Mode, Error: err.Error()})
+	}
+
+	claims, err := h.Auth.Validate(token, c.QueryBool("check_jwt", true), c.QueryBool("check_blacklist", true))
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(AuthzBatchCheckResponse{
+			Mode:   req.Mode,
+			Error:  err.Error(),
+			Reason: string(service.TokenErrorReasonOf(err)),
+		})
+	}
+	if claims.UserID != "" && claims.ExpiresAt != nil {
+		h.Auth.AddTokenForTracking(jwtutil.TokenID(claims, token), claims.ExpiresAt.Unix(), claims.UserID, claims.Role)
+	}
+
+	roles := h.RBAC.RolesForUser(claims.TenantID, claims.UserID, claims.AllRoles())
+	env := conditionEnv(c, claims, req.Context)
+
+	decisions := make(map[string]AuthzBatchDecision, len(req.Checks))
+	anyAllowed, allAllowed := false, true
+	for _, item := range req.Checks {
+		res := service.Resource{Type: item.Resource, ID: item.ResourceID}
+		d := h.RBAC.Decide(claims.TenantID, roles, item.Privilege, res, env)
+		decisions[batchKey(item)] = AuthzBatchDecision{
+			Allowed:   d.Allowed,
+			Effect:    d.Effect,
+			Rule:      d.Rule,
+			Role:      d.Role,
+			Scope:     decisionScope(d),
+			Condition: d.Condition,
+		}
+		anyAllowed = anyAllowed || d.Allowed
+		allAllowed = allAllowed && d.Allowed
+	}
+
+	result := allAllowed
+	if req.Mode == BatchModeAny {
+		result = anyAllowed
+	}
+	return c.Status(fiber.StatusOK).JSON(AuthzBatchCheckResponse{
+		Status:    result,
+		UserID:    claims.UserID,
+		Roles:     roles,
+		TenantID:  claims.TenantID,
+		Mode:      req.Mode,
+		Result:    result,
+		Decisions: decisions,
+	})
+}
+
+// validateBatchRequest mode-u normallaşdırır, boş və ya təkrarlanan açarları rədd edir
+func validateBatchRequest(req *AuthzBatchCheckRequest) error {
+	req.Mode = strings.ToLower(strings.TrimSpace(req.Mode))
+	if req.Mode == "" {
+		req.Mode = BatchModeAll
+	}
+	if req.Mode != BatchModeAll && req.Mode != BatchModeAny {
+		return fiber.NewError(fiber.StatusBadRequest, "mode must be all or any")
+	}
+	if len(req.Checks) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "checks is required")
+	}
+	if len(req.Checks) > maxBatchChecks {
+		return fiber.NewError(fiber.StatusBadRequest, "too many checks")
+	}
+
+	seen := make(map[string]bool, len(req.Checks))
+	for _, item := range req.Checks {
+		if item.Privilege == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "privilege is required for every check")
+		}
+		key := batchKey(item)
+		if seen[key] {
+			return fiber.NewError(fiber.StatusBadRequest, "duplicate check key: "+key)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func batchKey(item AuthzBatchCheckItem) string {
+	if item.Key != "" {
+		return item.Key
+	}
+	if item.Resource == "" && item.ResourceID == "" {
+		return item.Privilege
+	}
+	return item.Privilege + "@" + item.Resource + ":" + item.ResourceID
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"fmt"
+	"ms-authz/internal/service"
+	"ms-authz/pkg/jwtutil"
+	"time"
+)
+
+// AuthzExplanation /check?explain=true cavabındakı qiymətləndirmə izi
+type AuthzExplanation struct {
+	Summary         string             `json:"summary"`
+	Token           []ExplainTokenStep `json:"token"`
+	Claims          *ExplainClaims     `json:"claims,omitempty"`
+	Roles           []ExplainRole      `json:"roles,omitempty"`
+	RBACDurationUs  int64              `json:"rbac_duration_us,omitempty"`
+	TotalDurationUs int64              `json:"total_duration_us"`
+}
+
+type ExplainTokenStep struct {
+	Check      string `json:"check"`  // jwt, blacklist, revocation
+	Status     string `json:"status"` // passed, failed, skipped
+	Reason     string `json:"reason,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+	DurationUs int64  `json:"duration_us"`
+}
+
+// ExplainClaims tokenin yoxlamada istifadə olunan claim-ləri
+type ExplainClaims struct {
+	Issuer    string     `json:"iss,omitempty"`
+	Subject   string     `json:"sub,omitempty"`
+	Audience  []string   `json:"aud,omitempty"`
+	TokenID   string     `json:"jti,omitempty"`
+	TenantID  string     `json:"tenant_id,omitempty"`
+	Roles     []string   `json:"roles,omitempty"` // tokendəki role və roles
+	IssuedAt  *time.Time `json:"iat,omitempty"`
+	NotBefore *time.Time `json:"nbf,omitempty"`
+	ExpiresAt *time.Time `json:"exp,omitempty"`
+}
+
+type ExplainRole struct {
+	Role   string         `json:"role"`
+	Known  bool           `json:"known"` // rol tenant-da mövcuddur
+	Grants []ExplainGrant `json:"grants"`
+}
+
+type ExplainGrant struct {
+	Effect          string `json:"effect"`
+	Pattern         string `json:"pattern"`
+	ResourceType    string `json:"resource_type,omitempty"`
+	ResourcePattern string `json:"resource_pattern,omitempty"`
+	Condition       string `json:"condition,omitempty"`
+	Outcome         string `json:"outcome"` // matched, no_match, out_of_scope, condition_false, condition_error
+	Error           string `json:"error,omitempty"`
+	Applied         bool   `json:"applied"`
+	Decisive        bool   `json:"decisive"`
+}
+
+// newExplanation explain=false olduqda nil qaytarır, metodlar nil üzərində heç nə etmir
+func newExplanation(enabled bool) *AuthzExplanation {
+	if !enabled {
+		return nil
+	}
+	return &AuthzExplanation{Token: []ExplainTokenStep{}}
+}
+
+func (e *AuthzExplanation) token(steps []service.ValidationStep, claims *jwtutil.Claims) {
+	if e == nil {
+		return
+	}
+	for _, s := range steps {
+		e.Token = append(e.Token, ExplainTokenStep{
+			Check:      s.Check,
+			Status:     s.Status,
+			Reason:     string(s.Reason),
+			Detail:     s.Detail,
+			DurationUs: s.Duration.Microseconds(),
+		})
+		if s.Status == service.StepFailed {
+			e.Summary = fmt.Sprintf("token rejected by %s check: %s", s.Check, s.Detail)
+		}
+	}
+	if claims == nil {
+		return
+	}
+	e.Summary = "token accepted"
+	e.Claims = &ExplainClaims{
+		Issuer:   claims.Issuer,
+		Subject:  claims.Subject,
+		Audience: claims.Audience,
+		TokenID:  claims.ID,
+		TenantID: claims.TenantID,
+		Roles:    claims.AllRoles(),
+	}
+	if claims.IssuedAt != nil {
+		e.Claims.IssuedAt = &claims.IssuedAt.Time
+	}
+	if claims.NotBefore != nil {
+		e.Claims.NotBefore = &claims.NotBefore.Time
+	}
+	if claims.ExpiresAt != nil {
+		e.Claims.ExpiresAt = &claims.ExpiresAt.Time
+	}
+}
+
+func (e *AuthzExplanation) rbac(privilege string, d service.Decision, traces []service.RoleTrace, took time.Duration) {
+	if e == nil {
+		return
+	}
+	e.RBACDurationUs = took.Microseconds()
+	for _, t := range traces {
+		role := ExplainRole{Role: t.Role, Known: t.Known, Grants: make([]ExplainGrant, 0, len(t.Grants))}
+		for _, g := range t.Grants {
+			role.Grants = append(role.Grants, ExplainGrant{
+				Effect:          g.Effect,
+				Pattern:         g.Pattern,
+				ResourceType:    g.ResourceType,
+				ResourcePattern: g.ResourcePattern,
+				Condition:       g.Condition,
+				Outcome:         g.Outcome,
+				Error:           g.Error,
+				Applied:         g.Applied,
+				Decisive:        g.Decisive,
+			})
+		}
+		e.Roles = append(e.Roles, role)
+	}
+
+	switch {
+	case d.Effect == "":
+		e.Summary = fmt.Sprintf("%s denied: no grant of roles %v matches", privilege, roleNames(traces))
+	case d.Allowed:
+		e.Summary = fmt.Sprintf("%s allowed by %s of role %s", privilege, d.Rule, d.Role)
+	default:
+		e.Summary = fmt.Sprintf("%s denied by deny grant %s of role %s", privilege, d.Rule, d.Role)
+	}
+	if scope := decisionScope(d); scope != "" {
+		e.Summary += " on " + scope
+	}
+	if d.Condition != "" {
+		e.Summary += " when " + d.Condition
+	}
+}
+
+func (e *AuthzExplanation) done(start time.Time) *AuthzExplanation {
+	if e != nil {
+		e.TotalDurationUs = time.Since(start).Microseconds()
+	}
+	return e
+}
+
+func roleNames(traces []service.RoleTrace) []string {
+	names := make([]string, len(traces))
+	for i, t := range traces {
+		names[i] = t.Role
+	}
+	return names
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ms-authz/internal/service"
+	"net/http"
+)
+
+// ForwardAuthHandler Traefik forwardAuth və nginx auth_request üçün endpoint.
+// Orijinal sorğunun metodu və URI-si proxy-nin header-lərindən oxunur.
+type ForwardAuthHandler struct {
+	Gateway *service.GatewayAuthorizer
+}
+
+func NewForwardAuthHandler(gateway *service.GatewayAuthorizer) *ForwardAuthHandler {
+	return &ForwardAuthHandler{Gateway: gateway}
+}
+
+func (h *ForwardAuthHandler) RegisterRoutes(app *fiber.App) {
+	// nginx auth_request alt sorğunu orijinal metodla göndərir
+	app.All("/api/v1/authz/forward-auth", h.ForwardAuth)
+}
+
+// ForwardAuth godoc
+// @Summary Ingress üçün forward-auth
+// @Description Orijinal metod X-Forwarded-Method (və ya X-Original-Method), URI isə X-Forwarded-Uri (və ya X-Original-URI) header-indən oxunur
+// @Description və route reyestri ilə tələb olunan permission-lara çevrilir (service verilməzsə GATEWAY_SERVICE). Token /check-dəki kimi (JWT + blacklist) yoxlanılır.
+// @Description URI percent-decode və path.Clean ilə normallaşdırılır, bundan sonra ".." seqmenti qalan path rədd edilir.
+// @Description Token-siz yalnız default tenant-ın public route-ları keçir, tokenli sorğu token-in tenant-ının reyestri ilə həll olunur.
+// @Description İcazə verildikdə 200 və X-User-Id, X-User-Role, X-Tenant-Id header-ləri qaytarılır.
+// @Description Şərtlərdəki ip /check-dəki kimi müştəri IP-sidir: PROXY_HEADER yalnız TRUSTED_PROXIES-dən gələn sorğularda nəzərə alınır, əks halda bağlantının ünvanı.
+// @Tags Authorization
+// @Produce json
+// @Param Authorization header string false "Bearer {token}"
+// @Param service query string false "Route reyestrindəki servis (verilməzsə GATEWAY_SERVICE)"
+// @Param X-Forwarded-Method header string false "Orijinal metod (Traefik)"
+// @Param X-Forwarded-Uri header string false "Orijinal URI (Traefik)"
+// @Param X-Original-Method header string false "Orijinal metod (nginx)"
+// @Param X-Original-URI header string false "Orijinal URI (nginx)"
+// @Success 200 {string} string "OK"
+// @Failure 400 {string} string "Missing X-Forwarded-Uri / X-Original-URI header"
+// @Failure 401 {object} AuthzCheckResponse "Unauthorized"
+// @Failure 403 {object} AuthzCheckResponse "Permission denied or no route rule"
+// @Router /api/v1/authz/forward-auth [get]
+func (h *ForwardAuthHandler) ForwardAuth(c *fiber.Ctx) error {
+	uri := firstHeader(c, "X-Forwarded-Uri", "X-Original-URI")
+	if uri == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Missing X-Forwarded-Uri / X-Original-URI header")
+	}
+	method := firstHeader(c, "X-Forwarded-Method", "X-Original-Method")
+	if method == "" {
+		method = c.Method()
+	}
+	d := h.Gateway.Authorize(service.GatewayRequest{
+		Service:       c.Query("service"),
+		Method:        method,
+		Path:          uri,
+		Authorization: c.Get("Authorization"),
+		ClientIP:      c.IP(),
+	})
+	if !d.Allowed() {
+		resp := AuthzCheckResponse{
+			Status:           false,
+			PrivilegeChecked: d.Permission,
+			Error:            d.Error,
+			Reason:           string(d.Reason),
+		}
+		if d.Claims != nil {
+			resp.UserID = d.Claims.UserID
+			resp.Role = d.Claims.Role
+			resp.TenantID = d.Claims.TenantID
+		}
+		return c.Status(d.Status).JSON(resp)
+	}
+
+	// Boş identity header-ləri göndərilmir; proxy onları upstream sorğusundan silir və ya boş təyin edir
+	for key, value := range d.Identity() {
+		if value != "" {
+			c.Set(http.CanonicalHeaderKey(key), value)
+		}
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func firstHeader(c *fiber.Ctx, keys ...string) string {
+	for _, key := range keys {
+		if v := c.Get(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
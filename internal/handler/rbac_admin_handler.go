@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"ms-authz/internal/domain/model"
 	"ms-authz/internal/domain/repository"
 	"ms-authz/internal/dto"
 	"ms-authz/internal/service"
+	"ms-authz/pkg/condition"
+	"ms-authz/pkg/permission"
 	"strconv"
+	"strings"
 )
 
 type RBACAdminHandler struct {
@@ -27,6 +31,14 @@ func (h *RBACAdminHandler) RegisterRoutes(app *fiber.App) {
 	app.Get("/api/v1/authz/roles/roles-with-permissions", h.GetRolesWithPermissions)
 	app.Get("/api/v1/authz/roles/:id/permissions", h.GetPermissionsByRoleID)
 	app.Put("/api/v1/authz/roles/:id", h.UpdateRole)
+	app.Get("/api/v1/authz/roles/:id/parents", h.GetRoleParents)
+	app.Post("/api/v1/authz/roles/:id/parents/:parentID", h.AddRoleParent)
+	app.Delete("/api/v1/authz/roles/:id/parents/:parentID", h.RemoveRoleParent)
+	app.Get("/api/v1/authz/roles/:id/effective-permissions", h.GetEffectivePermissions)
+
+	app.Get("/api/v1/authz/users/:id/roles", h.GetUserRoles)
+	app.Post("/api/v1/authz/users/:id/roles/:roleID", h.AssignUserRole)
+	app.Delete("/api/v1/authz/users/:id/roles/:roleID", h.UnassignUserRole)
 
 	app.Post("/api/v1/authz/permissions", h.CreatePermission)
 	app.Get("/api/v1/authz/permissions", h.GetPermissions)
@@ -35,44 +47,57 @@ func (h *RBACAdminHandler) RegisterRoutes(app *fiber.App) {
 	app.Get("/api/v1/authz/permissions/:id/roles", h.GetRolesByPermissionID)
 	app.Put("/api/v1/authz/permissions/:id", h.UpdatePermission)
 
+	app.Post("/api/v1/authz/routes", h.CreateRoute)
+	app.Get("/api/v1/authz/routes", h.GetRoutes)
+	app.Get("/api/v1/authz/routes/:id", h.GetRoute)
+	app.Put("/api/v1/authz/routes/:id", h.UpdateRoute)
+	app.Delete("/api/v1/authz/routes/:id", h.DeleteRoute)
+
 }
 
 // CreateRole godoc
 // @Summary Yeni rol yaradır
 // @Tags Role
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
 // @Accept json
 // @Produce json
-// @Param role body model.Role true "Yeni rol"
+// @Param role body dto.RoleRequest true "Yeni rol"
 // @Success 200 {object} model.Role
 // @Failure 400 {string} string "Invalid body"
 // @Failure 500 {string} string "Server error"
 // @Router /api/v1/authz/roles [post]
 func (h *RBACAdminHandler) CreateRole(c *fiber.Ctx) error {
-	var role model.Role
-	if err := c.BodyParser(&role); err != nil {
+	var req dto.RoleRequest
+	if err := c.BodyParser(&req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid body")
 	}
-	if err := h.UoW.RoleRepo().Create(&role); err != nil {
+	role := model.Role{Name: req.Name, Description: req.Description}
+	err := h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.RoleRepo().Create(&role); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACRoleCreated, map[string]any{
+			"role_id":   role.ID,
+			"role_name": role.Name,
+		})
+	})
+	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
-	h.RBAC.PublishCacheEvent("RBAC_ROLE_CREATED", map[string]any{
-		"role_id":   role.ID,
-		"role_name": role.Name,
-	})
-
 	return c.JSON(role)
 }
 
 // GetRoles godoc
 // @Summary Mövcud bütün rolları qaytarır
 // @Tags Role
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
 // @Produce json
 // @Success 200 {array} model.Role
 // @Failure 500 {string} string "Server error"
 // @Router /api/v1/authz/roles [get]
 func (h *RBACAdminHandler) GetRoles(c *fiber.Ctx) error {
-	roles, err := h.UoW.RoleRepo().GetAll()
+	roles, err := h.uow(c).RoleRepo().GetAll()
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
@@ -91,10 +116,11 @@ func (h *RBACAdminHandler) GetRoles(c *fiber.Ctx) error {
 // UpdateRole godoc
 // @Summary Mövcud rolu yeniləyir
 // @Tags Role
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
 // @Accept json
 // @Produce json
 // @Param id path int true "Role ID"
-// @Param role body model.Role true "Yenilənmiş rol məlumatı"
+// @Param role body dto.RoleRequest true "Yenilənmiş rol məlumatı"
 // @Success 200 {object} model.Role
 // @Failure 400 {string} string "Invalid input"
 // @Failure 404 {string} string "Role not found"
@@ -106,73 +132,91 @@ func (h *RBACAdminHandler) UpdateRole(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
 	}
 
-	var updated model.Role
+	var updated dto.RoleRequest
 	if err := c.BodyParser(&updated); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid body")
 	}
 
-	role, err := h.UoW.RoleRepo().GetByID(uint(id))
+	role, err := h.uow(c).RoleRepo().GetByID(uint(id))
 	if err != nil {
 		return fiber.NewError(fiber.StatusNotFound, "Role not found")
 	}
 
 	role.Name = updated.Name
 
-	if err := h.UoW.RoleRepo().Update(role); err != nil {
+	err = h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.RoleRepo().Update(role); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACRoleUpdated, map[string]any{
+			"role_id":  role.ID,
+			"new_name": updated.Name,
+		})
+	})
+	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
-	h.RBAC.PublishCacheEvent("RBAC_ROLE_UPDATED", map[string]any{
-		"role_id":  role.ID,
-		"new_name": updated.Name,
-	})
-
 	return c.JSON(role)
 }
 
 // DeleteRole godoc
 // @Summary Rolu ID-yə görə silir
 // @Tags Role
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
 // @Param id path int true "Role ID"
 // @Success 204 {string} string "No Content"
 // @Failure 500 {string} string "Server error"
 // @Router /api/v1/authz/roles/{id} [delete]
 func (h *RBACAdminHandler) DeleteRole(c *fiber.Ctx) error {
 	id, _ := strconv.Atoi(c.Params("id"))
-	if err := h.UoW.RoleRepo().Delete(uint(id)); err != nil {
+	err := h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.RoleRepo().Delete(uint(id)); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACRoleDeleted, map[string]any{
+			"role_id": id,
+		})
+	})
+	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
-	h.RBAC.PublishCacheEvent("RBAC_ROLE_DELETED", map[string]any{
-		"role_id": id,
-	})
-
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
 // CreatePermission godoc
 // @Summary Yeni permission yaradır
 // @Tags Permission
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
 // @Accept json
 // @Produce json
-// @Param permission body model.Permission true "Yeni permission"
+// @Param permission body dto.PermissionRequest true "Yeni permission"
 // @Success 200 {object} model.Permission
-// @Failure 400 {string} string "Invalid body"
+// @Failure 400 {string} string "Invalid body or permission pattern"
 // @Failure 500 {string} string "Server error"
 // @Router /api/v1/authz/permissions [post]
 func (h *RBACAdminHandler) CreatePermission(c *fiber.Ctx) error {
-	var p model.Permission
-	if err := c.BodyParser(&p); err != nil {
+	var req dto.PermissionRequest
+	if err := c.BodyParser(&req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid body")
 	}
-	if err := h.UoW.PermissionRepo().Create(&p); err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	if err := permission.Validate(req.Name); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
-
-	h.RBAC.PublishCacheEvent("RBAC_PERMISSION_CREATED", map[string]any{
-		"perm_id":   p.ID,
-		"perm_name": p.Name,
+	p := model.Permission{Name: req.Name, Description: req.Description}
+	err := h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.PermissionRepo().Create(&p); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACPermissionCreated, map[string]any{
+			"perm_id":   p.ID,
+			"perm_name": p.Name,
+		})
 	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
 
 	return c.JSON(p)
 }
@@ -180,12 +224,13 @@ func (h *RBACAdminHandler) CreatePermission(c *fiber.Ctx) error {
 // GetPermissions godoc
 // @Summary Bütün permission-ları qaytarır
 // @Tags Permission
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
 // @Produce json
 // @Success 200 {array} model.Permission
 // @Failure 500 {string} string "Server error"
 // @Router /api/v1/authz/permissions [get]
 func (h *RBACAdminHandler) GetPermissions(c *fiber.Ctx) error {
-	perms, err := h.UoW.PermissionRepo().GetAll()
+	perms, err := h.uow(c).PermissionRepo().GetAll()
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
@@ -204,10 +249,11 @@ func (h *RBACAdminHandler) GetPermissions(c *fiber.Ctx) error {
 // UpdatePermission godoc
 // @Summary Mövcud permission-u yeniləyir
 // @Tags Permission
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
 // @Accept json
 // @Produce json
 // @Param id path int true "Permission ID"
-// @Param permission body model.Permission true "Yenilənmiş permission məlumatı"
+// @Param permission body dto.PermissionRequest true "Yenilənmiş permission məlumatı"
 // @Success 200 {object} model.Permission
 // @Failure 400 {string} string "Invalid input"
 // @Failure 404 {string} string "Permission not found"
@@ -219,165 +265,565 @@ func (h *RBACAdminHandler) UpdatePermission(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
 	}
 
-	var updated model.Permission
+	var updated dto.PermissionRequest
 	if err := c.BodyParser(&updated); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid body")
 	}
+	if err := permission.Validate(updated.Name); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
 
-	perm, err := h.UoW.PermissionRepo().GetByID(uint(id))
+	perm, err := h.uow(c).PermissionRepo().GetByID(uint(id))
 	if err != nil {
 		return fiber.NewError(fiber.StatusNotFound, "Permission not found")
 	}
 
 	perm.Name = updated.Name
 
-	if err := h.UoW.PermissionRepo().Update(perm); err != nil {
+	err = h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.PermissionRepo().Update(perm); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACPermissionUpdated, map[string]any{
+			"perm_id":  perm.ID,
+			"new_name": updated.Name,
+		})
+	})
+	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
-	h.RBAC.PublishCacheEvent("RBAC_PERMISSION_UPDATED", map[string]any{
-		"perm_id":  perm.ID,
-		"new_name": updated.Name,
-	})
-
 	return c.JSON(perm)
 }
 
 // DeletePermission godoc
 // @Summary Permission-u ID ilə silir
 // @Tags Permission
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
 // @Param id path int true "Permission ID"
 // @Success 204 {string} string "No Content"
 // @Failure 500 {string} string "Server error"
 // @Router /api/v1/authz/permissions/{id} [delete]
 func (h *RBACAdminHandler) DeletePermission(c *fiber.Ctx) error {
 	id, _ := strconv.Atoi(c.Params("id"))
-	if err := h.UoW.PermissionRepo().Delete(uint(id)); err != nil {
+	err := h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.PermissionRepo().Delete(uint(id)); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACPermissionDeleted, map[string]any{
+			"perm_id": id,
+		})
+	})
+	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
-	h.RBAC.PublishCacheEvent("RBAC_PERMISSION_DELETED", map[string]any{
-		"perm_id": id,
-	})
-
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
 // AssignPermission godoc
 // @Summary Role-a permission təyin edir
+// @Description effect=deny ilə təyin olunan permission rolun (və ondan miras alan rolların) bütün allow-larından üstündür.
+// @Description resource_type verilərsə təyinat yalnız həmin tipdə və ID-si resource_pattern-ə uyğun resurslara aiddir
+// @Description (path.Match sintaksisi, məs. invoice + tenant/42/invoices/*). resource_pattern boşdursa tipin bütün resursları.
+// @Description condition verilərsə təyinat yalnız şərt doğru olduqda tətbiq olunur, məs. ip in 10.0.0.0/8 və ya claims.department == resource.department.
+// @Description Eyni scope-lu təyinat artıq varsa onun effekti və şərti yenilənir.
 // @Tags Role-Permission
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
 // @Param roleID path int true "Role ID"
 // @Param permID path int true "Permission ID"
+// @Param effect query string false "allow və ya deny" default(allow) Enums(allow, deny)
+// @Param resource_type query string false "Resurs tipi, məs. invoice (* – istənilən tip)"
+// @Param resource_pattern query string false "Resurs ID pattern-i, məs. tenant/42/invoices/*"
+// @Param condition query string false "ABAC şərti, məs. time between 08:00-18:00"
 // @Success 204 {string} string "No Content"
+// @Failure 400 {string} string "Invalid effect, resource scope or condition"
+// @Failure 404 {string} string "Role or permission not found"
 // @Failure 500 {string} string "Server error"
 // @Router /api/v1/authz/roles/{roleID}/permissions/{permID} [post]
 func (h *RBACAdminHandler) AssignPermission(c *fiber.Ctx) error {
 	roleID, _ := strconv.Atoi(c.Params("roleID"))
 	permID, _ := strconv.Atoi(c.Params("permID"))
-	if err := h.UoW.RolePermissionRepo().AddPermission(uint(roleID), uint(permID)); err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	effect := c.Query("effect", model.EffectAllow)
+	if effect != model.EffectAllow && effect != model.EffectDeny {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid effect")
+	}
+	resourceType, resourcePattern, err := resourceScope(c)
+	if err != nil {
+		return err
+	}
+	cond := strings.TrimSpace(c.Query("condition"))
+	if cond != "" {
+		if _, err := condition.Compile(cond); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
 	}
 
-	h.RBAC.PublishCacheEvent("RBAC_PERMISSION_ASSIGNED", map[string]any{
-		"role_id": roleID,
-		"perm_id": permID,
+	// Rol və permission sorğunun tenant-ına aid olmalıdır
+	if _, err := h.uow(c).RoleRepo().GetByID(uint(roleID)); err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Role not found")
+	}
+	if _, err := h.uow(c).PermissionRepo().GetByID(uint(permID)); err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Permission not found")
+	}
+
+	grant := model.RolePermission{
+		RoleID:          uint(roleID),
+		PermissionID:    uint(permID),
+		Effect:          effect,
+		ResourceType:    resourceType,
+		ResourcePattern: resourcePattern,
+		Condition:       cond,
+	}
+	err = h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.RolePermissionRepo().AddPermission(&grant); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACPermissionAssigned, map[string]any{
+			"role_id":          roleID,
+			"perm_id":          permID,
+			"effect":           effect,
+			"resource_type":    resourceType,
+			"resource_pattern": resourcePattern,
+			"condition":        cond,
+		})
 	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
 
-	h.RBAC.ReloadCache()
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
 // RemovePermission godoc
 // @Summary Role-dan permission silir
+// @Description resource_type verilməzsə permission-un rola bütün təyinatları (scope-lu olanlar daxil) silinir,
+// @Description verilərsə yalnız həmin scope-lu təyinat.
 // @Tags Role-Permission
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
 // @Param roleID path int true "Role ID"
 // @Param permID path int true "Permission ID"
+// @Param resource_type query string false "Resurs tipi"
+// @Param resource_pattern query string false "Resurs ID pattern-i"
 // @Success 204 {string} string "No Content"
+// @Failure 400 {string} string "Invalid resource scope"
+// @Failure 404 {string} string "Role not found"
 // @Failure 500 {string} string "Server error"
 // @Router /api/v1/authz/roles/{roleID}/permissions/{permID} [delete]
 func (h *RBACAdminHandler) RemovePermission(c *fiber.Ctx) error {
 	roleID, _ := strconv.Atoi(c.Params("roleID"))
 	permID, _ := strconv.Atoi(c.Params("permID"))
-	if err := h.UoW.RolePermissionRepo().RemovePermission(uint(roleID), uint(permID)); err != nil {
+	resourceType, resourcePattern, err := resourceScope(c)
+	if err != nil {
+		return err
+	}
+	if _, err := h.uow(c).RoleRepo().GetByID(uint(roleID)); err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Role not found")
+	}
+
+	err = h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		var err error
+		if resourceType == "" {
+			err = tx.RolePermissionRepo().RemovePermission(uint(roleID), uint(permID))
+		} else {
+			err = tx.RolePermissionRepo().RemoveScopedPermission(uint(roleID), uint(permID), resourceType, resourcePattern)
+		}
+		if err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACPermissionRemoved, map[string]any{
+			"role_id":          roleID,
+			"perm_id":          permID,
+			"resource_type":    resourceType,
+			"resource_pattern": resourcePattern,
+		})
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Təyinatın resurs scope-u query parametrlərindən. Pattern tipsiz verilə bilməz.
+func resourceScope(c *fiber.Ctx) (resourceType, resourcePattern string, err error) {
+	resourceType = strings.TrimSpace(c.Query("resource_type"))
+	resourcePattern = strings.TrimSpace(c.Query("resource_pattern"))
+	if resourceType == "" && resourcePattern != "" {
+		return "", "", fiber.NewError(fiber.StatusBadRequest, "resource_type is required with resource_pattern")
+	}
+	if err := service.ValidateResourcePattern(resourcePattern); err != nil {
+		return "", "", fiber.NewError(fiber.StatusBadRequest, "Invalid resource_pattern")
+	}
+	return resourceType, resourcePattern, nil
+}
+
+// GetRoleParents godoc
+// @Summary Rolun birbaşa valideyn rollarını qaytarır
+// @Tags Role-Hierarchy
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Param id path int true "Role ID"
+// @Produce json
+// @Success 200 {array} dto.RoleDTO
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Role not found"
+// @Router /api/v1/authz/roles/{id}/parents [get]
+func (h *RBACAdminHandler) GetRoleParents(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+
+	parents, err := h.uow(c).RoleRepo().GetParents(uint(id))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Role not found")
+	}
+
+	result := make([]dto.RoleDTO, 0, len(parents))
+	for _, p := range parents {
+		result = append(result, dto.RoleDTO{
+			ID:   p.ID,
+			Name: p.Name,
+		})
+	}
+
+	return c.JSON(result)
+}
+
+// AddRoleParent godoc
+// @Summary Rola valideyn rol əlavə edir (rol valideynin permission-larını miras alır)
+// @Tags Role-Hierarchy
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Param id path int true "Role ID"
+// @Param parentID path int true "Parent Role ID"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Role not found"
+// @Failure 409 {string} string "Role inheritance would create a cycle"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/roles/{id}/parents/{parentID} [post]
+func (h *RBACAdminHandler) AddRoleParent(c *fiber.Ctx) error {
+	roleID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+	parentID, err := strconv.Atoi(c.Params("parentID"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+
+	err = h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := h.RBAC.AddRoleParent(tx, uint(roleID), uint(parentID)); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACRoleParentAdded, map[string]any{
+			"role_id":   roleID,
+			"parent_id": parentID,
+		})
+	})
+	switch {
+	case errors.Is(err, service.ErrRoleNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrRoleCycle):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case err != nil:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RemoveRoleParent godoc
+// @Summary Roldan valideyn rol əlaqəsini silir
+// @Tags Role-Hierarchy
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Param id path int true "Role ID"
+// @Param parentID path int true "Parent Role ID"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Role not found"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/roles/{id}/parents/{parentID} [delete]
+func (h *RBACAdminHandler) RemoveRoleParent(c *fiber.Ctx) error {
+	roleID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+	parentID, err := strconv.Atoi(c.Params("parentID"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+
+	if _, err := h.uow(c).RoleRepo().GetByID(uint(roleID)); err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Role not found")
+	}
+
+	err = h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.RoleRepo().RemoveParent(uint(roleID), uint(parentID)); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACRoleParentRemoved, map[string]any{
+			"role_id":   roleID,
+			"parent_id": parentID,
+		})
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetEffectivePermissions godoc
+// @Summary Rolun effektiv allow və deny permission-larını (miras alınanlar daxil) qaytarır
+// @Tags Role-Hierarchy
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Param id path int true "Role ID"
+// @Produce json
+// @Success 200 {object} dto.EffectivePermissionsDTO
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Role not found"
+// @Router /api/v1/authz/roles/{id}/effective-permissions [get]
+func (h *RBACAdminHandler) GetEffectivePermissions(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+
+	role, err := h.uow(c).RoleRepo().GetByID(uint(id))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Role not found")
+	}
+
+	sets := h.RBAC.EffectivePermissions(tenantOf(c), role.Name)
+	return c.JSON(dto.EffectivePermissionsDTO{
+		Role:   role.Name,
+		Allow:  sets[0].Allow,
+		Deny:   sets[0].Deny,
+		Scoped: scopedPermissionsDTO(sets[1:]),
+	})
+}
+
+func scopedPermissionsDTO(sets []service.PermissionSet) []dto.ScopedPermissionsDTO {
+	result := make([]dto.ScopedPermissionsDTO, 0, len(sets))
+	for _, set := range sets {
+		result = append(result, dto.ScopedPermissionsDTO{
+			ResourceType:    set.ResourceType,
+			ResourcePattern: set.ResourcePattern,
+			Condition:       set.Condition,
+			Allow:           set.Allow,
+			Deny:            set.Deny,
+		})
+	}
+	return result
+}
+
+// GetUserRoles godoc
+// @Summary İstifadəçiyə server-side təyin olunmuş rolları qaytarır
+// @Tags User-Role
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Param id path int true "User ID"
+// @Produce json
+// @Success 200 {array} dto.RoleDTO
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "User not found"
+// @Router /api/v1/authz/users/{id}/roles [get]
+func (h *RBACAdminHandler) GetUserRoles(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+
+	roles, err := h.uow(c).UserRepo().GetRoles(uint(id))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "User not found")
+	}
+
+	result := make([]dto.RoleDTO, 0, len(roles))
+	for _, r := range roles {
+		result = append(result, dto.RoleDTO{
+			ID:   r.ID,
+			Name: r.Name,
+		})
+	}
+
+	return c.JSON(result)
+}
+
+// AssignUserRole godoc
+// @Summary İstifadəçiyə rol təyin edir
+// @Description /authz/check token-dəki rollarla bu təyinatların birləşməsini yoxlayır.
+// @Tags User-Role
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Param id path int true "User ID"
+// @Param roleID path int true "Role ID"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "User or role not found"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/users/{id}/roles/{roleID} [post]
+func (h *RBACAdminHandler) AssignUserRole(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+	roleID, err := strconv.Atoi(c.Params("roleID"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+
+	if _, err := h.uow(c).UserRepo().GetByID(uint(userID)); err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "User not found")
+	}
+	if _, err := h.uow(c).RoleRepo().GetByID(uint(roleID)); err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "Role not found")
+	}
+
+	err = h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.UserRepo().AssignRole(uint(userID), uint(roleID)); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACUserRoleAssigned, map[string]any{
+			"user_id": userID,
+			"role_id": roleID,
+		})
+	})
+	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
-	h.RBAC.PublishCacheEvent("RBAC_PERMISSION_REMOVED", map[string]any{
-		"role_id": roleID,
-		"perm_id": permID,
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// UnassignUserRole godoc
+// @Summary İstifadəçidən rolu geri alır
+// @Tags User-Role
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Param id path int true "User ID"
+// @Param roleID path int true "Role ID"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "User not found"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/users/{id}/roles/{roleID} [delete]
+func (h *RBACAdminHandler) UnassignUserRole(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+	roleID, err := strconv.Atoi(c.Params("roleID"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+
+	if _, err := h.uow(c).UserRepo().GetByID(uint(userID)); err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "User not found")
+	}
+
+	err = h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.UserRepo().UnassignRole(uint(userID), uint(roleID)); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACUserRoleUnassigned, map[string]any{
+			"user_id": userID,
+			"role_id": roleID,
+		})
 	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
 // GetRolesWithPermissions godoc
-// @Summary Rolları və onlara bağlı permission-ları qaytarır
+// @Summary Rolları və onlara birbaşa təyin olunmuş permission-ları qaytarır
+// @Description Hər təyinat effekti (allow/deny), resurs scope-u və şərti ilə göstərilir; miras alınanlar üçün effective-permissions.
 // @Tags Role
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
 // @Produce json
-// @Success 200 {array} model.Role
+// @Success 200 {array} dto.RoleWithPermissionsDTO
 // @Failure 500 {string} string "Server error"
 // @Router /api/v1/authz/roles/roles-with-permissions [get]
 func (h *RBACAdminHandler) GetRolesWithPermissions(c *fiber.Ctx) error {
-	roles, err := h.UoW.RoleRepo().GetAllWithPermissions()
+	roles, err := h.uow(c).RoleRepo().GetAll()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	grants, err := h.uow(c).RolePermissionRepo().GetAll()
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
-	var result []dto.RoleWithPermissionsDTO
-	for _, r := range roles {
-		roleDTO := dto.RoleWithPermissionsDTO{
-			ID:   r.ID,
-			Name: r.Name,
-		}
-		for _, p := range r.Permissions {
-			roleDTO.Permissions = append(roleDTO.Permissions, dto.PermissionDTO{
-				ID:   p.ID,
-				Name: p.Name,
-			})
+	byRole := make(map[uint][]dto.PermissionGrantDTO)
+	for _, g := range grants {
+		if g.Permission.ID != 0 {
+			byRole[g.RoleID] = append(byRole[g.RoleID], permissionGrantDTO(g))
 		}
-		result = append(result, roleDTO)
+	}
+
+	result := make([]dto.RoleWithPermissionsDTO, 0, len(roles))
+	for _, r := range roles {
+		result = append(result, dto.RoleWithPermissionsDTO{
+			ID:          r.ID,
+			Name:        r.Name,
+			Permissions: nonNil(byRole[r.ID]),
+		})
 	}
 
 	return c.JSON(result)
 }
 
 // GetPermissionsWithRoles godoc
-// @Summary Permission-ları və aid olduqları rolları qaytarır
+// @Summary Permission-ları və təyin olunduqları rolları qaytarır
+// @Description Hər təyinat effekti (allow/deny), resurs scope-u və şərti ilə göstərilir.
 // @Tags Permission
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
 // @Produce json
-// @Success 200 {array} model.Permission
+// @Success 200 {array} dto.PermissionWithRolesDTO
 // @Failure 500 {string} string "Server error"
 // @Router /api/v1/authz/permissions/permissions-with-roles [get]
 func (h *RBACAdminHandler) GetPermissionsWithRoles(c *fiber.Ctx) error {
-	perms, err := h.UoW.PermissionRepo().GetAllWithRoles()
+	perms, err := h.uow(c).PermissionRepo().GetAll()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	grants, err := h.uow(c).RolePermissionRepo().GetAll()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	roleNames, err := h.roleNames(c)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
-	var result []dto.PermissionWithRolesDTO
-	for _, p := range perms {
-		permDTO := dto.PermissionWithRolesDTO{
-			ID:   p.ID,
-			Name: p.Name,
+	byPermission := make(map[uint][]dto.RoleGrantDTO)
+	for _, g := range grants {
+		if _, ok := roleNames[g.RoleID]; ok {
+			byPermission[g.PermissionID] = append(byPermission[g.PermissionID], roleGrantDTO(g, roleNames))
 		}
-		for _, r := range p.Roles {
-			permDTO.Roles = append(permDTO.Roles, dto.RoleDTO{
-				ID:   r.ID,
-				Name: r.Name,
-			})
-		}
-		result = append(result, permDTO)
+	}
+
+	result := make([]dto.PermissionWithRolesDTO, 0, len(perms))
+	for _, p := range perms {
+		result = append(result, dto.PermissionWithRolesDTO{
+			ID:    p.ID,
+			Name:  p.Name,
+			Roles: nonNil(byPermission[p.ID]),
+		})
 	}
 
 	return c.JSON(result)
 }
 
 // GetPermissionsByRoleID godoc
-// @Summary Verilmiş role ID üçün permission-ları qaytarır
+// @Summary Verilmiş role ID üçün birbaşa təyin olunmuş permission-ları qaytarır
+// @Description Hər təyinat effekti (allow/deny), resurs scope-u və şərti ilə göstərilir; miras alınanlar üçün effective-permissions.
 // @Tags Role
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
 // @Param id path int true "Role ID"
 // @Produce json
-// @Success 200 {array} model.Permission
+// @Success 200 {array} dto.PermissionGrantDTO
 // @Failure 400 {string} string "Invalid ID"
 // @Failure 500 {string} string "Server error"
 // @Router /api/v1/authz/roles/{id}/permissions [get]
@@ -387,28 +833,29 @@ func (h *RBACAdminHandler) GetPermissionsByRoleID(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
 	}
 
-	perms, err := h.UoW.RoleRepo().GetPermissionsByRoleID(uint(id))
+	grants, err := h.uow(c).RolePermissionRepo().GetByRoleID(uint(id))
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
-	var result []dto.PermissionDTO
-	for _, p := range perms {
-		result = append(result, dto.PermissionDTO{
-			ID:   p.ID,
-			Name: p.Name,
-		})
+	result := make([]dto.PermissionGrantDTO, 0, len(grants))
+	for _, g := range grants {
+		if g.Permission.ID != 0 {
+			result = append(result, permissionGrantDTO(g))
+		}
 	}
 
 	return c.JSON(result)
 }
 
 // GetRolesByPermissionID godoc
-// @Summary Verilmiş permission ID üçün aid olduğu rolları qaytarır
+// @Summary Verilmiş permission ID üçün təyin olunduğu rolları qaytarır
+// @Description Hər təyinat effekti (allow/deny), resurs scope-u və şərti ilə göstərilir.
 // @Tags Permission
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
 // @Param id path int true "Permission ID"
 // @Produce json
-// @Success 200 {array} model.Role
+// @Success 200 {array} dto.RoleGrantDTO
 // @Failure 400 {string} string "Invalid ID"
 // @Failure 500 {string} string "Server error"
 // @Router /api/v1/authz/permissions/{id}/roles [get]
@@ -418,18 +865,58 @@ func (h *RBACAdminHandler) GetRolesByPermissionID(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
 	}
 
-	roles, err := h.UoW.PermissionRepo().GetRolesByPermissionID(uint(id))
+	grants, err := h.uow(c).RolePermissionRepo().GetByPermissionID(uint(id))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	roleNames, err := h.roleNames(c)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
-	var result []dto.RoleDTO
-	for _, r := range roles {
-		result = append(result, dto.RoleDTO{
-			ID:   r.ID,
-			Name: r.Name,
-		})
+	result := make([]dto.RoleGrantDTO, 0, len(grants))
+	for _, g := range grants {
+		if _, ok := roleNames[g.RoleID]; ok { // silinmiş rolların təyinatları göstərilmir
+			result = append(result, roleGrantDTO(g, roleNames))
+		}
 	}
 
 	return c.JSON(result)
 }
+
+// Sorğunun tenant-ındakı rolların adları ID-yə görə
+func (h *RBACAdminHandler) roleNames(c *fiber.Ctx) (map[uint]string, error) {
+	roles, err := h.uow(c).RoleRepo().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(roles))
+	for _, r := range roles {
+		names[r.ID] = r.Name
+	}
+	return names, nil
+}
+
+func grantDTO(g model.RolePermission) dto.GrantDTO {
+	return dto.GrantDTO{
+		Effect:          g.Effect,
+		ResourceType:    g.ResourceType,
+		ResourcePattern: g.ResourcePattern,
+		Condition:       g.Condition,
+	}
+}
+
+func permissionGrantDTO(g model.RolePermission) dto.PermissionGrantDTO {
+	return dto.PermissionGrantDTO{ID: g.Permission.ID, Name: g.Permission.Name, GrantDTO: grantDTO(g)}
+}
+
+func roleGrantDTO(g model.RolePermission, roleNames map[uint]string) dto.RoleGrantDTO {
+	return dto.RoleGrantDTO{ID: g.RoleID, Name: roleNames[g.RoleID], GrantDTO: grantDTO(g)}
+}
+
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
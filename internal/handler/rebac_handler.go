@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"ms-authz/internal/dto"
+	"ms-authz/internal/service"
+	"ms-authz/pkg/rebac"
+)
+
+type ReBACHandler struct {
+	ReBAC *service.ReBACService
+}
+
+func NewReBACHandler(rebacService *service.ReBACService) *ReBACHandler {
+	return &ReBACHandler{ReBAC: rebacService}
+}
+
+func (h *ReBACHandler) RegisterRoutes(app *fiber.App) {
+	app.Post("/api/v1/authz/relations/check", h.Check)
+	app.Get("/api/v1/authz/relations/expand", h.Expand)
+	app.Post("/api/v1/authz/relations/list-objects", h.ListObjects)
+
+	app.Get("/api/v1/authz/relations/tuples", h.GetTuples)
+	app.Post("/api/v1/authz/relations/tuples", h.WriteTuple)
+	app.Delete("/api/v1/authz/relations/tuples", h.DeleteTuple)
+}
+
+// Check godoc
+// @Summary Subject-in obyekt üzərində relation-a malik olduğunu yoxlayır
+// @Description Birbaşa tuple-lar, userset-lər (group:eng#member) və namespace konfiqurasiyasındakı rewrite-lar
+// @Description (computed_userset, tuple_to_userset) nəzərə alınır.
+// @Tags Relation
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Accept json
+// @Produce json
+// @Param check body dto.RelationTupleDTO true "Yoxlanılan tuple"
+// @Success 200 {object} dto.RelationCheckResponse
+// @Failure 400 {string} string "Invalid tuple or unknown namespace/relation"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/relations/check [post]
+func (h *ReBACHandler) Check(c *fiber.Ctx) error {
+	var req dto.RelationTupleDTO
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid body")
+	}
+	tuple, err := parseTuple(req)
+	if err != nil {
+		return relationError(err)
+	}
+
+	allowed, err := h.ReBAC.Check(tenantOf(c), tuple.Object, tuple.Relation, tuple.Subject)
+	if err != nil {
+		return relationError(err)
+	}
+	return c.JSON(dto.RelationCheckResponse{
+		Allowed:  allowed,
+		Object:   req.Object,
+		Relation: req.Relation,
+		Subject:  req.Subject,
+	})
+}
+
+// Expand godoc
+// @Summary object#relation-ın userset ağacını qaytarır
+// @Description Birbaşa tuple-lardakı userset-lər yarpaq kimi qalır, onları ayrıca expand etmək olar.
+// @Tags Relation
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Produce json
+// @Param object query string true "Obyekt, məs. document:123"
+// @Param relation query string true "Relation, məs. viewer"
+// @Success 200 {object} rebac.Node
+// @Failure 400 {string} string "Invalid object or unknown namespace/relation"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/relations/expand [get]
+func (h *ReBACHandler) Expand(c *fiber.Ctx) error {
+	object, err := rebac.ParseObject(c.Query("object"))
+	if err != nil {
+		return relationError(err)
+	}
+
+	tree, err := h.ReBAC.Expand(tenantOf(c), object, c.Query("relation"))
+	if err != nil {
+		return relationError(err)
+	}
+	return c.JSON(tree)
+}
+
+// ListObjects godoc
+// @Summary Namespace-də subject-in relation-a malik olduğu obyektləri qaytarır
+// @Tags Relation
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Accept json
+// @Produce json
+// @Param request body dto.ListObjectsRequest true "Namespace, relation və subject"
+// @Success 200 {object} dto.ListObjectsResponse
+// @Failure 400 {string} string "Invalid subject or unknown namespace/relation"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/relations/list-objects [post]
+func (h *ReBACHandler) ListObjects(c *fiber.Ctx) error {
+	var req dto.ListObjectsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid body")
+	}
+	subject, err := rebac.ParseSubject(req.Subject)
+	if err != nil {
+		return relationError(err)
+	}
+
+	objects, err := h.ReBAC.ListObjects(tenantOf(c), req.Namespace, req.Relation, subject)
+	if err != nil {
+		return relationError(err)
+	}
+	return c.JSON(dto.ListObjectsResponse{
+		Namespace: req.Namespace,
+		Relation:  req.Relation,
+		Subject:   req.Subject,
+		Objects:   objects,
+	})
+}
+
+// GetTuples godoc
+// @Summary Obyektin birbaşa yazılmış tuple-larını qaytarır
+// @Tags Relation
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Produce json
+// @Param object query string true "Obyekt, məs. folder:9"
+// @Param relation query string false "Relation (verilməzsə hamısı)"
+// @Success 200 {array} dto.RelationTupleDTO
+// @Failure 400 {string} string "Invalid object"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/relations/tuples [get]
+func (h *ReBACHandler) GetTuples(c *fiber.Ctx) error {
+	object, err := rebac.ParseObject(c.Query("object"))
+	if err != nil {
+		return relationError(err)
+	}
+
+	tuples, err := h.ReBAC.Tuples(tenantOf(c), object, c.Query("relation"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	result := make([]dto.RelationTupleDTO, 0, len(tuples))
+	for _, t := range tuples {
+		result = append(result, dto.RelationTupleDTO{
+			Object:   t.Object.String(),
+			Relation: t.Relation,
+			Subject:  t.Subject.String(),
+		})
+	}
+	return c.JSON(result)
+}
+
+// WriteTuple godoc
+// @Summary Relation tuple yazır
+// @Description Relation konfiqurasiyada birbaşa tuple qəbul etməlidir ("this"). Tuple artıq varsa heç nə dəyişmir.
+// @Tags Relation
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Accept json
+// @Param tuple body dto.RelationTupleDTO true "Yeni tuple"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {string} string "Invalid tuple or unknown namespace/relation"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/relations/tuples [post]
+func (h *ReBACHandler) WriteTuple(c *fiber.Ctx) error {
+	var req dto.RelationTupleDTO
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid body")
+	}
+	tuple, err := parseTuple(req)
+	if err != nil {
+		return relationError(err)
+	}
+
+	if err := h.ReBAC.WriteTuple(tenantOf(c), tuple); err != nil {
+		return relationError(err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// DeleteTuple godoc
+// @Summary Relation tuple silir
+// @Tags Relation
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Accept json
+// @Param tuple body dto.RelationTupleDTO true "Silinəcək tuple"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {string} string "Invalid tuple"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/relations/tuples [delete]
+func (h *ReBACHandler) DeleteTuple(c *fiber.Ctx) error {
+	var req dto.RelationTupleDTO
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid body")
+	}
+	tuple, err := parseTuple(req)
+	if err != nil {
+		return relationError(err)
+	}
+
+	if err := h.ReBAC.DeleteTuple(tenantOf(c), tuple); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func parseTuple(req dto.RelationTupleDTO) (rebac.Tuple, error) {
+	object, err := rebac.ParseObject(req.Object)
+	if err != nil {
+		return rebac.Tuple{}, err
+	}
+	subject, err := rebac.ParseSubject(req.Subject)
+	if err != nil {
+		return rebac.Tuple{}, err
+	}
+	if req.Relation == "" {
+		return rebac.Tuple{}, rebac.ErrInvalidTuple
+	}
+	return rebac.Tuple{Object: object, Relation: req.Relation, Subject: subject}, nil
+}
+
+func relationError(err error) error {
+	switch {
+	case errors.Is(err, rebac.ErrInvalidTuple),
+		errors.Is(err, rebac.ErrUnknownNamespace),
+		errors.Is(err, rebac.ErrUnknownRelation):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
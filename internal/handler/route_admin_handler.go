@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"ms-authz/internal/domain/model"
+	"ms-authz/internal/domain/repository"
+	"ms-authz/internal/dto"
+	"ms-authz/internal/service"
+	"ms-authz/pkg/permission"
+	"ms-authz/pkg/route"
+	"strconv"
+	"strings"
+)
+
+// CreateRoute godoc
+// @Summary Servis route-una tələb olunan permission-ları təyin edir
+// @Description path pkg/route sintaksisindədir: "*" bir seqment, sonuncu "**" qalan bütün seqmentlər (məs. /api/users/*).
+// @Description permissions tenant-ın kataloqundakı konkret (wildcard-sız) permission-lardır və hamısı tələb olunur;
+// @Description boşdursa etibarlı token kifayətdir, public route isə token tələb etmir.
+// @Description Bir neçə route uyğun gələrsə priority-si böyük olan, bərabər olduqda əvvəl yaradılan qalib gəlir.
+// @Tags Route
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Accept json
+// @Produce json
+// @Param route body dto.RouteDTO true "Yeni route"
+// @Success 200 {object} dto.RouteDTO
+// @Failure 400 {string} string "Invalid route or unknown permission"
+// @Failure 409 {string} string "Route already exists"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/routes [post]
+func (h *RBACAdminHandler) CreateRoute(c *fiber.Ctx) error {
+	var req dto.RouteDTO
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid body")
+	}
+	r := model.Route{}
+	if err := h.applyRoute(c, &r, req); err != nil {
+		return err
+	}
+
+	err := h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.RouteRepo().Create(&r); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACRouteCreated, map[string]any{
+			"route_id": r.ID,
+			"service":  r.Service,
+			"method":   r.Method,
+			"path":     r.Path,
+		})
+	})
+	if err != nil {
+		return routeError(err)
+	}
+
+	return c.JSON(routeDTO(r))
+}
+
+// GetRoutes godoc
+// @Summary Route reyestrini uyğunlaşma sırası ilə qaytarır
+// @Tags Route
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Param service query string false "Yalnız bu servisin route-ları"
+// @Produce json
+// @Success 200 {array} dto.RouteDTO
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/routes [get]
+func (h *RBACAdminHandler) GetRoutes(c *fiber.Ctx) error {
+	var routes []model.Route
+	var err error
+	if svc, ok := c.Queries()["service"]; ok {
+		routes, err = h.uow(c).RouteRepo().GetByService(strings.TrimSpace(svc))
+	} else {
+		routes, err = h.uow(c).RouteRepo().GetAll()
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	result := make([]dto.RouteDTO, 0, len(routes))
+	for _, r := range routes {
+		result = append(result, routeDTO(r))
+	}
+	return c.JSON(result)
+}
+
+// GetRoute godoc
+// @Summary Route-u ID ilə qaytarır
+// @Tags Route
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Param id path int true "Route ID"
+// @Produce json
+// @Success 200 {object} dto.RouteDTO
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Route not found"
+// @Router /api/v1/authz/routes/{id} [get]
+func (h *RBACAdminHandler) GetRoute(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+
+	r, err := h.uow(c).RouteRepo().GetByID(uint(id))
+	if err != nil {
+		return routeError(err)
+	}
+	return c.JSON(routeDTO(*r))
+}
+
+// UpdateRoute godoc
+// @Summary Mövcud route-u yeniləyir
+// @Tags Route
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Accept json
+// @Produce json
+// @Param id path int true "Route ID"
+// @Param route body dto.RouteDTO true "Yenilənmiş route"
+// @Success 200 {object} dto.RouteDTO
+// @Failure 400 {string} string "Invalid route or unknown permission"
+// @Failure 404 {string} string "Route not found"
+// @Failure 409 {string} string "Route already exists"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/routes/{id} [put]
+func (h *RBACAdminHandler) UpdateRoute(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+
+	var req dto.RouteDTO
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid body")
+	}
+
+	r, err := h.uow(c).RouteRepo().GetByID(uint(id))
+	if err != nil {
+		return routeError(err)
+	}
+	if err := h.applyRoute(c, r, req); err != nil {
+		return err
+	}
+
+	err = h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.RouteRepo().Update(r); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACRouteUpdated, map[string]any{
+			"route_id": r.ID,
+			"service":  r.Service,
+			"method":   r.Method,
+			"path":     r.Path,
+		})
+	})
+	if err != nil {
+		return routeError(err)
+	}
+
+	return c.JSON(routeDTO(*r))
+}
+
+// DeleteRoute godoc
+// @Summary Route-u ID ilə silir
+// @Description Silinmiş route-a uyğun gələn sorğular (başqa route uyğun gəlmirsə) /check-də rədd edilir.
+// @Tags Route
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Param id path int true "Route ID"
+// @Success 204 {string} string "No Content"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/routes/{id} [delete]
+func (h *RBACAdminHandler) DeleteRoute(c *fiber.Ctx) error {
+	id, _ := strconv.Atoi(c.Params("id"))
+	err := h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.RouteRepo().Delete(uint(id)); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACRouteDeleted, map[string]any{
+			"route_id": id,
+		})
+	})
+	if err != nil {
+		return routeError(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// applyRoute sorğunu yoxlayır və route-a köçürür. Permission-lar sorğunun tenant-ının kataloqunda olmalıdır.
+func (h *RBACAdminHandler) applyRoute(c *fiber.Ctx, r *model.Route, req dto.RouteDTO) error {
+	method := strings.ToUpper(strings.TrimSpace(req.Method))
+	if method == "" {
+		method = route.AnyMethod
+	}
+	perms := make([]string, 0, len(req.Permissions))
+	for _, name := range req.Permissions {
+		name = strings.TrimSpace(name)
+		if err := permission.Validate(name); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		if strings.Contains(name, permission.Wildcard) {
+			return fiber.NewError(fiber.StatusBadRequest, "Route permissions must be concrete: "+name)
+		}
+		if _, err := h.uow(c).PermissionRepo().GetByName(name); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Unknown permission: "+name)
+		}
+		perms = append(perms, name)
+	}
+
+	r.Service = strings.TrimSpace(req.Service)
+	r.Method = method
+	r.Path = strings.TrimSpace(req.Path)
+	r.Permissions = perms
+	r.Public = req.Public
+	r.Priority = req.Priority
+	if err := route.Validate(service.RouteRule(*r)); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return nil
+}
+
+func routeDTO(r model.Route) dto.RouteDTO {
+	perms := r.Permissions
+	if perms == nil {
+		perms = []string{}
+	}
+	return dto.RouteDTO{
+		ID:          r.ID,
+		Service:     r.Service,
+		Method:      r.Method,
+		Path:        r.Path,
+		Permissions: perms,
+		Public:      r.Public,
+		Priority:    r.Priority,
+	}
+}
+
+func routeError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Route not found")
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return fiber.NewError(fiber.StatusConflict, "Route already exists")
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ms-authz/internal/domain/repository"
+	"strings"
+)
+
+// TenantHeader admin API sorğularının aid olduğu tenant. Verilməzsə default (boş) tenant istifadə olunur.
+const TenantHeader = "X-Tenant-ID"
+
+func tenantOf(c *fiber.Ctx) string {
+	return strings.TrimSpace(c.Get(TenantHeader))
+}
+
+// Sorğunun tenant-ına məhdudlaşdırılmış UnitOfWork
+func (h *RBACAdminHandler) uow(c *fiber.Ctx) repository.UnitOfWork {
+	return h.UoW.ForTenant(tenantOf(c))
+}
+
+func (h *UserHandler) uow(c *fiber.Ctx) repository.UnitOfWork {
+	return h.UoW.ForTenant(tenantOf(c))
+}
@@ -0,0 +1,333 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"ms-authz/internal/domain/model"
+	"ms-authz/internal/domain/repository"
+	"ms-authz/internal/dto"
+	"ms-authz/internal/service"
+	"strconv"
+	"strings"
+)
+
+type UserHandler struct {
+	UoW  repository.UnitOfWork
+	RBAC *service.RBACService
+}
+
+func NewUserHandler(uow repository.UnitOfWork, rbacService *service.RBACService) *UserHandler {
+	return &UserHandler{UoW: uow, RBAC: rbacService}
+}
+
+func (h *UserHandler) RegisterRoutes(app *fiber.App) {
+	app.Get("/api/v1/authz/users", h.GetUsers)
+	app.Post("/api/v1/authz/users", h.CreateUser)
+	app.Get("/api/v1/authz/users/:id", h.GetUser)
+	app.Put("/api/v1/authz/users/:id", h.UpdateUser)
+	app.Delete("/api/v1/authz/users/:id", h.DeleteUser)
+	app.Post("/api/v1/authz/users/:id/restore", h.RestoreUser)
+	app.Get("/api/v1/authz/users/:id/effective-permissions", h.GetUserEffectivePermissions)
+}
+
+// GetUsers godoc
+// @Summary İstifadəçiləri qaytarır, username və ya email verilərsə həmin istifadəçini axtarır
+// @Tags User
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Produce json
+// @Param username query string false "Username ilə axtarış"
+// @Param email query string false "Email ilə axtarış"
+// @Success 200 {array} dto.UserDTO
+// @Failure 404 {string} string "User not found"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/users [get]
+func (h *UserHandler) GetUsers(c *fiber.Ctx) error {
+	var lookup func() (*model.User, error)
+	if username := c.Query("username"); username != "" {
+		lookup = func() (*model.User, error) { return h.uow(c).UserRepo().GetByUsername(username) }
+	} else if email := c.Query("email"); email != "" {
+		lookup = func() (*model.User, error) { return h.uow(c).UserRepo().GetByEmail(email) }
+	}
+
+	if lookup != nil {
+		user, err := lookup()
+		if err != nil {
+			return userError(err)
+		}
+		return c.JSON([]dto.UserDTO{h.toDTO(c, user)})
+	}
+
+	users, err := h.uow(c).UserRepo().GetAllWithRoles()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	result := make([]dto.UserDTO, 0, len(users))
+	for i := range users {
+		result = append(result, userDTO(&users[i], users[i].Roles))
+	}
+	return c.JSON(result)
+}
+
+// GetUser godoc
+// @Summary İstifadəçini ID-yə görə qaytarır
+// @Tags User
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.UserDTO
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "User not found"
+// @Router /api/v1/authz/users/{id} [get]
+func (h *UserHandler) GetUser(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+
+	user, err := h.uow(c).UserRepo().GetByID(uint(id))
+	if err != nil {
+		return userError(err)
+	}
+	return c.JSON(h.toDTO(c, user))
+}
+
+// CreateUser godoc
+// @Summary Yeni istifadəçi yaradır
+// @Description Rollar /users/{id}/roles endpoint-ləri ilə təyin olunur, role_id köhnə tək rol sahəsidir.
+// @Tags User
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Accept json
+// @Produce json
+// @Param user body dto.UserRequest true "Yeni istifadəçi"
+// @Success 200 {object} dto.UserDTO
+// @Failure 400 {string} string "Invalid body or unknown role_id"
+// @Failure 409 {string} string "Username or email already exists"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/users [post]
+func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
+	var req dto.UserRequest
+	if err := c.BodyParser(&req); err != nil || strings.TrimSpace(req.Username) == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid body")
+	}
+
+	if err := h.checkRole(c, req.RoleID); err != nil {
+		return err
+	}
+
+	user := model.User{Username: req.Username, Email: req.Email, RoleID: req.RoleID}
+	err := h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.UserRepo().Create(&user); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACUserCreated, map[string]any{
+			"user_id":  user.ID,
+			"username": user.Username,
+		})
+	})
+	if err != nil {
+		return userError(err)
+	}
+
+	return c.JSON(userDTO(&user, nil))
+}
+
+// UpdateUser godoc
+// @Summary Mövcud istifadəçini yeniləyir
+// @Tags User
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param user body dto.UserRequest true "Yenilənmiş istifadəçi məlumatı"
+// @Success 200 {object} dto.UserDTO
+// @Failure 400 {string} string "Invalid input or unknown role_id"
+// @Failure 404 {string} string "User not found"
+// @Failure 409 {string} string "Username or email already exists"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/users/{id} [put]
+func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+
+	var req dto.UserRequest
+	if err := c.BodyParser(&req); err != nil || strings.TrimSpace(req.Username) == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid body")
+	}
+
+	if err := h.checkRole(c, req.RoleID); err != nil {
+		return err
+	}
+
+	user, err := h.uow(c).UserRepo().GetByID(uint(id))
+	if err != nil {
+		return userError(err)
+	}
+	user.Username = req.Username
+	user.Email = req.Email
+	user.RoleID = req.RoleID
+
+	err = h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.UserRepo().Update(user); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACUserUpdated, map[string]any{
+			"user_id": user.ID,
+		})
+	})
+	if err != nil {
+		return userError(err)
+	}
+
+	return c.JSON(h.toDTO(c, user))
+}
+
+// DeleteUser godoc
+// @Summary İstifadəçini silir (soft-delete, restore ilə bərpa oluna bilər)
+// @Tags User
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Param id path int true "User ID"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/users/{id} [delete]
+func (h *UserHandler) DeleteUser(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+
+	err = h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.UserRepo().Delete(uint(id)); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACUserDeleted, map[string]any{
+			"user_id": id,
+		})
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RestoreUser godoc
+// @Summary Silinmiş istifadəçini bərpa edir
+// @Tags User
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.UserDTO
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "User not found"
+// @Failure 500 {string} string "Server error"
+// @Router /api/v1/authz/users/{id}/restore [post]
+func (h *UserHandler) RestoreUser(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+
+	err = h.RBAC.Mutate(h.uow(c), func(tx repository.UnitOfWork) error {
+		if err := tx.UserRepo().Restore(uint(id)); err != nil {
+			return err
+		}
+		return h.RBAC.EnqueueCacheEvent(tx, dto.EventRBACUserRestored, map[string]any{
+			"user_id": id,
+		})
+	})
+	if err != nil {
+		return userError(err)
+	}
+
+	user, err := h.uow(c).UserRepo().GetByID(uint(id))
+	if err != nil {
+		return userError(err)
+	}
+	return c.JSON(h.toDTO(c, user))
+}
+
+// GetUserEffectivePermissions godoc
+// @Summary İstifadəçinin server-side rollarından (miras alınanlar daxil) toplanmış permission-ları qaytarır
+// @Description Token-dəki rollar burada nəzərə alınmır.
+// @Tags User
+// @Param X-Tenant-ID header string false "Tenant ID (verilməzsə default tenant)"
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.UserEffectivePermissionsDTO
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "User not found"
+// @Router /api/v1/authz/users/{id}/effective-permissions [get]
+func (h *UserHandler) GetUserEffectivePermissions(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid ID")
+	}
+
+	user, err := h.uow(c).UserRepo().GetByID(uint(id))
+	if err != nil {
+		return userError(err)
+	}
+
+	roles := h.RBAC.RolesForUser(tenantOf(c), strconv.Itoa(id), nil)
+	sets := h.RBAC.EffectivePermissions(tenantOf(c), roles...)
+	return c.JSON(dto.UserEffectivePermissionsDTO{
+		UserID: user.ID,
+		Roles:  append([]string{}, roles...),
+		Allow:  sets[0].Allow,
+		Deny:   sets[0].Deny,
+		Scoped: scopedPermissionsDTO(sets[1:]),
+	})
+}
+
+// Server-side rollarla birlikdə DTO
+func (h *UserHandler) toDTO(c *fiber.Ctx, user *model.User) dto.UserDTO {
+	roles, _ := h.uow(c).UserRepo().GetRoles(user.ID)
+	return userDTO(user, roles)
+}
+
+func userDTO(user *model.User, roles []model.Role) dto.UserDTO {
+	result := dto.UserDTO{
+		ID:       user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		RoleID:   user.RoleID,
+		Roles:    make([]dto.RoleDTO, 0, len(roles)),
+	}
+	for _, r := range roles {
+		result.Roles = append(result.Roles, dto.RoleDTO{
+			ID:   r.ID,
+			Name: r.Name,
+		})
+	}
+	return result
+}
+
+// checkRole role_id-nin (verilibsə) sorğunun tenant-ında mövcud olduğunu yoxlayır
+func (h *UserHandler) checkRole(c *fiber.Ctx, roleID uint) error {
+	if roleID == 0 {
+		return nil
+	}
+	if _, err := h.uow(c).RoleRepo().GetByID(roleID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusBadRequest, "Unknown role_id")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return nil
+}
+
+func userError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "User not found")
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return fiber.NewError(fiber.StatusConflict, "Username or email already exists")
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"ms-authz/internal/dto"
+	"ms-authz/internal/service"
+	"ms-authz/pkg/jwtutil"
+	"strings"
+	"time"
+)
+
+type WhoAmIResponse struct {
+	UserID      string                     `json:"user_id,omitempty"`
+	Role        string                     `json:"role,omitempty"`
+	Roles       []string                   `json:"roles"` // token + server-side rollar
+	TenantID    string                     `json:"tenant_id,omitempty"`
+	Permissions []string                   `json:"permissions"` // kataloqdakı konkret permission-lardan icazə veriləni (wildcard-lar açılmış, deny-lar çıxılmış)
+	Allow       []string                   `json:"allow"`       // irsiyyət daxil scope-suz allow pattern-ləri
+	Deny        []string                   `json:"deny"`
+	Scoped      []dto.ScopedPermissionsDTO `json:"scoped"` // resursa və ya şərtə bağlı təyinatlar
+	IssuedAt    *time.Time                 `json:"issued_at,omitempty"`
+	ExpiresAt   *time.Time                 `json:"expires_at,omitempty"`
+}
+
+// WhoAmI godoc
+// @Summary Tokenin effektiv icazələri
+// @Description Token Authorize ilə eyni qaydalarla doğrulanır; istifadəçi, rollar, effektiv permission-lar və tokenin bitmə vaxtı qaytarılır.
+// @Description permissions tenant-ın permission kataloqundakı konkret adlardır (wildcard-lar açılmış, deny-lar çıxılmış);
+// @Description şərtli təyinatlar müştərinin IP-si və cari vaxt üzərində qiymətləndirilir.
+// @Tags Authorization
+// @Produce json
+// @Param Authorization header string true "Bearer {token}"
+// @Param check_jwt query bool false "JWT yoxlanılsın?" default(true)
+// @Param check_blacklist query bool false "Blacklist yoxlanılsın?" default(true)
+// @Success 200 {object} WhoAmIResponse
+// @Failure 401 {object} AuthzCheckResponse "Unauthorized"
+// @Router /api/v1/authz/whoami [get]
+func (h *AuthorizeHandler) WhoAmI(c *fiber.Ctx) error {
+	authHeader := c.Get("Authorization")
+	if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		return fiber.NewError(fiber.StatusUnauthorized, "Missing or invalid Authorization header")
+	}
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+
+	checkJWT := c.QueryBool("check_jwt", true)
+	checkBlacklist := c.QueryBool("check_blacklist", true)
+	claims, err := h.Auth.Validate(token, checkJWT, checkBlacklist)
+	if err != nil {
+		reason := service.TokenErrorReasonOf(err)
+		return c.Status(fiber.StatusUnauthorized).JSON(AuthzCheckResponse{
+			Status:           false,
+			Error:            err.Error(),
+			Reason:           string(reason),
+			Blacklisted:      reason == service.ReasonBlacklisted || reason == service.ReasonRevoked,
+			JWTValidated:     checkJWT,
+			BlacklistChecked: checkBlacklist,
+		})
+	}
+	if claims.UserID != "" && claims.ExpiresAt != nil {
+		h.Auth.AddTokenForTracking(jwtutil.TokenID(claims, token), claims.ExpiresAt.Unix(), claims.UserID, claims.Role)
+	}
+
+	roles := h.RBAC.RolesForUser(claims.TenantID, claims.UserID, claims.AllRoles())
+	sets := h.RBAC.EffectivePermissions(claims.TenantID, roles...)
+	resp := WhoAmIResponse{
+		UserID:      claims.UserID,
+		Role:        claims.Role,
+		Roles:       roles,
+		TenantID:    claims.TenantID,
+		Permissions: h.RBAC.GrantedPermissions(claims.TenantID, roles, conditionEnv(c, claims, nil)),
+		Allow:       sets[0].Allow,
+		Deny:        sets[0].Deny,
+		Scoped:      scopedPermissionsDTO(sets[1:]),
+	}
+	if resp.Roles == nil {
+		resp.Roles = []string{}
+	}
+	if claims.IssuedAt != nil {
+		resp.IssuedAt = &claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		resp.ExpiresAt = &claims.ExpiresAt.Time
+	}
+	return c.JSON(resp)
+}
@@ -5,7 +5,12 @@ import (
 "time"
 )
 
-func StartTokenCleanupService(repo *TokenRepo, interval time.Duration) {
+// TokenRepo və ya onun üzərində qurulmuş persistent repository
+type expiringRepo interface {
+	CleanupExpired()
+}
+
+func StartTokenCleanupService(repo expiringRepo, interval time.Duration) {
 	go func() {
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
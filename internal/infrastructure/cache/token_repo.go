@@ -6,21 +6,23 @@ import (
 )
 
 type TokenInfo struct {
-	Token string // JWT özü
-	Exp   int64  // Expiration timestamp
+	JTI string // jti claim-i və ya tokenin hash-i (jwtutil.TokenID)
+	Exp int64  // Expiration timestamp
 }
 
 type TokenRepo struct {
-	blacklist  sync.Map // map[jti]exp_timestamp
-	userTokens sync.Map
+	blacklist   sync.Map // map[jti]exp_timestamp
+	userTokens  sync.Map
+	revocations sync.Map // map[userID]revoked_before_timestamp
+	revokeMu    sync.Mutex
 }
 
 func NewTokenRepository() *TokenRepo {
 	return &TokenRepo{}
 }
 
-func (r *TokenRepo) IsBlacklisted(token string) bool {
-	value, ok := r.blacklist.Load(token)
+func (r *TokenRepo) IsBlacklisted(jti string) bool {
+	value, ok := r.blacklist.Load(jti)
 	if !ok {
 		return false
 	}
@@ -28,23 +30,28 @@ func (r *TokenRepo) IsBlacklisted(token string) bool {
 	return time.Now().Unix() < exp
 }
 
-func (r *TokenRepo) Add(token string, exp int64) {
-	r.blacklist.Store(token, exp)
+func (r *TokenRepo) Add(jti string, exp int64) {
+	r.blacklist.Store(jti, exp)
 }
 
-func (r *TokenRepo) AddWithUser(token string, exp int64, userID string, role string) {
+// AddCached yalnız yaddaşa yazır – qeyd DB-yə artıq yazıldıqda istifadə olunur (PersistentTokenRepo bunu dəyişmir)
+func (r *TokenRepo) AddCached(jti string, exp int64) {
+	r.blacklist.Store(jti, exp)
+}
+
+func (r *TokenRepo) AddWithUser(jti string, exp int64, userID string, role string) {
 	val, _ := r.userTokens.LoadOrStore(userID, []TokenInfo{})
 	tokenList := val.([]TokenInfo)
 
 	for _, t := range tokenList {
-		if t.Token == token {
+		if t.JTI == jti {
 			return // artıq var
 		}
 	}
 
 	tokenList = append(tokenList, TokenInfo{
-		Token: token,
-		Exp:   exp,
+		JTI: jti,
+		Exp: exp,
 	})
 	r.userTokens.Store(userID, tokenList)
 }
@@ -65,6 +72,31 @@ func (r *TokenRepo) GetAllTokensByUser(userID string) []TokenInfo {
 	return val.([]TokenInfo)
 }
 
+// Cutoff yalnız irəli çəkilir – gecikmiş event yeni cutoff-u ləğv edə bilməz
+func (r *TokenRepo) SetRevokedBefore(tenantID, userID string, revokedBefore int64) {
+	r.revokeMu.Lock()
+	defer r.revokeMu.Unlock()
+
+	key := revocationKey(tenantID, userID)
+	if current, ok := r.revocations.Load(key); ok && current.(int64) >= revokedBefore {
+		return
+	}
+	r.revocations.Store(key, revokedBefore)
+}
+
+func (r *TokenRepo) RevokedBefore(tenantID, userID string) (int64, bool) {
+	val, ok := r.revocations.Load(revocationKey(tenantID, userID))
+	if !ok {
+		return 0, false
+	}
+	return val.(int64), true
+}
+
+// Cutoff tenant daxilindədir, eyni user_id başqa tenant-da başqa istifadəçidir
+func revocationKey(tenantID, userID string) string {
+	return tenantID + "|" + userID
+}
+
 func (r *TokenRepo) CleanupExpired() {
 	now := time.Now().Unix()
 
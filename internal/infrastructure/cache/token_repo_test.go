@@ -2,125 +2,110 @@ package cache
 
 import (
 	"reflect"
-	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewTokenRepository(t *testing.T) {
-	tests := []struct {
-		name string
-		want *TokenRepo
-	}{
-		// TODO: Add test cases.
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := NewTokenRepository(); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("NewTokenRepository() = %v, want %v", got, tt.want)
-			}
-		})
+	if got := NewTokenRepository(); got == nil {
+		t.Errorf("NewTokenRepository() = nil")
 	}
 }
 
 func TestTokenRepo_Add(t *testing.T) {
-	type fields struct {
-		blacklist  sync.Map
-		userTokens sync.Map
-	}
 	type args struct {
 		jti string
 		exp int64
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
+		name string
+		args args
 	}{
-		// TODO: Add test cases.
+		{"jti claim", args{"a1b2c3", time.Now().Add(time.Hour).Unix()}},
+		{"token hash", args{"sha256:deadbeef", time.Now().Add(time.Hour).Unix()}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r := &TokenRepo{
-				blacklist:  tt.fields.blacklist,
-				userTokens: tt.fields.userTokens,
-			}
+			r := NewTokenRepository()
 			r.Add(tt.args.jti, tt.args.exp)
+			if !r.IsBlacklisted(tt.args.jti) {
+				t.Errorf("IsBlacklisted(%q) = false after Add", tt.args.jti)
+			}
 		})
 	}
 }
 
 func TestTokenRepo_AddWithUser(t *testing.T) {
-	type fields struct {
-		blacklist  sync.Map
-		userTokens sync.Map
-	}
 	type args struct {
 		jti    string
 		exp    int64
 		userID string
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
+		name string
+		args []args
+		want []TokenInfo
 	}{
-		// TODO: Add test cases.
+		{
+			name: "tracks each jti once",
+			args: []args{{"j1", 100, "u1"}, {"j1", 100, "u1"}, {"j2", 200, "u1"}, {"j3", 300, "u2"}},
+			want: []TokenInfo{{JTI: "j1", Exp: 100}, {JTI: "j2", Exp: 200}},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r := &TokenRepo{
-				blacklist:  tt.fields.blacklist,
-				userTokens: tt.fields.userTokens,
+			r := NewTokenRepository()
+			for _, a := range tt.args {
+				r.AddWithUser(a.jti, a.exp, a.userID, "admin")
+			}
+			if got := r.GetAllTokensByUser("u1"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetAllTokensByUser() = %v, want %v", got, tt.want)
 			}
-			r.AddWithUser(tt.args.jti, tt.args.exp, tt.args.userID)
 		})
 	}
 }
 
 func TestTokenRepo_CleanupExpired(t *testing.T) {
-	type fields struct {
-		blacklist  sync.Map
-		userTokens sync.Map
+	now := time.Now().Unix()
+	r := NewTokenRepository()
+	r.Add("expired", now-10)
+	r.Add("active", now+3600)
+	r.AddWithUser("expired", now-10, "u1", "admin")
+	r.AddWithUser("active", now+3600, "u1", "admin")
+	r.AddWithUser("expired", now-10, "u2", "admin")
+
+	r.CleanupExpired()
+
+	if _, ok := r.blacklist.Load("expired"); ok {
+		t.Errorf("expired jti was not removed from blacklist")
 	}
-	tests := []struct {
-		name   string
-		fields fields
-	}{
-		// TODO: Add test cases.
+	if !r.IsBlacklisted("active") {
+		t.Errorf("active jti was removed from blacklist")
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			r := &TokenRepo{
-				blacklist:  tt.fields.blacklist,
-				userTokens: tt.fields.userTokens,
-			}
-			r.CleanupExpired()
-		})
+	if got, want := r.GetAllTokensByUser("u1"), []TokenInfo{{JTI: "active", Exp: now + 3600}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAllTokensByUser(u1) = %v, want %v", got, want)
+	}
+	if got := r.GetAllTokensByUser("u2"); got != nil {
+		t.Errorf("GetAllTokensByUser(u2) = %v, want nil", got)
 	}
 }
 
 func TestTokenRepo_GetAllJTIsByUser(t *testing.T) {
-	type fields struct {
-		blacklist  sync.Map
-		userTokens sync.Map
-	}
 	type args struct {
 		userID string
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   []TokenInfo
+		name string
+		args args
+		want []TokenInfo
 	}{
-		// TODO: Add test cases.
+		{"known user", args{"u1"}, []TokenInfo{{JTI: "j1", Exp: 100}}},
+		{"unknown user", args{"u404"}, nil},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r := &TokenRepo{
-				blacklist:  tt.fields.blacklist,
-				userTokens: tt.fields.userTokens,
-			}
+			r := NewTokenRepository()
+			r.AddWithUser("j1", 100, "u1", "admin")
 			if got := r.GetAllJTIsByUser(tt.args.userID); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("GetAllJTIsByUser() = %v, want %v", got, tt.want)
 			}
@@ -129,30 +114,27 @@ func TestTokenRepo_GetAllJTIsByUser(t *testing.T) {
 }
 
 func TestTokenRepo_IsBlacklisted(t *testing.T) {
-	type fields struct {
-		blacklist  sync.Map
-		userTokens sync.Map
-	}
+	now := time.Now().Unix()
 	type args struct {
 		jti string
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   bool
+		name string
+		args args
+		want bool
 	}{
-		// TODO: Add test cases.
+		{"blacklisted", args{"active"}, true},
+		{"expired entry", args{"expired"}, false},
+		{"unknown", args{"unknown"}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r := &TokenRepo{
-				blacklist:  tt.fields.blacklist,
-				userTokens: tt.fields.userTokens,
-			}
+			r := NewTokenRepository()
+			r.Add("active", now+3600)
+			r.Add("expired", now-10)
 			if got := r.IsBlacklisted(tt.args.jti); got != tt.want {
 				t.Errorf("IsBlacklisted() = %v, want %v", got, tt.want)
 			}
 		})
 	}
-}
\ No newline at end of file
+}
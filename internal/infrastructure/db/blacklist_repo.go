@@ -0,0 +1,36 @@
+package db
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"ms-authz/internal/domain/model"
+	"time"
+)
+
+type BlacklistRepo struct {
+	db *gorm.DB
+}
+
+func NewBlacklistRepository(db *gorm.DB) *BlacklistRepo {
+	return &BlacklistRepo{db: db}
+}
+
+// Eyni jti bir neçə instansiyadan gələ bilər – təkrar yazılış sadəcə ignore olunur
+func (r *BlacklistRepo) Add(jti string, expiresAt time.Time) error {
+	token := model.BlacklistedToken{JTI: jti, ExpiresAt: expiresAt}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "jti"}},
+		DoNothing: true,
+	}).Create(&token).Error
+}
+
+func (r *BlacklistRepo) GetActive(now time.Time) ([]model.BlacklistedToken, error) {
+	var tokens []model.BlacklistedToken
+	err := r.db.Where("expires_at > ?", now).Find(&tokens).Error
+	return tokens, err
+}
+
+// Vaxtı keçmiş qeydlər fiziki silinir, soft-delete cədvəli şişirdərdi
+func (r *BlacklistRepo) DeleteExpired(now time.Time) error {
+	return r.db.Unscoped().Where("expires_at <= ?", now).Delete(&model.BlacklistedToken{}).Error
+}
@@ -13,6 +13,12 @@ type GormUnitOfWork struct {
 	permissionRepo     repository.PermissionRepository
 	rolePermissionRepo repository.RolePermissionRepository
 	userRepo           repository.UserRepository
+	revocationRepo     repository.RevocationRepository
+	blacklistRepo      repository.BlacklistRepository
+	outboxRepo         repository.OutboxRepository
+	relationTupleRepo  repository.RelationTupleRepository
+	routeRepo          repository.RouteRepository
+	tenant             tenantScope // rol, permission və istifadəçi repository-lərinin tenant filtri
 }
 
 func NewUnitOfWork(db *gorm.DB) repository.UnitOfWork {
@@ -52,10 +58,26 @@ func (u *GormUnitOfWork) Rollback() error {
 	return err
 }
 
+// Transaction fn-ə tranzaksiyaya bağlı yeni UnitOfWork verir.
+// Paylaşılan UnitOfWork-un vəziyyəti dəyişmir, ona görə paralel sorğular üçün təhlükəsizdir.
+func (u *GormUnitOfWork) Transaction(fn func(tx repository.UnitOfWork) error) error {
+	return u.getDB().Transaction(func(tx *gorm.DB) error {
+		return fn(&GormUnitOfWork{db: tx, tx: tx, tenant: u.tenant})
+	})
+}
+
+// ForTenant rol, permission, istifadəçi, relation tuple və route repository-lərini tenantID-yə məhdudlaşdıran UnitOfWork qaytarır.
+// Aktiv tranzaksiya (varsa) paylaşılır.
+func (u *GormUnitOfWork) ForTenant(tenantID string) repository.UnitOfWork {
+	return &GormUnitOfWork{db: u.db, tx: u.tx, tenant: tenantScope{id: tenantID, scoped: true}}
+}
+
 // RoleRepo getter
 func (u *GormUnitOfWork) RoleRepo() repository.RoleRepository {
 	if u.roleRepo == nil {
-		u.roleRepo = NewRoleRepository(u.getDB())
+		repo := NewRoleRepository(u.getDB())
+		repo.tenant = u.tenant
+		u.roleRepo = repo
 	}
 	return u.roleRepo
 }
@@ -63,7 +85,9 @@ func (u *GormUnitOfWork) RoleRepo() repository.RoleRepository {
 // PermissionRepo getter
 func (u *GormUnitOfWork) PermissionRepo() repository.PermissionRepository {
 	if u.permissionRepo == nil {
-		u.permissionRepo = NewPermissionRepository(u.getDB())
+		repo := NewPermissionRepository(u.getDB())
+		repo.tenant = u.tenant
+		u.permissionRepo = repo
 	}
 	return u.permissionRepo
 }
@@ -71,7 +95,9 @@ func (u *GormUnitOfWork) PermissionRepo() repository.PermissionRepository {
 // RolePermissionRepo getter
 func (u *GormUnitOfWork) RolePermissionRepo() repository.RolePermissionRepository {
 	if u.rolePermissionRepo == nil {
-		u.rolePermissionRepo = NewRolePermissionRepository(u.getDB())
+		repo := NewRolePermissionRepository(u.getDB())
+		repo.tenant = u.tenant
+		u.rolePermissionRepo = repo
 	}
 	return u.rolePermissionRepo
 }
@@ -79,11 +105,57 @@ func (u *GormUnitOfWork) RolePermissionRepo() repository.RolePermissionRepositor
 // UserRepo getter
 func (u *GormUnitOfWork) UserRepo() repository.UserRepository {
 	if u.userRepo == nil {
-		u.userRepo = NewUserRepository(u.getDB())
+		repo := NewUserRepository(u.getDB())
+		repo.tenant = u.tenant
+		u.userRepo = repo
 	}
 	return u.userRepo
 }
 
+// RevocationRepo getter
+func (u *GormUnitOfWork) RevocationRepo() repository.RevocationRepository {
+	if u.revocationRepo == nil {
+		u.revocationRepo = NewRevocationRepository(u.getDB())
+	}
+	return u.revocationRepo
+}
+
+// BlacklistRepo getter
+func (u *GormUnitOfWork) BlacklistRepo() repository.BlacklistRepository {
+	if u.blacklistRepo == nil {
+		u.blacklistRepo = NewBlacklistRepository(u.getDB())
+	}
+	return u.blacklistRepo
+}
+
+// OutboxRepo getter
+func (u *GormUnitOfWork) OutboxRepo() repository.OutboxRepository {
+	if u.outboxRepo == nil {
+		u.outboxRepo = NewOutboxRepository(u.getDB())
+	}
+	return u.outboxRepo
+}
+
+// RelationTupleRepo getter
+func (u *GormUnitOfWork) RelationTupleRepo() repository.RelationTupleRepository {
+	if u.relationTupleRepo == nil {
+		repo := NewRelationTupleRepository(u.getDB())
+		repo.tenant = u.tenant
+		u.relationTupleRepo = repo
+	}
+	return u.relationTupleRepo
+}
+
+// RouteRepo getter
+func (u *GormUnitOfWork) RouteRepo() repository.RouteRepository {
+	if u.routeRepo == nil {
+		repo := NewRouteRepository(u.getDB())
+		repo.tenant = u.tenant
+		u.routeRepo = repo
+	}
+	return u.routeRepo
+}
+
 // Internal helper for choosing correct DB (with or without transaction)
 func (u *GormUnitOfWork) getDB() *gorm.DB {
 	if u.tx != nil {
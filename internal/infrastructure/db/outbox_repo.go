@@ -0,0 +1,88 @@
+package db
+
+import (
+	"encoding/json"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"ms-authz/internal/domain/model"
+	"strings"
+	"time"
+)
+
+type OutboxRepo struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepo {
+	return &OutboxRepo{db: db}
+}
+
+func (r *OutboxRepo) Add(exchange string, payload any, passiveQueues []string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	event := model.OutboxEvent{
+		Exchange:      exchange,
+		Payload:       string(body),
+		PassiveQueues: strings.Join(passiveQueues, ","),
+	}
+	return r.db.Create(&event).Error
+}
+
+func (r *OutboxRepo) Claim(limit int, now, lockedUntil time.Time) ([]model.OutboxEvent, error) {
+	var events []model.OutboxEvent
+	err := r.db.
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("sent_at IS NULL AND parked_at IS NULL").
+		Where("locked_until IS NULL OR locked_until < ?", now).
+		Order("id").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil || len(events) == 0 {
+		return events, err
+	}
+
+	ids := make([]uint, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	err = r.db.Model(&model.OutboxEvent{}).Where("id IN ?", ids).Update("locked_until", lockedUntil).Error
+	return events, err
+}
+
+func (r *OutboxRepo) Release(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&model.OutboxEvent{}).Where("id IN ?", ids).Update("locked_until", nil).Error
+}
+
+func (r *OutboxRepo) MarkSent(id uint, sentAt time.Time) error {
+	return r.db.Model(&model.OutboxEvent{}).Where("id = ?", id).Updates(map[string]any{
+		"sent_at":      sentAt,
+		"last_error":   "",
+		"locked_until": nil,
+	}).Error
+}
+
+func (r *OutboxRepo) MarkFailed(id uint, cause error) error {
+	return r.db.Model(&model.OutboxEvent{}).Where("id = ?", id).Updates(map[string]any{
+		"attempts":     gorm.Expr("attempts + 1"),
+		"last_error":   cause.Error(),
+		"locked_until": nil,
+	}).Error
+}
+
+func (r *OutboxRepo) Park(id uint, parkedAt time.Time, cause error) error {
+	return r.db.Model(&model.OutboxEvent{}).Where("id = ?", id).Updates(map[string]any{
+		"attempts":     gorm.Expr("attempts + 1"),
+		"last_error":   cause.Error(),
+		"parked_at":    parkedAt,
+		"locked_until": nil,
+	}).Error
+}
+
+func (r *OutboxRepo) DeleteSentBefore(before time.Time) error {
+	return r.db.Unscoped().Where("sent_at IS NOT NULL AND sent_at < ?", before).Delete(&model.OutboxEvent{}).Error
+}
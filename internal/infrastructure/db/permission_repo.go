@@ -2,11 +2,13 @@ package db
 
 import (
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"ms-authz/internal/domain/model"
 )
 
 type PermissionRepo struct {
-	db *gorm.DB
+	db     *gorm.DB
+	tenant tenantScope
 }
 
 func NewPermissionRepository(db *gorm.DB) *PermissionRepo {
@@ -15,42 +17,45 @@ func NewPermissionRepository(db *gorm.DB) *PermissionRepo {
 
 func (r *PermissionRepo) GetByID(id uint) (*model.Permission, error) {
 	var p model.Permission
-	err := r.db.First(&p, id).Error
+	err := r.db.Scopes(r.tenant.query).First(&p, id).Error
 	return &p, err
 }
 
 func (r *PermissionRepo) GetByName(name string) (*model.Permission, error) {
 	var p model.Permission
-	err := r.db.Where("name = ?", name).First(&p).Error
+	err := r.db.Scopes(r.tenant.query).Where("name = ?", name).First(&p).Error
 	return &p, err
 }
 
 func (r *PermissionRepo) GetAll() ([]model.Permission, error) {
 	var permissions []model.Permission
-	err := r.db.Find(&permissions).Error
+	err := r.db.Scopes(r.tenant.query).Find(&permissions).Error
 	return permissions, err
 }
 
+// Create və Update rol assosiasiyalarını yazmır, təyinatlar RolePermissionRepo ilə edilir
 func (r *PermissionRepo) Create(p *model.Permission) error {
-	return r.db.Create(p).Error
+	r.tenant.assign(&p.TenantID)
+	return r.db.Omit(clause.Associations).Create(p).Error
 }
 
 func (r *PermissionRepo) Update(role *model.Permission) error {
-	return r.db.Save(role).Error
+	r.tenant.assign(&role.TenantID)
+	return r.db.Scopes(r.tenant.query).Omit(clause.Associations).Save(role).Error
 }
 
 func (r *PermissionRepo) Delete(id uint) error {
-	return r.db.Delete(&model.Permission{}, id).Error
+	return r.db.Scopes(r.tenant.query).Delete(&model.Permission{}, id).Error
 }
 
 func (r *PermissionRepo) GetAllWithRoles() ([]model.Permission, error) {
 	var perms []model.Permission
-	err := r.db.Preload("Roles").Find(&perms).Error
+	err := r.db.Scopes(r.tenant.query).Preload("Roles").Find(&perms).Error
 	return perms, err
 }
 
 func (r *PermissionRepo) GetRolesByPermissionID(id uint) ([]model.Role, error) {
 	var perm model.Permission
-	err := r.db.Preload("Roles").First(&perm, id).Error
+	err := r.db.Scopes(r.tenant.query).Preload("Roles").First(&perm, id).Error
 	return perm.Roles, err
 }
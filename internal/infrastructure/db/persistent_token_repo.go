@@ -0,0 +1,49 @@
+package db
+
+import (
+	"log"
+	"ms-authz/internal/domain/repository"
+	"ms-authz/internal/infrastructure/cache"
+	"time"
+)
+
+// PersistentTokenRepo blacklist-i Postgres-də saxlayır. Bütün oxumalar yaddaşdakı
+// cache.TokenRepo-dan gedir (hot path), yazılar isə həm yaddaşa, həm DB-yə düşür.
+// Startup-da Load() ilə aktiv qeydlər yaddaşa yüklənir, ona görə restart və ya
+// yeni pod ləğv olunmuş tokenləri unutmur.
+type PersistentTokenRepo struct {
+	*cache.TokenRepo
+	uow repository.UnitOfWork
+}
+
+func NewPersistentTokenRepository(uow repository.UnitOfWork, mem *cache.TokenRepo) *PersistentTokenRepo {
+	return &PersistentTokenRepo{TokenRepo: mem, uow: uow}
+}
+
+// Load DB-dəki aktiv blacklist qeydlərini yaddaşa yükləyir (warm-load)
+func (r *PersistentTokenRepo) Load() error {
+	tokens, err := r.uow.BlacklistRepo().GetActive(time.Now())
+	if err != nil {
+		return err
+	}
+	for _, t := range tokens {
+		r.TokenRepo.Add(t.JTI, t.ExpiresAt.Unix())
+	}
+	log.Printf("✅ %d blacklisted token(s) loaded", len(tokens))
+	return nil
+}
+
+func (r *PersistentTokenRepo) Add(jti string, exp int64) {
+	r.TokenRepo.Add(jti, exp)
+	if err := r.uow.BlacklistRepo().Add(jti, time.Unix(exp, 0)); err != nil {
+		// Yaddaşda artıq var – bu instansiya üçün token bloklanıb, yalnız restart-dan sonra itə bilər
+		log.Printf("❌ failed to persist blacklisted token: %v", err)
+	}
+}
+
+func (r *PersistentTokenRepo) CleanupExpired() {
+	r.TokenRepo.CleanupExpired()
+	if err := r.uow.BlacklistRepo().DeleteExpired(time.Now()); err != nil {
+		log.Printf("❌ failed to delete expired blacklist entries: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"ms-authz/internal/domain/model"
+	"ms-authz/internal/domain/repository"
+	"ms-authz/internal/infrastructure/cache"
+	"testing"
+	"time"
+)
+
+type memoryBlacklistRepo struct {
+	rows map[string]time.Time
+}
+
+func (r *memoryBlacklistRepo) Add(jti string, expiresAt time.Time) error {
+	if _, ok := r.rows[jti]; !ok {
+		r.rows[jti] = expiresAt
+	}
+	return nil
+}
+
+func (r *memoryBlacklistRepo) GetActive(now time.Time) ([]model.BlacklistedToken, error) {
+	var tokens []model.BlacklistedToken
+	for jti, exp := range r.rows {
+		if exp.After(now) {
+			tokens = append(tokens, model.BlacklistedToken{JTI: jti, ExpiresAt: exp})
+		}
+	}
+	return tokens, nil
+}
+
+func (r *memoryBlacklistRepo) DeleteExpired(now time.Time) error {
+	for jti, exp := range r.rows {
+		if !exp.After(now) {
+			delete(r.rows, jti)
+		}
+	}
+	return nil
+}
+
+type blacklistUoW struct {
+	repository.UnitOfWork
+	blacklist *memoryBlacklistRepo
+}
+
+func (u *blacklistUoW) BlacklistRepo() repository.BlacklistRepository { return u.blacklist }
+
+func TestPersistentTokenRepo_SurvivesRestart(t *testing.T) {
+	uow := &blacklistUoW{blacklist: &memoryBlacklistRepo{rows: map[string]time.Time{}}}
+	now := time.Now()
+
+	first := NewPersistentTokenRepository(uow, cache.NewTokenRepository())
+	first.Add("revoked", now.Add(time.Hour).Unix())
+	first.Add("expired", now.Add(-time.Minute).Unix())
+	if !first.IsBlacklisted("revoked") {
+		t.Fatal("IsBlacklisted() = false right after Add")
+	}
+
+	// Yeni pod: yaddaş boşdur, Load DB-dən bərpa edir
+	restarted := NewPersistentTokenRepository(uow, cache.NewTokenRepository())
+	if restarted.IsBlacklisted("revoked") {
+		t.Fatal("fresh in-memory repository should be empty before Load")
+	}
+	if err := restarted.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if !restarted.IsBlacklisted("revoked") {
+		t.Error("IsBlacklisted(revoked) = false after Load")
+	}
+
+	// AddCached DB-yə yazılmış qeydlər üçündür və store-a ikinci dəfə yazmır
+	restarted.AddCached("cached-only", now.Add(time.Hour).Unix())
+	if !restarted.IsBlacklisted("cached-only") {
+		t.Error("IsBlacklisted(cached-only) = false after AddCached")
+	}
+	if _, ok := uow.blacklist.rows["cached-only"]; ok {
+		t.Error("AddCached() wrote to the store")
+	}
+
+	restarted.CleanupExpired()
+	if _, ok := uow.blacklist.rows["expired"]; ok {
+		t.Error("CleanupExpired() did not delete expired rows from the store")
+	}
+	if _, ok := uow.blacklist.rows["revoked"]; !ok {
+		t.Error("CleanupExpired() deleted an active row")
+	}
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"ms-authz/internal/domain/model"
+)
+
+type RelationTupleRepo struct {
+	db     *gorm.DB
+	tenant tenantScope
+}
+
+func NewRelationTupleRepository(db *gorm.DB) *RelationTupleRepo {
+	return &RelationTupleRepo{db: db}
+}
+
+func (r *RelationTupleRepo) Read(namespace, objectID, relation string) ([]model.RelationTuple, error) {
+	var tuples []model.RelationTuple
+	q := r.db.Scopes(r.tenant.query).Where("namespace = ? AND object_id = ?", namespace, objectID)
+	if relation != "" {
+		q = q.Where("relation = ?", relation)
+	}
+	err := q.Order("id").Find(&tuples).Error
+	return tuples, err
+}
+
+func (r *RelationTupleRepo) ObjectIDs(namespace string) ([]string, error) {
+	var ids []string
+	err := r.db.Model(&model.RelationTuple{}).Scopes(r.tenant.query).
+		Where("namespace = ?", namespace).
+		Distinct().Pluck("object_id", &ids).Error
+	return ids, err
+}
+
+func (r *RelationTupleRepo) Write(tuple *model.RelationTuple) error {
+	r.tenant.assign(&tuple.TenantID)
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(tuple).Error
+}
+
+func (r *RelationTupleRepo) Delete(tuple *model.RelationTuple) error {
+	r.tenant.assign(&tuple.TenantID)
+	return r.db.
+		Where("tenant_id = ? AND namespace = ? AND object_id = ? AND relation = ?",
+			tuple.TenantID, tuple.Namespace, tuple.ObjectID, tuple.Relation).
+		Where("subject_namespace = ? AND subject_id = ? AND subject_relation = ?",
+			tuple.SubjectNamespace, tuple.SubjectID, tuple.SubjectRelation).
+		Delete(&model.RelationTuple{}).Error
+}
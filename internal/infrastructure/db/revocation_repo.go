@@ -0,0 +1,40 @@
+package db
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"ms-authz/internal/domain/model"
+	"time"
+)
+
+type RevocationRepo struct {
+	db *gorm.DB
+}
+
+func NewRevocationRepository(db *gorm.DB) *RevocationRepo {
+	return &RevocationRepo{db: db}
+}
+
+func (r *RevocationRepo) GetByUserID(tenantID, userID string) (*model.UserRevocation, error) {
+	var rev model.UserRevocation
+	err := r.db.Where("tenant_id = ? AND user_id = ?", tenantID, userID).First(&rev).Error
+	return &rev, err
+}
+
+func (r *RevocationRepo) GetAll() ([]model.UserRevocation, error) {
+	var revs []model.UserRevocation
+	err := r.db.Find(&revs).Error
+	return revs, err
+}
+
+// Mövcud cutoff yalnız irəli çəkilə bilər, geri qaytarılmır
+func (r *RevocationRepo) Upsert(tenantID, userID string, revokedBefore time.Time) error {
+	rev := model.UserRevocation{TenantID: tenantID, UserID: userID, RevokedBefore: revokedBefore}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "tenant_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "revoked_before"}, Value: gorm.Expr("GREATEST(user_revocations.revoked_before, EXCLUDED.revoked_before)")},
+			{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("EXCLUDED.updated_at")},
+		},
+	}).Create(&rev).Error
+}
@@ -2,11 +2,13 @@ package db
 
 import (
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"ms-authz/internal/domain/model"
 )
 
 type RolePermissionRepo struct {
-	db *gorm.DB
+	db     *gorm.DB
+	tenant tenantScope
 }
 
 func NewRolePermissionRepository(db *gorm.DB) *RolePermissionRepo {
@@ -15,17 +17,38 @@ func NewRolePermissionRepository(db *gorm.DB) *RolePermissionRepo {
 
 func (r *RolePermissionRepo) GetPermissionsByRoleID(roleID uint) ([]model.Permission, error) {
 	var role model.Role
-	err := r.db.Preload("Permissions").First(&role, roleID).Error
+	err := r.db.Scopes(r.tenant.query).Preload("Permissions").First(&role, roleID).Error
 	if err != nil {
 		return nil, err
 	}
 	return role.Permissions, nil
 }
 
-func (r *RolePermissionRepo) AddPermission(roleID, permissionID uint) error {
-	role := model.Role{Model: gorm.Model{ID: roleID}}
-	perm := model.Permission{Model: gorm.Model{ID: permissionID}}
-	return r.db.Model(&role).Association("Permissions").Append(&perm)
+func (r *RolePermissionRepo) GetAll() ([]model.RolePermission, error) {
+	var grants []model.RolePermission
+	err := r.db.Scopes(r.tenant.roles).Preload("Permission").Find(&grants).Error
+	return grants, err
+}
+
+func (r *RolePermissionRepo) GetByRoleID(roleID uint) ([]model.RolePermission, error) {
+	var grants []model.RolePermission
+	err := r.db.Scopes(r.tenant.roles).Preload("Permission").Where("role_id = ?", roleID).Order("id").Find(&grants).Error
+	return grants, err
+}
+
+func (r *RolePermissionRepo) GetByPermissionID(permissionID uint) ([]model.RolePermission, error) {
+	var grants []model.RolePermission
+	err := r.db.Scopes(r.tenant.roles).Preload("Permission").Where("permission_id = ?", permissionID).Order("id").Find(&grants).Error
+	return grants, err
+}
+
+func (r *RolePermissionRepo) AddPermission(grant *model.RolePermission) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "role_id"}, {Name: "permission_id"}, {Name: "resource_type"}, {Name: "resource_pattern"},
+		},
+		DoUpdates: clause.AssignmentColumns([]string{"effect", "condition", "updated_at"}),
+	}).Create(grant).Error
 }
 
 func (r *RolePermissionRepo) RemovePermission(roleID, permissionID uint) error {
@@ -38,3 +61,10 @@ func (r *RolePermissionRepo) ClearPermissions(roleID uint) error {
 	role := model.Role{Model: gorm.Model{ID: roleID}}
 	return r.db.Model(&role).Association("Permissions").Clear()
 }
+
+func (r *RolePermissionRepo) RemoveScopedPermission(roleID, permissionID uint, resourceType, resourcePattern string) error {
+	return r.db.Unscoped().
+		Where("role_id = ? AND permission_id = ? AND resource_type = ? AND resource_pattern = ?",
+			roleID, permissionID, resourceType, resourcePattern).
+		Delete(&model.RolePermission{}).Error
+}
@@ -2,11 +2,13 @@ package db
 
 import (
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"ms-authz/internal/domain/model"
 )
 
 type RoleRepo struct {
-	db *gorm.DB
+	db     *gorm.DB
+	tenant tenantScope
 }
 
 func NewRoleRepository(db *gorm.DB) *RoleRepo {
@@ -15,42 +17,70 @@ func NewRoleRepository(db *gorm.DB) *RoleRepo {
 
 func (r *RoleRepo) GetByID(id uint) (*model.Role, error) {
 	var role model.Role
-	err := r.db.First(&role, id).Error
+	err := r.db.Scopes(r.tenant.query).First(&role, id).Error
 	return &role, err
 }
 
 func (r *RoleRepo) GetByName(name string) (*model.Role, error) {
 	var role model.Role
-	err := r.db.Where("name = ?", name).First(&role).Error
+	err := r.db.Scopes(r.tenant.query).Where("name = ?", name).First(&role).Error
 	return &role, err
 }
 
 func (r *RoleRepo) GetAll() ([]model.Role, error) {
 	var roles []model.Role
-	err := r.db.Find(&roles).Error
+	err := r.db.Scopes(r.tenant.query).Find(&roles).Error
 	return roles, err
 }
 
+// Create və Update assosiasiyaları (valideynlər, permission-lar, istifadəçilər) yazmır –
+// onlar yalnız tenant və dövr yoxlaması olan ayrıca metodlarla dəyişir
 func (r *RoleRepo) Create(role *model.Role) error {
-	return r.db.Create(role).Error
+	r.tenant.assign(&role.TenantID)
+	return r.db.Omit(clause.Associations).Create(role).Error
 }
 
 func (r *RoleRepo) Update(role *model.Role) error {
-	return r.db.Save(role).Error
+	r.tenant.assign(&role.TenantID)
+	return r.db.Scopes(r.tenant.query).Omit(clause.Associations).Save(role).Error
 }
 
 func (r *RoleRepo) Delete(id uint) error {
-	return r.db.Delete(&model.Role{}, id).Error
+	return r.db.Scopes(r.tenant.query).Delete(&model.Role{}, id).Error
 }
 
 func (r *RoleRepo) GetAllWithPermissions() ([]model.Role, error) {
 	var roles []model.Role
-	err := r.db.Preload("Permissions").Find(&roles).Error
+	err := r.db.Scopes(r.tenant.query).Preload("Permissions").Find(&roles).Error
 	return roles, err
 }
 
 func (r *RoleRepo) GetPermissionsByRoleID(id uint) ([]model.Permission, error) {
 	var role model.Role
-	err := r.db.Preload("Permissions").First(&role, id).Error
+	err := r.db.Scopes(r.tenant.query).Preload("Permissions").First(&role, id).Error
 	return role.Permissions, err
 }
+
+func (r *RoleRepo) GetAllWithParents() ([]model.Role, error) {
+	var roles []model.Role
+	err := r.db.Scopes(r.tenant.query).Preload("Parents").Find(&roles).Error
+	return roles, err
+}
+
+func (r *RoleRepo) GetParents(roleID uint) ([]model.Role, error) {
+	var role model.Role
+	err := r.db.Scopes(r.tenant.query).Preload("Parents").First(&role, roleID).Error
+	return role.Parents, err
+}
+
+func (r *RoleRepo) AddParent(roleID, parentID uint) error {
+	role := model.Role{Model: gorm.Model{ID: roleID}}
+	parent := model.Role{Model: gorm.Model{ID: parentID}}
+	return r.db.Model(&role).Association("Parents").Append(&parent)
+}
+
+func (r *RoleRepo) RemoveParent(roleID, parentID uint) error {
+	role := model.Role{Model: gorm.Model{ID: roleID}}
+	parent := model.Role{Model: gorm.Model{ID: parentID}}
+	return r.db.Model(&role).Association("Parents").Delete(&parent)
+}
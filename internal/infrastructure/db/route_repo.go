@@ -0,0 +1,53 @@
+package db
+
+import (
+	"gorm.io/gorm"
+	"ms-authz/internal/domain/model"
+)
+
+type RouteRepo struct {
+	db     *gorm.DB
+	tenant tenantScope
+}
+
+func NewRouteRepository(db *gorm.DB) *RouteRepo {
+	return &RouteRepo{db: db}
+}
+
+// Route cədvəlində uyğunlaşma sırası
+func routeOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("service, priority DESC, id")
+}
+
+func (r *RouteRepo) GetByID(id uint) (*model.Route, error) {
+	var route model.Route
+	err := r.db.Scopes(r.tenant.query).First(&route, id).Error
+	return &route, err
+}
+
+func (r *RouteRepo) GetAll() ([]model.Route, error) {
+	var routes []model.Route
+	err := r.db.Scopes(r.tenant.query, routeOrder).Find(&routes).Error
+	return routes, err
+}
+
+func (r *RouteRepo) GetByService(service string) ([]model.Route, error) {
+	var routes []model.Route
+	err := r.db.Scopes(r.tenant.query, routeOrder).Where("service = ?", service).Find(&routes).Error
+	return routes, err
+}
+
+func (r *RouteRepo) Create(route *model.Route) error {
+	r.tenant.assign(&route.TenantID)
+	return r.db.Create(route).Error
+}
+
+func (r *RouteRepo) Update(route *model.Route) error {
+	r.tenant.assign(&route.TenantID)
+	return r.db.Scopes(r.tenant.query).Save(route).Error
+}
+
+// Delete route-u birdəfəlik silir: unikal indeks deleted_at-ı nəzərə almır, soft delete eyni route-un yenidən yaradılmasını bloklayardı
+func (r *RouteRepo) Delete(id uint) error {
+	return r.db.Unscoped().Scopes(r.tenant.query).Delete(&model.Route{}, id).Error
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"gorm.io/gorm"
+	"ms-authz/internal/domain/model"
+)
+
+// tenantScope repository sorğularını bir tenant-a məhdudlaşdırır.
+// Sıfır dəyər bütün tenant-lar deməkdir (daxili istifadə, məs. RBAC cache-in yüklənməsi).
+type tenantScope struct {
+	id     string
+	scoped bool
+}
+
+// query Scopes ilə işlədilir: tenant_id sütunu olan cədvəllər üçün filtr
+func (t tenantScope) query(db *gorm.DB) *gorm.DB {
+	if !t.scoped {
+		return db
+	}
+	return db.Where("tenant_id = ?", t.id)
+}
+
+// roles role_id sütunu olan cədvəlləri rolun tenant-ı ilə filtr edir
+func (t tenantScope) roles(db *gorm.DB) *gorm.DB {
+	if !t.scoped {
+		return db
+	}
+	return db.Where("role_id IN (?)", db.Session(&gorm.Session{NewDB: true}).
+		Model(&model.Role{}).Select("id").Where("tenant_id = ?", t.id))
+}
+
+// assign yeni yazının tenant-ını təyin edir
+func (t tenantScope) assign(tenantID *string) {
+	if t.scoped {
+		*tenantID = t.id
+	}
+}
+
+// Əvvəlki qlobal unique index-lər tenant-lı index-lərlə əvəz olunub, AutoMigrate onları özü silmir
+var legacyUniqueIndexes = []struct {
+	model any
+	name  string
+}{
+	{&model.Role{}, "idx_roles_name"},
+	{&model.Permission{}, "idx_permissions_name"},
+	{&model.User{}, "idx_users_username"},
+	{&model.User{}, "idx_users_email"},
+	{&model.UserRevocation{}, "idx_user_revocations_user_id"},
+}
+
+// DropLegacyUniqueIndexes tenant-sız unique index-ləri (varsa) silir. AutoMigrate-dən sonra çağırılmalıdır.
+func DropLegacyUniqueIndexes(db *gorm.DB) error {
+	m := db.Migrator()
+	for _, idx := range legacyUniqueIndexes {
+		if !m.HasIndex(idx.model, idx.name) {
+			continue
+		}
+		if err := m.DropIndex(idx.model, idx.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"ms-authz/internal/domain/model"
+	"ms-authz/internal/domain/repository"
+)
+
+// sqlRecorder DryRun rejimində qurulan SQL-ləri toplayır
+type sqlRecorder struct {
+	logger.Interface
+	queries []string
+}
+
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.queries = append(r.queries, sql)
+}
+
+func newDryRunUoW(t *testing.T) (repository.UnitOfWork, *sqlRecorder) {
+	t.Helper()
+	rec := &sqlRecorder{Interface: logger.Discard}
+	gdb, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 user=test dbname=test sslmode=disable"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true, // yazılar üçün bağlantı tələb edən BEGIN açılmasın
+		Logger:                 rec,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewUnitOfWork(gdb), rec
+}
+
+func TestUnitOfWork_ForTenant(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(uow repository.UnitOfWork)
+		want string
+	}{
+		{"role by name", func(u repository.UnitOfWork) { u.RoleRepo().GetByName("admin") }, "tenant_id = 'acme'"},
+		{"roles with parents", func(u repository.UnitOfWork) { u.RoleRepo().GetAllWithParents() }, "tenant_id = 'acme'"},
+		{"permission by id", func(u repository.UnitOfWork) { u.PermissionRepo().GetByID(3) }, "tenant_id = 'acme'"},
+		{"user by username", func(u repository.UnitOfWork) { u.UserRepo().GetByUsername("john") }, "tenant_id = 'acme'"},
+		{"users with roles", func(u repository.UnitOfWork) { u.UserRepo().GetAllWithRoles() }, "tenant_id = 'acme'"},
+		{"relation tuples", func(u repository.UnitOfWork) { u.RelationTupleRepo().Read("document", "1", "viewer") }, "tenant_id = 'acme'"},
+		{"routes by service", func(u repository.UnitOfWork) { u.RouteRepo().GetByService("orders") }, "tenant_id = 'acme'"},
+		{"grants", func(u repository.UnitOfWork) { u.RolePermissionRepo().GetAll() }, `role_id IN (SELECT "id" FROM "roles" WHERE tenant_id = 'acme'`},
+		{"grants of permission", func(u repository.UnitOfWork) { u.RolePermissionRepo().GetByPermissionID(3) }, `role_id IN (SELECT "id" FROM "roles" WHERE tenant_id = 'acme'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uow, rec := newDryRunUoW(t)
+			tt.run(uow.ForTenant("acme"))
+			if len(rec.queries) == 0 || !strings.Contains(rec.queries[0], tt.want) {
+				t.Errorf("scoped query = %q, want it to contain %q", rec.queries, tt.want)
+			}
+
+			rec.queries = nil
+			tt.run(uow)
+			if len(rec.queries) == 0 || strings.Contains(rec.queries[0], "tenant_id") {
+				t.Errorf("unscoped query = %q, want no tenant filter", rec.queries)
+			}
+		})
+	}
+}
+
+func TestUnitOfWork_ForTenantAssignsTenantOnCreate(t *testing.T) {
+	uow, _ := newDryRunUoW(t)
+	tx := uow.ForTenant("acme")
+
+	role := model.Role{Name: "admin", TenantID: "other"}
+	perm := model.Permission{Name: "orders:read"}
+	user := model.User{Username: "john"}
+	_ = tx.RoleRepo().Create(&role)
+	_ = tx.PermissionRepo().Create(&perm)
+	_ = tx.UserRepo().Create(&user)
+	if role.TenantID != "acme" || perm.TenantID != "acme" || user.TenantID != "acme" {
+		t.Errorf("tenants = %q, %q, %q; want acme", role.TenantID, perm.TenantID, user.TenantID)
+	}
+}
+
+func TestRouteRepo_DeleteIsHard(t *testing.T) {
+	uow, rec := newDryRunUoW(t)
+	_ = uow.ForTenant("acme").RouteRepo().Delete(3)
+	if len(rec.queries) == 0 || !strings.HasPrefix(rec.queries[0], `DELETE FROM "routes"`) || !strings.Contains(rec.queries[0], "tenant_id = 'acme'") {
+		t.Errorf("delete query = %q, want a tenant-scoped hard delete", rec.queries)
+	}
+}
+
+func TestRoleRepo_WritesSkipAssociations(t *testing.T) {
+	uow, rec := newDryRunUoW(t)
+	foreign := model.Role{Model: gorm.Model{ID: 9}, TenantID: "globex", Name: "admin"}
+	role := model.Role{Model: gorm.Model{ID: 3}, Name: "viewer", Parents: []model.Role{foreign}}
+	perm := model.Permission{Model: gorm.Model{ID: 4}, Name: "orders:read", Roles: []model.Role{foreign}}
+
+	tx := uow.ForTenant("acme")
+	_ = tx.RoleRepo().Create(&role)
+	_ = tx.RoleRepo().Update(&role)
+	_ = tx.PermissionRepo().Create(&perm)
+	_ = tx.PermissionRepo().Update(&perm)
+	for _, q := range rec.queries {
+		if strings.Contains(q, "role_parents") || strings.Contains(q, "role_permissions") || strings.Contains(q, "globex") {
+			t.Errorf("write touched an association: %s", q)
+		}
+	}
+	if len(rec.queries) == 0 {
+		t.Fatal("no queries recorded")
+	}
+}
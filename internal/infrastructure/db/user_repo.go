@@ -6,7 +6,8 @@ import (
 )
 
 type UserRepo struct {
-	db *gorm.DB
+	db     *gorm.DB
+	tenant tenantScope
 }
 
 func NewUserRepository(db *gorm.DB) *UserRepo {
@@ -15,26 +16,86 @@ func NewUserRepository(db *gorm.DB) *UserRepo {
 
 func (r *UserRepo) GetByID(id uint) (*model.User, error) {
 	var user model.User
-	err := r.db.Preload("Role").First(&user, id).Error
+	err := r.db.Scopes(r.tenant.query).Preload("Role").First(&user, id).Error
 	return &user, err
 }
 
 func (r *UserRepo) GetByUsername(username string) (*model.User, error) {
 	var user model.User
-	err := r.db.Preload("Role").Where("username = ?", username).First(&user).Error
+	err := r.db.Scopes(r.tenant.query).Preload("Role").Where("username = ?", username).First(&user).Error
+	return &user, err
+}
+
+func (r *UserRepo) GetByEmail(email string) (*model.User, error) {
+	var user model.User
+	err := r.db.Scopes(r.tenant.query).Preload("Role").Where("email = ?", email).First(&user).Error
 	return &user, err
 }
 
 func (r *UserRepo) GetAll() ([]model.User, error) {
 	var users []model.User
-	err := r.db.Preload("Role").Find(&users).Error
+	err := r.db.Scopes(r.tenant.query).Preload("Role").Find(&users).Error
 	return users, err
 }
 
 func (r *UserRepo) Create(user *model.User) error {
+	r.tenant.assign(&user.TenantID)
+	// role_id boşdursa NULL yazılır (roles FK-sı 0-ı qəbul etmir)
+	if user.RoleID == 0 {
+		return r.db.Omit("RoleID").Create(user).Error
+	}
 	return r.db.Create(user).Error
 }
 
+func (r *UserRepo) Update(user *model.User) error {
+	var roleID any
+	if user.RoleID != 0 {
+		roleID = user.RoleID
+	}
+	return r.db.Scopes(r.tenant.query).Model(user).Updates(map[string]any{
+		"username": user.Username,
+		"email":    user.Email,
+		"role_id":  roleID,
+	}).Error
+}
+
 func (r *UserRepo) Delete(id uint) error {
-	return r.db.Delete(&model.User{}, id).Error
+	return r.db.Scopes(r.tenant.query).Delete(&model.User{}, id).Error
+}
+
+func (r *UserRepo) Restore(id uint) error {
+	res := r.db.Unscoped().Scopes(r.tenant.query).Model(&model.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *UserRepo) GetAllWithRoles() ([]model.User, error) {
+	var users []model.User
+	err := r.db.Scopes(r.tenant.query).Preload("Role").Preload("Roles").Find(&users).Error
+	return users, err
+}
+
+func (r *UserRepo) GetRoles(userID uint) ([]model.Role, error) {
+	var user model.User
+	err := r.db.Scopes(r.tenant.query).Preload("Roles").First(&user, userID).Error
+	return user.Roles, err
+}
+
+func (r *UserRepo) AssignRole(userID, roleID uint) error {
+	user := model.User{Model: gorm.Model{ID: userID}}
+	role := model.Role{Model: gorm.Model{ID: roleID}}
+	return r.db.Model(&user).Association("Roles").Append(&role)
+}
+
+func (r *UserRepo) UnassignRole(userID, roleID uint) error {
+	user := model.User{Model: gorm.Model{ID: userID}}
+	role := model.Role{Model: gorm.Model{ID: roleID}}
+	return r.db.Model(&user).Association("Roles").Delete(&role)
 }
@@ -1,20 +1,169 @@
 package mq
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"sync"
+	"time"
 
 	"github.com/rabbitmq/amqp091-go"
 )
 
+var (
+	ErrNotConnected = errors.New("RabbitMQ is not connected")
+	ErrClosed       = errors.New("RabbitMQ connection is closed")
+)
+
+// Servisin istifadə etdiyi exchange-lər – hər (yenidən) qoşulmada declare olunur
+var exchanges = []string{"auth.tokens.fanout", "rbac.update.fanout"}
+
+// Channel *amqp091.Channel-in istifadə etdiyimiz hissəsi (testdə fake ilə əvəz olunur)
+type Channel interface {
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp091.Table) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp091.Table) (amqp091.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp091.Table) error
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp091.Table) (<-chan amqp091.Delivery, error)
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp091.Publishing) error
+	Confirm(noWait bool) error
+	NotifyPublish(confirm chan amqp091.Confirmation) chan amqp091.Confirmation
+	NotifyReturn(c chan amqp091.Return) chan amqp091.Return
+	NotifyClose(c chan *amqp091.Error) chan *amqp091.Error
+	IsClosed() bool
+	Close() error
+}
+
+// Connection *amqp091.Connection-un istifadə etdiyimiz hissəsi
+type Connection interface {
+	Channel() (Channel, error)
+	NotifyClose(c chan *amqp091.Error) chan *amqp091.Error
+	IsClosed() bool
+	Close() error
+}
+
+type Dialer func(url string) (Connection, error)
+
+type amqpConnection struct {
+	*amqp091.Connection
+}
+
+func (c amqpConnection) Channel() (Channel, error) {
+	return c.Connection.Channel()
+}
+
+func dialAMQP(url string) (Connection, error) {
+	conn, err := amqp091.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	return amqpConnection{conn}, nil
+}
+
+// MQ RabbitMQ bağlantısını nəzarətdə saxlayır: bağlantı qırılanda backoff ilə
+// yenidən qoşulur, exchange-ləri yenidən declare edir və Subscribe ilə qeydiyyatdan
+// keçmiş consumer-ləri yeni channel-lər üzərində bərpa edir.
 type MQ struct {
-	Connection *amqp091.Connection
-	Channel    *amqp091.Channel
+	url        string
+	dial       Dialer
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu        sync.RWMutex
+	conn      Connection
+	connReady chan struct{} // yeni bağlantı qurulanda bağlanır (broadcast)
+	closing   bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
 }
 
 // NewMQ RabbitMQ bağlantısını qurur və exchange-ləri yaradır
 func NewMQ(rabbitURL string) (*MQ, error) {
-	conn, err := amqp091.Dial(rabbitURL)
+	return newMQ(rabbitURL, dialAMQP, 500*time.Millisecond, 30*time.Second)
+}
+
+func newMQ(rabbitURL string, dial Dialer, minBackoff, maxBackoff time.Duration) (*MQ, error) {
+	m := &MQ{
+		url:        rabbitURL,
+		dial:       dial,
+		minBackoff: minBackoff,
+		maxBackoff: maxBackoff,
+		connReady:  make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+
+	// İlk qoşulma uğursuz olarsa servis başlamır
+	conn, err := m.connect()
+	if err != nil {
+		return nil, err
+	}
+	m.setConnection(conn)
+
+	m.wg.Add(1)
+	go m.supervise()
+
+	log.Println("[MQ] Connected and exchanges declared")
+	return m, nil
+}
+
+// NewChannel cari bağlantı üzərində yeni channel açır. Bağlantı yoxdursa gözləmir.
+func (m *MQ) NewChannel() (Channel, error) {
+	m.mu.RLock()
+	conn, closing := m.conn, m.closing
+	m.mu.RUnlock()
+
+	if closing {
+		return nil, ErrClosed
+	}
+	if conn == nil || conn.IsClosed() {
+		return nil, ErrNotConnected
+	}
+	return conn.Channel()
+}
+
+// Subscribe setup funksiyasını ayrıca channel üzərində işə salır və channel və ya
+// bağlantı qırıldıqda onu avtomatik olaraq yeni channel ilə təkrar çağırır.
+// İlk setup-ın xətası birbaşa qaytarılır.
+func (m *MQ) Subscribe(setup func(ch Channel) error) error {
+	ch, err := m.NewChannel()
+	if err != nil {
+		return err
+	}
+	closed := ch.NotifyClose(make(chan *amqp091.Error, 1))
+	if err := setup(ch); err != nil {
+		_ = ch.Close()
+		return err
+	}
+
+	m.wg.Add(1)
+	go m.resubscribe(setup, closed)
+	return nil
+}
+
+// Close RabbitMQ connection və channel-ləri bağlayır
+func (m *MQ) Close() {
+	m.mu.Lock()
+	if m.closing {
+		m.mu.Unlock()
+		return
+	}
+	m.closing = true
+	conn := m.conn
+	m.mu.Unlock()
+
+	close(m.done)
+	if conn != nil {
+		if err := conn.Close(); err != nil && !errors.Is(err, amqp091.ErrClosed) {
+			log.Println("Error closing MQ connection:", err)
+		}
+	}
+	m.wg.Wait()
+}
+
+// Dial + exchange-lərin declare olunması
+func (m *MQ) connect() (Connection, error) {
+	conn, err := m.dial(m.url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
 	}
@@ -24,9 +173,9 @@ func NewMQ(rabbitURL string) (*MQ, error) {
 		_ = conn.Close()
 		return nil, fmt.Errorf("failed to open channel: %w", err)
 	}
+	defer ch.Close()
 
 	// Exchange-ləri fanout növündə yaradırıq
-	exchanges := []string{"auth.tokens.fanout", "rbac.update.fanout"}
 	for _, ex := range exchanges {
 		err := ch.ExchangeDeclare(
 			ex,       // name
@@ -38,29 +187,145 @@ func NewMQ(rabbitURL string) (*MQ, error) {
 			nil,      // arguments
 		)
 		if err != nil {
-			_ = ch.Close()
 			_ = conn.Close()
 			return nil, fmt.Errorf("failed to declare exchange '%s': %w", ex, err)
 		}
 	}
+	return conn, nil
+}
 
-	log.Println("[MQ] Connected and exchanges declared")
-	return &MQ{
-		Connection: conn,
-		Channel:    ch,
-	}, nil
+func (m *MQ) setConnection(conn Connection) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.conn = conn
+	close(m.connReady)
+	m.connReady = make(chan struct{})
 }
 
-// Close RabbitMQ connection və channel-i bağlayır
-func (mq *MQ) Close() {
-	if mq.Channel != nil {
-		if err := mq.Channel.Close(); err != nil {
-			log.Println("Error closing MQ channel:", err)
+// Bağlantının qırılmasını izləyir və yenidən qoşulur
+func (m *MQ) supervise() {
+	defer m.wg.Done()
+
+	for {
+		m.mu.RLock()
+		conn := m.conn
+		m.mu.RUnlock()
+
+		select {
+		case err := <-conn.NotifyClose(make(chan *amqp091.Error, 1)):
+			if m.isClosing() {
+				return
+			}
+			log.Printf("⚠️ [MQ] connection lost: %v", err)
+		case <-m.done:
+			return
+		}
+
+		if !m.reconnect() {
+			return
 		}
 	}
-	if mq.Connection != nil {
-		if err := mq.Connection.Close(); err != nil {
-			log.Println("Error closing MQ connection:", err)
+}
+
+func (m *MQ) reconnect() bool {
+	for attempt := 0; ; attempt++ {
+		select {
+		case <-time.After(m.backoff(attempt)):
+		case <-m.done:
+			return false
+		}
+
+		conn, err := m.connect()
+		if err != nil {
+			log.Printf("❌ [MQ] reconnect attempt %d failed: %v", attempt+1, err)
+			continue
+		}
+
+		m.mu.Lock()
+		if m.closing {
+			m.mu.Unlock()
+			_ = conn.Close()
+			return false
+		}
+		m.mu.Unlock()
+
+		m.setConnection(conn)
+		log.Println("✅ [MQ] reconnected and exchanges re-declared")
+		return true
+	}
+}
+
+// Channel qırılanda setup-ı yeni channel üzərində təkrarlayır
+func (m *MQ) resubscribe(setup func(ch Channel) error, closed chan *amqp091.Error) {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case <-closed:
+		case <-m.done:
+			return
+		}
+		if m.isClosing() {
+			return
 		}
+
+		for attempt := 0; ; attempt++ {
+			ch, err := m.waitChannel(attempt)
+			if err != nil {
+				return // MQ bağlanır
+			}
+			closed = ch.NotifyClose(make(chan *amqp091.Error, 1))
+			if err := setup(ch); err != nil {
+				log.Printf("❌ [MQ] re-subscribe failed: %v", err)
+				_ = ch.Close()
+				continue
+			}
+			log.Println("✅ [MQ] consumer re-attached")
+			break
+		}
+	}
+}
+
+// Bağlantı bərpa olunana qədər gözləyir və yeni channel açır
+func (m *MQ) waitChannel(attempt int) (Channel, error) {
+	for {
+		m.mu.RLock()
+		ready := m.connReady
+		m.mu.RUnlock()
+
+		ch, err := m.NewChannel()
+		if err == nil {
+			return ch, nil
+		}
+		if errors.Is(err, ErrClosed) {
+			return nil, err
+		}
+
+		// Yeni bağlantını və ya növbəti cəhdin vaxtını gözləyirik
+		select {
+		case <-ready:
+		case <-time.After(m.backoff(attempt)):
+			attempt++
+		case <-m.done:
+			return nil, ErrClosed
+		}
+	}
+}
+
+func (m *MQ) isClosing() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.closing
+}
+
+// Exponential backoff: minBackoff, 2x, 4x, ... maxBackoff
+func (m *MQ) backoff(attempt int) time.Duration {
+	d := m.minBackoff
+	for i := 0; i < attempt && d < m.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > m.maxBackoff {
+		d = m.maxBackoff
 	}
+	return d
 }
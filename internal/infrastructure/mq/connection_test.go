@@ -0,0 +1,392 @@
+package mq
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// fakeBroker bütün dial-ları və declare/publish əməliyyatlarını qeydə alır
+type fakeBroker struct {
+	mu        sync.Mutex
+	conns     []*fakeConn
+	exchanges map[string]int // exchange -> declare sayı
+	published []string
+	failDials int
+
+	// publisher confirm davranışı
+	nack       bool
+	unroutable bool
+	noConfirm  bool
+}
+
+func newFakeBroker() *fakeBroker {
+	return &fakeBroker{exchanges: make(map[string]int)}
+}
+
+func (b *fakeBroker) dial(string) (Connection, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failDials > 0 {
+		b.failDials--
+		return nil, errors.New("connection refused")
+	}
+	conn := &fakeConn{broker: b}
+	b.conns = append(b.conns, conn)
+	return conn, nil
+}
+
+func (b *fakeBroker) dials() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.conns)
+}
+
+func (b *fakeBroker) current() *fakeConn {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.conns[len(b.conns)-1]
+}
+
+// kill broker restartını simulyasiya edir
+func (b *fakeBroker) kill(failDials int) {
+	b.mu.Lock()
+	b.failDials = failDials
+	b.mu.Unlock()
+	b.current().drop(&amqp091.Error{Code: amqp091.ConnectionForced, Reason: "broker restart"})
+}
+
+type fakeConn struct {
+	broker   *fakeBroker
+	mu       sync.Mutex
+	closed   bool
+	notify   []chan *amqp091.Error
+	channels []*fakeChannel
+}
+
+func (c *fakeConn) Channel() (Channel, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, amqp091.ErrClosed
+	}
+	ch := &fakeChannel{conn: c}
+	c.channels = append(c.channels, ch)
+	return ch, nil
+}
+
+func (c *fakeConn) NotifyClose(receiver chan *amqp091.Error) chan *amqp091.Error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		close(receiver)
+	} else {
+		c.notify = append(c.notify, receiver)
+	}
+	return receiver
+}
+
+func (c *fakeConn) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+func (c *fakeConn) Close() error {
+	c.drop(nil)
+	return nil
+}
+
+func (c *fakeConn) drop(err *amqp091.Error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	notify, channels := c.notify, c.channels
+	c.mu.Unlock()
+
+	for _, ch := range channels {
+		ch.drop(err)
+	}
+	for _, n := range notify {
+		if err != nil {
+			n <- err
+		}
+		close(n)
+	}
+}
+
+type fakeChannel struct {
+	conn     *fakeConn
+	mu       sync.Mutex
+	closed   bool
+	notify   []chan *amqp091.Error
+	subs     []chan amqp091.Delivery
+	confirms []chan amqp091.Confirmation
+	returns  []chan amqp091.Return
+	tag      uint64
+}
+
+func (ch *fakeChannel) ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp091.Table) error {
+	ch.conn.broker.mu.Lock()
+	defer ch.conn.broker.mu.Unlock()
+	ch.conn.broker.exchanges[name]++
+	return nil
+}
+
+func (ch *fakeChannel) QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp091.Table) (amqp091.Queue, error) {
+	return amqp091.Queue{Name: "amq.gen-test"}, nil
+}
+
+func (ch *fakeChannel) QueueBind(name, key, exchange string, noWait bool, args amqp091.Table) error {
+	return nil
+}
+
+func (ch *fakeChannel) Qos(prefetchCount, prefetchSize int, global bool) error {
+	return nil
+}
+
+func (ch *fakeChannel) Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp091.Table) (<-chan amqp091.Delivery, error) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	d := make(chan amqp091.Delivery)
+	ch.subs = append(ch.subs, d)
+	return d, nil
+}
+
+func (ch *fakeChannel) Publish(exchange, key string, mandatory, immediate bool, msg amqp091.Publishing) error {
+	ch.mu.Lock()
+	if ch.closed {
+		ch.mu.Unlock()
+		return amqp091.ErrClosed
+	}
+	ch.tag++
+	tag, confirms, returns := ch.tag, ch.confirms, ch.returns
+	ch.mu.Unlock()
+
+	b := ch.conn.broker
+	b.mu.Lock()
+	nack, unroutable, noConfirm := b.nack, b.unroutable, b.noConfirm
+	if !nack && !unroutable {
+		b.published = append(b.published, string(msg.Body))
+	}
+	b.mu.Unlock()
+
+	if unroutable && mandatory {
+		for _, r := range returns {
+			r <- amqp091.Return{ReplyCode: amqp091.NoRoute, ReplyText: "NO_ROUTE", Exchange: exchange}
+		}
+	}
+	if noConfirm {
+		return nil
+	}
+	for _, c := range confirms {
+		c <- amqp091.Confirmation{DeliveryTag: tag, Ack: !nack}
+	}
+	return nil
+}
+
+func (ch *fakeChannel) Confirm(noWait bool) error {
+	return nil
+}
+
+func (ch *fakeChannel) NotifyPublish(confirm chan amqp091.Confirmation) chan amqp091.Confirmation {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	ch.confirms = append(ch.confirms, confirm)
+	return confirm
+}
+
+func (ch *fakeChannel) NotifyReturn(receiver chan amqp091.Return) chan amqp091.Return {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	ch.returns = append(ch.returns, receiver)
+	return receiver
+}
+
+func (ch *fakeChannel) NotifyClose(receiver chan *amqp091.Error) chan *amqp091.Error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if ch.closed {
+		close(receiver)
+	} else {
+		ch.notify = append(ch.notify, receiver)
+	}
+	return receiver
+}
+
+func (ch *fakeChannel) IsClosed() bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	return ch.closed
+}
+
+func (ch *fakeChannel) Close() error {
+	ch.drop(nil)
+	return nil
+}
+
+func (ch *fakeChannel) drop(err *amqp091.Error) {
+	ch.mu.Lock()
+	if ch.closed {
+		ch.mu.Unlock()
+		return
+	}
+	ch.closed = true
+	notify, subs, confirms, returns := ch.notify, ch.subs, ch.confirms, ch.returns
+	ch.mu.Unlock()
+
+	for _, s := range subs {
+		close(s)
+	}
+	for _, c := range confirms {
+		close(c)
+	}
+	for _, r := range returns {
+		close(r)
+	}
+	for _, n := range notify {
+		if err != nil {
+			n <- err
+		}
+		close(n)
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestMQ_ReconnectsAndReattaches(t *testing.T) {
+	broker := newFakeBroker()
+	m, err := newMQ("amqp://test", broker.dial, time.Millisecond, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	var mu sync.Mutex
+	setups := 0
+	err = m.Subscribe(func(ch Channel) error {
+		mu.Lock()
+		setups++
+		mu.Unlock()
+		_, err := ch.Consume("q", "", false, true, false, false, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publisher := NewPublisherService(m, time.Second)
+	if err := publisher.PublishEvent("auth.tokens.fanout", map[string]string{"event": "first"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Broker restart: ilk 2 yenidən qoşulma cəhdi uğursuz olur
+	broker.kill(2)
+
+	waitFor(t, "reconnect", func() bool { return broker.dials() == 2 })
+	waitFor(t, "consumer re-attach", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return setups == 2
+	})
+
+	broker.mu.Lock()
+	for _, ex := range exchanges {
+		if broker.exchanges[ex] != 2 {
+			t.Errorf("exchange %s declared %d times, want 2", ex, broker.exchanges[ex])
+		}
+	}
+	broker.mu.Unlock()
+
+	if err := publisher.PublishEvent("auth.tokens.fanout", map[string]string{"event": "second"}, nil); err != nil {
+		t.Fatalf("PublishEvent() after reconnect error = %v", err)
+	}
+	broker.mu.Lock()
+	if len(broker.published) != 2 {
+		t.Errorf("published %d messages, want 2", len(broker.published))
+	}
+	broker.mu.Unlock()
+}
+
+func TestMQ_ResubscribesOnChannelClose(t *testing.T) {
+	broker := newFakeBroker()
+	m, err := newMQ("amqp://test", broker.dial, time.Millisecond, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	var mu sync.Mutex
+	var channels []Channel
+	if err := m.Subscribe(func(ch Channel) error {
+		mu.Lock()
+		defer mu.Unlock()
+		channels = append(channels, ch)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Yalnız channel səviyyəsində xəta – bağlantı sağdır
+	mu.Lock()
+	channels[0].(*fakeChannel).drop(&amqp091.Error{Code: amqp091.PreconditionFailed})
+	mu.Unlock()
+
+	waitFor(t, "resubscribe", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(channels) == 2
+	})
+	if broker.dials() != 1 {
+		t.Errorf("channel error must not reconnect, got %d dials", broker.dials())
+	}
+}
+
+func TestMQ_PublishWhileDisconnected(t *testing.T) {
+	broker := newFakeBroker()
+	m, err := newMQ("amqp://test", broker.dial, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	broker.kill(0)
+	err = NewPublisherService(m, time.Second).PublishEvent("auth.tokens.fanout", map[string]string{}, nil)
+	if !errors.Is(err, ErrNotConnected) {
+		t.Errorf("PublishEvent() error = %v, want %v", err, ErrNotConnected)
+	}
+}
+
+func TestMQ_Close(t *testing.T) {
+	broker := newFakeBroker()
+	m, err := newMQ("amqp://test", broker.dial, time.Millisecond, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Subscribe(func(Channel) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Close()
+	if broker.dials() != 1 {
+		t.Errorf("Close() must not trigger a reconnect, got %d dials", broker.dials())
+	}
+	if _, err := m.NewChannel(); !errors.Is(err, ErrClosed) {
+		t.Errorf("NewChannel() after Close error = %v, want %v", err, ErrClosed)
+	}
+}
@@ -1,33 +1,80 @@
 package mq
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"sync"
+	"time"
 
 	"github.com/rabbitmq/amqp091-go"
 )
 
 type Publisher interface {
 	PublishEvent(exchange string, payload any, passiveQueues []string) error
+	PublishEventContext(ctx context.Context, exchange string, payload any, passiveQueues []string) error
 }
 
+var (
+	// ErrNacked broker mesajı qəbul etmədikdə (basic.nack) qaytarılır
+	ErrNacked = errors.New("message was not confirmed by the broker")
+	// ErrUnroutable mesaj heç bir queue-ya çatmadıqda (mandatory, basic.return) qaytarılır
+	ErrUnroutable = errors.New("message was returned as unroutable")
+)
+
+// DefaultConfirmTimeout PublishEvent-in broker təsdiqini gözləmə müddəti
+const DefaultConfirmTimeout = 5 * time.Second
+
+// PublisherService öz channel-ini confirm rejimində saxlayır: PublishEvent yalnız broker
+// mesajı təsdiqlədikdən və o ən azı bir queue-ya çatdıqdan sonra nil qaytarır.
+// Channel və ya bağlantı qırılıbsa növbəti publish zamanı cari bağlantı üzərində yenisi açılır.
 type PublisherService struct {
-	ch *amqp091.Channel
+	mq             *MQ
+	confirmTimeout time.Duration
+
+	mu       sync.Mutex
+	ch       Channel
+	confirms chan amqp091.Confirmation
+	returns  chan amqp091.Return
 }
 
-func NewPublisherService(ch *amqp091.Channel) *PublisherService {
-	return &PublisherService{ch: ch}
+// NewPublisherService confirmTimeout <= 0 olarsa DefaultConfirmTimeout istifadə olunur
+func NewPublisherService(mq *MQ, confirmTimeout time.Duration) *PublisherService {
+	if confirmTimeout <= 0 {
+		confirmTimeout = DefaultConfirmTimeout
+	}
+	return &PublisherService{mq: mq, confirmTimeout: confirmTimeout}
 }
 
+// PublishEvent PublishEventContext-i confirmTimeout ilə çağırır
 func (p *PublisherService) PublishEvent(exchange string, payload any, passiveQueues []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.confirmTimeout)
+	defer cancel()
+	return p.PublishEventContext(ctx, exchange, payload, passiveQueues)
+}
+
+// PublishEventContext mesajı mandatory olaraq göndərir və broker-in ack/nack cavabını
+// ctx bitənə qədər gözləyir. Nack, return və ya timeout xəta kimi qaytarılır.
+func (p *PublisherService) PublishEventContext(ctx context.Context, exchange string, payload any, passiveQueues []string) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
 
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch, err := p.channel()
+	if err != nil {
+		log.Printf("❌ Failed to publish to %s: %v", exchange, err)
+		return err
+	}
+
 	// Queue-ları əvvəlcə yaradıb bind edirik
 	for _, qName := range passiveQueues {
-		_, err := p.ch.QueueDeclare(
+		_, err := ch.QueueDeclare(
 			qName, true, false, false, false, nil,
 		)
 		if err != nil {
@@ -35,20 +82,21 @@ func (p *PublisherService) PublishEvent(exchange string, payload any, passiveQue
 			continue
 		}
 
-		err = p.ch.QueueBind(qName, "", exchange, false, nil)
+		err = ch.QueueBind(qName, "", exchange, false, nil)
 		if err != nil {
 			log.Printf("❌ Failed to bind queue %s: %v", qName, err)
 		}
 	}
 
-	err = p.ch.Publish(
+	err = ch.Publish(
 		exchange,
 		"",
-		false,
+		true, // mandatory: heç bir queue-ya çatmasa broker geri qaytarır
 		false,
 		amqp091.Publishing{
-			ContentType: "application/json",
-			Body:        body,
+			ContentType:  "application/json",
+			DeliveryMode: amqp091.Persistent,
+			Body:         body,
 		},
 	)
 	if err != nil {
@@ -56,6 +104,66 @@ func (p *PublisherService) PublishEvent(exchange string, payload any, passiveQue
 		return err
 	}
 
-	log.Printf("📤 Published event to %s: %s", exchange, string(body))
+	if err := p.waitConfirm(ctx); err != nil {
+		log.Printf("❌ Event to %s not confirmed: %v", exchange, err)
+		return err
+	}
+
+	log.Printf("📤 Published event to %s (confirmed): %s", exchange, string(body))
 	return nil
 }
+
+// Mutex altında eyni anda yalnız bir mesaj təsdiq gözləyir, ona görə növbəti confirm bizimkidir.
+// Broker basic.return-u ack-dan əvvəl göndərir, amqp091 da onları eyni ardıcıllıqla ötürür.
+func (p *PublisherService) waitConfirm(ctx context.Context) error {
+	select {
+	case confirm, ok := <-p.confirms:
+		if !ok {
+			p.reset()
+			return ErrNacked
+		}
+		select {
+		case ret, ok := <-p.returns:
+			if ok {
+				return fmt.Errorf("%w: %s (%d)", ErrUnroutable, ret.ReplyText, ret.ReplyCode)
+			}
+		default:
+		}
+		if !confirm.Ack {
+			return ErrNacked
+		}
+		return nil
+	case <-ctx.Done():
+		// Gecikmiş confirm növbəti mesaja aid edilməsin deyə channel dəyişdirilir
+		p.reset()
+		return fmt.Errorf("waiting for publisher confirm: %w", ctx.Err())
+	}
+}
+
+// p.mu altında çağırılmalıdır
+func (p *PublisherService) channel() (Channel, error) {
+	if p.ch != nil && !p.ch.IsClosed() {
+		return p.ch, nil
+	}
+
+	ch, err := p.mq.NewChannel()
+	if err != nil {
+		return nil, err
+	}
+	if err := ch.Confirm(false); err != nil {
+		_ = ch.Close()
+		return nil, err
+	}
+	p.confirms = ch.NotifyPublish(make(chan amqp091.Confirmation, 1))
+	p.returns = ch.NotifyReturn(make(chan amqp091.Return, 1))
+	p.ch = ch
+	return ch, nil
+}
+
+// p.mu altında çağırılmalıdır
+func (p *PublisherService) reset() {
+	if p.ch != nil {
+		_ = p.ch.Close()
+	}
+	p.ch = nil
+}
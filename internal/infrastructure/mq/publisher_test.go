@@ -0,0 +1,70 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPublisherService_Confirms(t *testing.T) {
+	tests := []struct {
+		name       string
+		nack       bool
+		unroutable bool
+		noConfirm  bool
+		wantErr    error
+	}{
+		{name: "acked", wantErr: nil},
+		{name: "nacked", nack: true, wantErr: ErrNacked},
+		{name: "returned as unroutable", unroutable: true, wantErr: ErrUnroutable},
+		{name: "confirm timeout", noConfirm: true, wantErr: context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := newFakeBroker()
+			m, err := newMQ("amqp://test", broker.dial, time.Hour, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer m.Close()
+
+			broker.mu.Lock()
+			broker.nack, broker.unroutable, broker.noConfirm = tt.nack, tt.unroutable, tt.noConfirm
+			broker.mu.Unlock()
+
+			publisher := NewPublisherService(m, 50*time.Millisecond)
+			err = publisher.PublishEvent("auth.tokens.fanout", map[string]string{"event": "x"}, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PublishEvent() error = %v, want %v", err, tt.wantErr)
+			}
+
+			// Xətadan sonra publisher işlək qalmalıdır
+			broker.mu.Lock()
+			broker.nack, broker.unroutable, broker.noConfirm = false, false, false
+			broker.mu.Unlock()
+			if err := publisher.PublishEvent("auth.tokens.fanout", map[string]string{"event": "y"}, nil); err != nil {
+				t.Errorf("PublishEvent() after %s error = %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestPublisherService_PublishEventContextCancelled(t *testing.T) {
+	broker := newFakeBroker()
+	m, err := newMQ("amqp://test", broker.dial, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	broker.noConfirm = true
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = NewPublisherService(m, time.Hour).PublishEventContext(ctx, "auth.tokens.fanout", map[string]string{}, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("PublishEventContext() error = %v, want %v", err, context.Canceled)
+	}
+}
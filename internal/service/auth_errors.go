@@ -0,0 +1,47 @@
+package service
+
+import "errors"
+
+// TokenErrorReason token-in hansı yoxlamadan keçmədiyini göstərir
+type TokenErrorReason string
+
+const (
+	ReasonMalformed           TokenErrorReason = "malformed"
+	ReasonMissingKid          TokenErrorReason = "missing_kid"
+	ReasonUnknownKey          TokenErrorReason = "unknown_key"
+	ReasonAlgorithmNotAllowed TokenErrorReason = "algorithm_not_allowed"
+	ReasonInvalidSignature    TokenErrorReason = "invalid_signature"
+	ReasonExpired             TokenErrorReason = "expired"
+	ReasonNotYetValid         TokenErrorReason = "not_yet_valid"
+	ReasonUntrustedIssuer     TokenErrorReason = "untrusted_issuer"
+	ReasonAudienceMismatch    TokenErrorReason = "audience_mismatch"
+	ReasonBlacklisted         TokenErrorReason = "blacklisted"
+	ReasonRevoked             TokenErrorReason = "revoked" // logout-all cutoff-undan əvvəl buraxılıb
+)
+
+// TokenError token doğrulama xətası və onun səbəbi
+type TokenError struct {
+	Reason TokenErrorReason
+	Err    error
+}
+
+func newTokenError(reason TokenErrorReason, msg string) *TokenError {
+	return &TokenError{Reason: reason, Err: errors.New(msg)}
+}
+
+func (e *TokenError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TokenError) Unwrap() error {
+	return e.Err
+}
+
+// TokenErrorReasonOf xətadan səbəbi çıxarır, TokenError deyilsə boş qaytarır
+func TokenErrorReasonOf(err error) TokenErrorReason {
+	var tokenErr *TokenError
+	if errors.As(err, &tokenErr) {
+		return tokenErr.Reason
+	}
+	return ""
+}
@@ -2,67 +2,352 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
 	"ms-authz/internal/domain/repository"
+	"ms-authz/internal/dto"
 	"ms-authz/internal/infrastructure/cache"
 	"ms-authz/pkg/jwtutil"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
 type AuthService struct {
+	uow               repository.UnitOfWork
 	tokenRepo         repository.TokenRepository
 	publicKeyProvider jwtutil.PublicKeyProvider
+	issuers           map[string]TrustedIssuer
+	clockSkew         time.Duration
 }
 
-func NewAuthService(tokenRepo repository.TokenRepository, keyProvider jwtutil.PublicKeyProvider) *AuthService {
+// TrustedIssuer etibarlı token buraxan (iss) və onun açar mənbəyi
+type TrustedIssuer struct {
+	Issuer      string
+	Audiences   []string                  // boşdursa aud yoxlanılmır
+	KeyProvider jwtutil.PublicKeyProvider // nil olarsa default provider istifadə olunur
+}
+
+type AuthConfig struct {
+	// Boş olarsa iss/aud yoxlanılmır və bütün tokenlər default provider ilə yoxlanır
+	Issuers   []TrustedIssuer
+	ClockSkew time.Duration // exp/nbf/iat üçün tolerantlıq
+}
+
+func NewAuthService(uow repository.UnitOfWork, tokenRepo repository.TokenRepository, keyProvider jwtutil.PublicKeyProvider, cfg AuthConfig) *AuthService {
+	issuers := make(map[string]TrustedIssuer, len(cfg.Issuers))
+	for _, iss := range cfg.Issuers {
+		issuers[iss.Issuer] = iss
+	}
+
 	return &AuthService{
+		uow:               uow,
 		tokenRepo:         tokenRepo,
 		publicKeyProvider: keyProvider,
+		issuers:           issuers,
+		clockSkew:         cfg.ClockSkew,
 	}
 }
 
-// Yeganə token doğrulama funksiyası – həm JWT, həm Blacklist yoxlayır
+// Yeganə token doğrulama funksiyası – həm JWT, həm Blacklist yoxlayır.
+// Xətalar *TokenError tipindədir, səbəbi TokenErrorReasonOf ilə almaq olar.
 func (s *AuthService) Validate(tokenStr string, checkJWT, checkBlacklist bool) (*jwtutil.Claims, error) {
+	return s.validate(tokenStr, checkJWT, checkBlacklist, nil)
+}
+
+// Token yoxlama addımının nəticəsi
+const (
+	StepPassed  = "passed"
+	StepFailed  = "failed"
+	StepSkipped = "skipped"
+)
+
+// ValidationStep explain rejimində token yoxlamasının bir addımı (jwt, blacklist, revocation)
+type ValidationStep struct {
+	Check    string
+	Status   string
+	Reason   TokenErrorReason
+	Detail   string
+	Duration time.Duration
+}
+
+// ValidateExplain Validate ilə eynidir, əlavə olaraq hər yoxlama addımının nəticəsini və müddətini qaytarır
+func (s *AuthService) ValidateExplain(tokenStr string, checkJWT, checkBlacklist bool) (*jwtutil.Claims, []ValidationStep, error) {
+	steps := []ValidationStep{}
+	claims, err := s.validate(tokenStr, checkJWT, checkBlacklist, &steps)
+	return claims, steps, err
+}
+
+func (s *AuthService) validate(tokenStr string, checkJWT, checkBlacklist bool, steps *[]ValidationStep) (*jwtutil.Claims, error) {
 	var claims jwtutil.Claims
+	record := func(check string, start time.Time, err error, detail string) {
+		if steps == nil {
+			return
+		}
+		step := ValidationStep{Check: check, Status: StepPassed, Detail: detail, Duration: time.Since(start)}
+		if err != nil {
+			step.Status, step.Reason, step.Detail = StepFailed, TokenErrorReasonOf(err), err.Error()
+		}
+		*steps = append(*steps, step)
+	}
+	skip := func(check, detail string) {
+		if steps != nil {
+			*steps = append(*steps, ValidationStep{Check: check, Status: StepSkipped, Detail: detail})
+		}
+	}
+
+	start := time.Now()
+	if checkJWT {
+		if err := s.verify(tokenStr, &claims); err != nil {
+			record("jwt", start, err, "")
+			return nil, err
+		}
+		record("jwt", start, nil, "signature, exp/nbf/iat, iss and aud verified")
+	} else if _, _, err := jwt.NewParser().ParseUnverified(tokenStr, &claims); err != nil {
+		err := &TokenError{Reason: ReasonMalformed, Err: err}
+		record("jwt", start, err, "")
+		return nil, err
+	} else {
+		skip("jwt", "signature and claims not verified (check_jwt=false)")
+	}
+
+	if !checkBlacklist {
+		skip("blacklist", "check_blacklist=false")
+		skip("revocation", "check_blacklist=false")
+		return &claims, nil
+	}
+
+	start = time.Now()
+	tokenID := jwtutil.TokenID(&claims, tokenStr)
+	if s.tokenRepo.IsBlacklisted(tokenID) {
+		err := newTokenError(ReasonBlacklisted, "token is blacklisted")
+		record("blacklist", start, err, "")
+		return nil, err
+	}
+	record("blacklist", start, nil, "token "+tokenID+" is not blacklisted")
+
+	start = time.Now()
+	if s.isRevokedForUser(&claims) {
+		err := newTokenError(ReasonRevoked, "token has been revoked")
+		record("revocation", start, err, "")
+		return nil, err
+	}
+	record("revocation", start, nil, "no logout-all cutoff applies to the token")
+
+	return &claims, nil
+}
+
+// Logout-all: cutoff anına qədər buraxılmış (iat <= cutoff) tokenlər etibarsızdır.
+// iat olmayan tokenin nə vaxt buraxıldığını bilmirik, ona görə onu da rədd edirik.
+func (s *AuthService) isRevokedForUser(claims *jwtutil.Claims) bool {
+	if claims.UserID == "" {
+		return false
+	}
+	cutoff, ok := s.tokenRepo.RevokedBefore(claims.TenantID, claims.UserID)
+	if !ok {
+		return false
+	}
+	return claims.IssuedAt == nil || claims.IssuedAt.Unix() <= cutoff
+}
+
+// İmza, iss, aud və vaxt claim-lərini yoxlayır
+func (s *AuthService) verify(tokenStr string, claims *jwtutil.Claims) error {
+	var issuer *TrustedIssuer
+
+	// Claim-ləri özümüz clock skew ilə yoxlayırıq
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	_, err := parser.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
+		iss, err := s.resolveIssuer(claims.Issuer)
+		if err != nil {
+			return nil, err
+		}
+		issuer = iss
 
-	token, err := jwt.ParseWithClaims(tokenStr, &claims, func(t *jwt.Token) (interface{}, error) {
 		kid, ok := t.Header["kid"].(string)
 		if !ok {
-			return nil, errors.New("missing kid in token header")
+			return nil, newTokenError(ReasonMissingKid, "missing kid in token header")
+		}
+
+		provider := s.publicKeyProvider
+		if issuer != nil && issuer.KeyProvider != nil {
+			provider = issuer.KeyProvider
+		}
+		key, err := provider.GetPublicKey(kid)
+		if err != nil {
+			return nil, &TokenError{Reason: ReasonUnknownKey, Err: err}
+		}
+		// alg header-inə kor-koranə güvənmirik – açar üçün icazəli olmalıdır
+		if !key.Allows(t.Method.Alg()) {
+			return nil, &TokenError{
+				Reason: ReasonAlgorithmNotAllowed,
+				Err:    fmt.Errorf("signing algorithm %s is not allowed for key %s", t.Method.Alg(), kid),
+			}
 		}
-		return s.publicKeyProvider.GetPublicKey(kid)
+		return key.Key, nil
 	})
 	if err != nil {
-		if checkJWT {
-			return nil, err
+		return toTokenError(err)
+	}
+
+	if err := s.verifyTimes(claims); err != nil {
+		return err
+	}
+
+	if issuer != nil && len(issuer.Audiences) > 0 && !audienceMatches(claims.Audience, issuer.Audiences) {
+		return newTokenError(ReasonAudienceMismatch, "token audience is not accepted")
+	}
+	return nil
+}
+
+// Issuer-lər konfiqurasiya olunmayıbsa nil qaytarır (köhnə davranış)
+func (s *AuthService) resolveIssuer(iss string) (*TrustedIssuer, error) {
+	if len(s.issuers) == 0 {
+		return nil, nil
+	}
+	issuer, ok := s.issuers[iss]
+	if !ok {
+		return nil, newTokenError(ReasonUntrustedIssuer, fmt.Sprintf("untrusted token issuer %q", iss))
+	}
+	return &issuer, nil
+}
+
+func (s *AuthService) verifyTimes(claims *jwtutil.Claims) error {
+	now := time.Now()
+
+	if claims.ExpiresAt != nil && now.After(claims.ExpiresAt.Add(s.clockSkew)) {
+		return newTokenError(ReasonExpired, "token is expired")
+	}
+	if claims.NotBefore != nil && now.Add(s.clockSkew).Before(claims.NotBefore.Time) {
+		return newTokenError(ReasonNotYetValid, "token is not valid yet")
+	}
+	if claims.IssuedAt != nil && now.Add(s.clockSkew).Before(claims.IssuedAt.Time) {
+		return newTokenError(ReasonNotYetValid, "token used before issued")
+	}
+	return nil
+}
+
+func audienceMatches(tokenAud jwt.ClaimStrings, accepted []string) bool {
+	for _, aud := range tokenAud {
+		for _, a := range accepted {
+			if aud == a {
+				return true
+			}
 		}
 	}
+	return false
+}
 
-	if !token.Valid && checkJWT {
-		return nil, errors.New("invalid token")
+// jwt kitabxanasının xətalarını TokenError-a çevirir
+func toTokenError(err error) error {
+	var tokenErr *TokenError
+	if errors.As(err, &tokenErr) {
+		return tokenErr
 	}
 
-	if checkBlacklist && s.tokenRepo.IsBlacklisted(tokenStr) {
-		return nil, errors.New("token is blacklisted")
+	var vErr *jwt.ValidationError
+	if errors.As(err, &vErr) {
+		switch {
+		case vErr.Errors&jwt.ValidationErrorMalformed != 0:
+			return &TokenError{Reason: ReasonMalformed, Err: err}
+		case vErr.Errors&jwt.ValidationErrorUnverifiable != 0:
+			// Məs. alg=none və ya dəstəklənməyən alqoritm
+			return &TokenError{Reason: ReasonAlgorithmNotAllowed, Err: err}
+		case vErr.Errors&jwt.ValidationErrorSignatureInvalid != 0:
+			return &TokenError{Reason: ReasonInvalidSignature, Err: err}
+		}
 	}
+	return &TokenError{Reason: ReasonInvalidSignature, Err: err}
+}
 
-	return &claims, nil
+// Tokeni (jti) local Blacklist cache-inə əlavə et. Event outbox-dan gəlir, qeyd DB-yə artıq yazılıb.
+func (s *AuthService) HandleBlacklistEvent(jti string, exp int64) {
+	s.tokenRepo.AddCached(jti, exp)
 }
 
-// Tokeni Blacklist-ə əlavə et (sadə)
-func (s *AuthService) HandleBlacklistEvent(token string, exp int64) {
-	s.tokenRepo.Add(token, exp)
+// Tokeni (jti) həm Blacklist-ə, həm user tracking-ə əlavə et
+func (s *AuthService) HandleBlacklistEventWithUser(jti string, exp int64, userID string, role string) {
+	s.tokenRepo.AddWithUser(jti, exp, userID, role)
+	s.tokenRepo.AddCached(jti, exp)
 }
 
-// Tokeni həm Blacklist-ə, həm user tracking-ə əlavə et
-func (s *AuthService) HandleBlacklistEventWithUser(token string, exp int64, userID string, role string) {
-	s.tokenRepo.AddWithUser(token, exp, userID, role)
-	s.tokenRepo.Add(token, exp)
+// Sistemdə aktiv olan tokeni (jti) izləməyə başla
+func (s *AuthService) AddTokenForTracking(jti string, exp int64, userID string, role string) {
+	s.tokenRepo.AddWithUser(jti, exp, userID, role)
 }
 
-// Sistemdə aktiv olan tokeni izləməyə başla
-func (s *AuthService) AddTokenForTracking(token string, exp int64, userID string, role string) {
-	s.tokenRepo.AddWithUser(token, exp, userID, role)
+// exp-siz token heç vaxt bitmir, ona görə onun blacklist qeydi bu müddət saxlanılır
+const noExpiryRevocation = 100 * 365 * 24 * time.Hour
+
+// RevocationExpiry tokenin blacklist qeydinin bitmə anını (unix) qaytarır. Verify exp-siz tokenləri qəbul edir,
+// belə tokenlər üçün uzaq gələcək götürülür.
+func RevocationExpiry(claims *jwtutil.Claims) int64 {
+	if claims.ExpiresAt == nil {
+		return time.Now().Add(noExpiryRevocation).Unix()
+	}
+	return claims.ExpiresAt.Unix()
+}
+
+// RevokeToken tokeni (jti) blacklist-ə yazır və TOKEN_BLACKLISTED event-ini eyni
+// tranzaksiyada outbox-a əlavə edir, sonra local cache-i yeniləyir.
+func (s *AuthService) RevokeToken(jti string, exp int64) error {
+	err := s.uow.Transaction(func(tx repository.UnitOfWork) error {
+		if err := tx.BlacklistRepo().Add(jti, time.Unix(exp, 0)); err != nil {
+			return err
+		}
+		return tx.OutboxRepo().Add("auth.tokens.fanout", dto.TokenBlacklistedEvent{
+			Event: dto.EventTokenBlacklisted,
+			JTI:   jti,
+			Exp:   exp,
+		}, nil)
+	})
+	if err != nil {
+		return err
+	}
+	// Blacklist qeydi tranzaksiyada yazılıb, təkrar DB yazısı lazım deyil
+	s.tokenRepo.AddCached(jti, exp)
+	return nil
+}
+
+// RevokeAllForUser tenant-ın istifadəçisinin indiyə qədər buraxılmış bütün tokenlərini ləğv edir.
+// Cutoff və TOKEN_BLACKLISTED_ALL event-i (outbox) eyni tranzaksiyada yazılır.
+func (s *AuthService) RevokeAllForUser(tenantID, userID string, revokedBefore time.Time) error {
+	err := s.uow.Transaction(func(tx repository.UnitOfWork) error {
+		if err := tx.RevocationRepo().Upsert(tenantID, userID, revokedBefore); err != nil {
+			return err
+		}
+		return tx.OutboxRepo().Add("auth.tokens.fanout", dto.TokenBlacklistedAllEvent{
+			Event:         dto.EventTokenBlacklistedAll,
+			TenantID:      tenantID,
+			UserID:        userID,
+			RevokedBefore: revokedBefore.Unix(),
+		}, []string{
+			"blacklist.cache.queue",
+			"blacklist.audit.queue",
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.tokenRepo.SetRevokedBefore(tenantID, userID, revokedBefore.Unix())
+	return nil
+}
+
+// TOKEN_BLACKLISTED_ALL event-i gəldikdə (DB-yə artıq yazılıb)
+func (s *AuthService) HandleRevokeAllEvent(tenantID, userID string, revokedBefore int64) {
+	s.tokenRepo.SetRevokedBefore(tenantID, userID, revokedBefore)
+}
+
+// Startup zamanı bütün cutoff-ları DB-dən yaddaşa yükləyir
+func (s *AuthService) LoadRevocations() error {
+	revs, err := s.uow.RevocationRepo().GetAll()
+	if err != nil {
+		return err
+	}
+	for _, rev := range revs {
+		s.tokenRepo.SetRevokedBefore(rev.TenantID, rev.UserID, rev.RevokedBefore.Unix())
+	}
+	log.Printf("✅ %d user revocation cutoff(s) loaded", len(revs))
+	return nil
 }
 
 // İstifadəçiyə aid bütün tokenləri al (admin panel və ya audit üçün)
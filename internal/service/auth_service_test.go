@@ -0,0 +1,486 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gorm.io/gorm"
+	"ms-authz/internal/domain/model"
+	"ms-authz/internal/domain/repository"
+	"ms-authz/internal/infrastructure/cache"
+	"ms-authz/pkg/jwtutil"
+)
+
+type staticKeyProvider map[string]*jwtutil.VerificationKey
+
+func (p staticKeyProvider) GetPublicKey(kid string) (*jwtutil.VerificationKey, error) {
+	if key, ok := p[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown kid %q", kid)
+}
+
+// fakeUoW yalnız testdə lazım olan repository-ləri təmin edir
+type fakeUoW struct {
+	repository.UnitOfWork
+	revocations *fakeRevocationRepo
+	outbox      *fakeOutboxRepo
+	roles       *fakeRoleRepo
+	users       *fakeUserRepo
+	permissions *fakePermissionRepo
+	routes      *fakeRouteRepo
+	blacklist   *fakeBlacklistRepo
+	inTx        bool // Transaction-un callback-i icra olunur
+}
+
+func (u *fakeUoW) UserRepo() repository.UserRepository { return u.users }
+
+func (u *fakeUoW) RoleRepo() repository.RoleRepository { return u.roles }
+func (u *fakeUoW) PermissionRepo() repository.PermissionRepository {
+	if u.permissions == nil {
+		return &fakePermissionRepo{}
+	}
+	return u.permissions
+}
+func (u *fakeUoW) RouteRepo() repository.RouteRepository {
+	if u.routes == nil {
+		return &fakeRouteRepo{}
+	}
+	return u.routes
+}
+func (u *fakeUoW) RolePermissionRepo() repository.RolePermissionRepository {
+	return &fakeRolePermissionRepo{roles: u.roles}
+}
+
+func (u *fakeUoW) RevocationRepo() repository.RevocationRepository { return u.revocations }
+func (u *fakeUoW) OutboxRepo() repository.OutboxRepository         { return u.outbox }
+func (u *fakeUoW) BlacklistRepo() repository.BlacklistRepository   { return u.blacklist }
+
+func (u *fakeUoW) Transaction(fn func(tx repository.UnitOfWork) error) error {
+	u.inTx = true
+	defer func() { u.inTx = false }()
+	return fn(u)
+}
+
+type fakeOutboxRepo struct {
+	events []model.OutboxEvent
+}
+
+func (r *fakeOutboxRepo) Add(exchange string, payload any, passiveQueues []string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	r.events = append(r.events, model.OutboxEvent{
+		Model:         gorm.Model{ID: uint(len(r.events) + 1)},
+		Exchange:      exchange,
+		Payload:       string(body),
+		PassiveQueues: strings.Join(passiveQueues, ","),
+	})
+	return nil
+}
+
+func (r *fakeOutboxRepo) Claim(limit int, now, lockedUntil time.Time) ([]model.OutboxEvent, error) {
+	var claimed []model.OutboxEvent
+	for i, e := range r.events {
+		if e.SentAt != nil || e.ParkedAt != nil || (e.LockedUntil != nil && !e.LockedUntil.Before(now)) || len(claimed) == limit {
+			continue
+		}
+		r.events[i].LockedUntil = &lockedUntil
+		claimed = append(claimed, r.events[i])
+	}
+	return claimed, nil
+}
+
+func (r *fakeOutboxRepo) Release(ids []uint) error {
+	for _, id := range ids {
+		r.events[id-1].LockedUntil = nil
+	}
+	return nil
+}
+
+func (r *fakeOutboxRepo) MarkSent(id uint, sentAt time.Time) error {
+	r.events[id-1].SentAt = &sentAt
+	r.events[id-1].LockedUntil = nil
+	return nil
+}
+
+func (r *fakeOutboxRepo) MarkFailed(id uint, cause error) error {
+	r.events[id-1].Attempts++
+	r.events[id-1].LastError = cause.Error()
+	r.events[id-1].LockedUntil = nil
+	return nil
+}
+
+func (r *fakeOutboxRepo) Park(id uint, parkedAt time.Time, cause error) error {
+	r.events[id-1].Attempts++
+	r.events[id-1].LastError = cause.Error()
+	r.events[id-1].ParkedAt = &parkedAt
+	r.events[id-1].LockedUntil = nil
+	return nil
+}
+
+func (r *fakeOutboxRepo) DeleteSentBefore(before time.Time) error {
+	return nil
+}
+
+type fakeBlacklistRepo struct {
+	repository.BlacklistRepository
+	rows map[string]time.Time
+}
+
+func (r *fakeBlacklistRepo) Add(jti string, expiresAt time.Time) error {
+	r.rows[jti] = expiresAt
+	return nil
+}
+
+type fakeRevocationRepo struct {
+	rows map[[2]string]time.Time // {tenant, user}
+}
+
+func (r *fakeRevocationRepo) GetByUserID(tenantID, userID string) (*model.UserRevocation, error) {
+	return &model.UserRevocation{TenantID: tenantID, UserID: userID, RevokedBefore: r.rows[[2]string{tenantID, userID}]}, nil
+}
+
+func (r *fakeRevocationRepo) GetAll() ([]model.UserRevocation, error) {
+	var revs []model.UserRevocation
+	for key, ts := range r.rows {
+		revs = append(revs, model.UserRevocation{TenantID: key[0], UserID: key[1], RevokedBefore: ts})
+	}
+	return revs, nil
+}
+
+func (r *fakeRevocationRepo) Upsert(tenantID, userID string, revokedBefore time.Time) error {
+	r.rows[[2]string{tenantID, userID}] = revokedBefore
+	return nil
+}
+
+func mustVerificationKey(t *testing.T, pub crypto.PublicKey, algs ...string) *jwtutil.VerificationKey {
+	t.Helper()
+	key, err := jwtutil.NewVerificationKey(pub, algs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key crypto.PrivateKey, claims jwtutil.Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestAuthService_Validate_Algorithms(t *testing.T) {
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	edPub, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	provider := staticKeyProvider{
+		"es256": mustVerificationKey(t, &p256.PublicKey),
+		"es384": mustVerificationKey(t, &p384.PublicKey),
+		"ed":    mustVerificationKey(t, edPub),
+	}
+	svc := NewAuthService(nil, cache.NewTokenRepository(), provider, AuthConfig{})
+
+	claims := jwtutil.Claims{
+		UserID: "7",
+		Role:   "support",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"ES256", signToken(t, jwt.SigningMethodES256, "es256", p256, claims), false},
+		{"ES384", signToken(t, jwt.SigningMethodES384, "es384", p384, claims), false},
+		{"EdDSA", signToken(t, jwt.SigningMethodEdDSA, "ed", edPriv, claims), false},
+		{"wrong kid for algorithm", signToken(t, jwt.SigningMethodES256, "es384", p256, claims), true},
+		{"unknown kid", signToken(t, jwt.SigningMethodES256, "missing", p256, claims), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.Validate(tt.token, true, true)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.UserID != "7" {
+				t.Errorf("Validate() user_id = %q, want 7", got.UserID)
+			}
+		})
+	}
+}
+
+func TestAuthService_Validate_Claims(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	svc := NewAuthService(nil, cache.NewTokenRepository(), staticKeyProvider{
+		"default": mustVerificationKey(t, &key.PublicKey),
+	}, AuthConfig{
+		Issuers: []TrustedIssuer{
+			{Issuer: "https://idp.example.com", Audiences: []string{"ms-authz"}},
+			{Issuer: "partner", KeyProvider: staticKeyProvider{
+				"default": mustVerificationKey(t, &otherKey.PublicKey),
+			}},
+		},
+		ClockSkew: 30 * time.Second,
+	})
+
+	now := time.Now()
+	claims := func(iss string, aud []string, exp, nbf time.Time) jwtutil.Claims {
+		return jwtutil.Claims{
+			UserID: "7",
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    iss,
+				Audience:  aud,
+				ExpiresAt: jwt.NewNumericDate(exp),
+				NotBefore: jwt.NewNumericDate(nbf),
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		signKey    *ecdsa.PrivateKey
+		claims     jwtutil.Claims
+		wantReason TokenErrorReason
+	}{
+		{"valid", key, claims("https://idp.example.com", []string{"ms-authz"}, now.Add(time.Hour), now), ""},
+		{"expired within skew", key, claims("https://idp.example.com", []string{"ms-authz"}, now.Add(-10*time.Second), now.Add(-time.Hour)), ""},
+		{"expired", key, claims("https://idp.example.com", []string{"ms-authz"}, now.Add(-time.Minute), now.Add(-time.Hour)), ReasonExpired},
+		{"nbf within skew", key, claims("https://idp.example.com", []string{"ms-authz"}, now.Add(time.Hour), now.Add(10*time.Second)), ""},
+		{"not yet valid", key, claims("https://idp.example.com", []string{"ms-authz"}, now.Add(time.Hour), now.Add(time.Minute)), ReasonNotYetValid},
+		{"untrusted issuer", key, claims("https://evil.example.com", []string{"ms-authz"}, now.Add(time.Hour), now), ReasonUntrustedIssuer},
+		{"audience mismatch", key, claims("https://idp.example.com", []string{"billing"}, now.Add(time.Hour), now), ReasonAudienceMismatch},
+		{"issuer specific key", otherKey, claims("partner", nil, now.Add(time.Hour), now), ""},
+		{"issuer specific key mismatch", key, claims("partner", nil, now.Add(time.Hour), now), ReasonInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := signToken(t, jwt.SigningMethodES256, "default", tt.signKey, tt.claims)
+			_, err := svc.Validate(token, true, true)
+			if got := TokenErrorReasonOf(err); got != tt.wantReason {
+				t.Errorf("Validate() reason = %q, want %q (err = %v)", got, tt.wantReason, err)
+			}
+			if tt.wantReason == "" && err != nil {
+				t.Errorf("Validate() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthService_Validate_Malformed(t *testing.T) {
+	svc := NewAuthService(nil, cache.NewTokenRepository(), staticKeyProvider{}, AuthConfig{})
+	for _, checkJWT := range []bool{true, false} {
+		_, err := svc.Validate("not-a-jwt", checkJWT, true)
+		if got := TokenErrorReasonOf(err); got != ReasonMalformed {
+			t.Errorf("Validate(checkJWT=%v) reason = %q, want %q", checkJWT, got, ReasonMalformed)
+		}
+	}
+}
+
+func TestAuthService_Validate_BlacklistByJTI(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	repo := cache.NewTokenRepository()
+	svc := NewAuthService(nil, repo, staticKeyProvider{
+		"k": mustVerificationKey(t, &key.PublicKey),
+	}, AuthConfig{})
+
+	exp := time.Now().Add(time.Hour)
+	withJTI := signToken(t, jwt.SigningMethodES256, "k", key, jwtutil.Claims{
+		UserID:           "7",
+		RegisteredClaims: jwt.RegisteredClaims{ID: "jti-1", ExpiresAt: jwt.NewNumericDate(exp)},
+	})
+	withoutJTI := signToken(t, jwt.SigningMethodES256, "k", key, jwtutil.Claims{
+		UserID:           "7",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(exp)},
+	})
+
+	svc.HandleBlacklistEvent("jti-1", exp.Unix())
+	svc.HandleBlacklistEvent(jwtutil.TokenID(nil, withoutJTI), exp.Unix())
+
+	for name, token := range map[string]string{"jti": withJTI, "hash fallback": withoutJTI} {
+		_, err := svc.Validate(token, true, true)
+		if got := TokenErrorReasonOf(err); got != ReasonBlacklisted {
+			t.Errorf("%s: Validate() reason = %q, want %q", name, got, ReasonBlacklisted)
+		}
+		if repo.IsBlacklisted(token) {
+			t.Errorf("%s: raw token must not be used as blacklist key", name)
+		}
+	}
+}
+
+func TestAuthService_RevokeTokenWithoutExp(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	uow := &fakeUoW{blacklist: &fakeBlacklistRepo{rows: map[string]time.Time{}}, outbox: &fakeOutboxRepo{}}
+	svc := NewAuthService(uow, cache.NewTokenRepository(), staticKeyProvider{
+		"k": mustVerificationKey(t, &key.PublicKey),
+	}, AuthConfig{})
+
+	token := signToken(t, jwt.SigningMethodES256, "k", key, jwtutil.Claims{UserID: "7"})
+	claims, err := svc.Validate(token, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := RevocationExpiry(claims)
+	if exp <= time.Now().Add(time.Hour).Unix() {
+		t.Fatalf("RevocationExpiry() = %d, want far future for a token without exp", exp)
+	}
+	if err := svc.RevokeToken(jwtutil.TokenID(claims, token), exp); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Validate(token, true, true); TokenErrorReasonOf(err) != ReasonBlacklisted {
+		t.Errorf("Validate() after revoke = %v, want blacklisted", err)
+	}
+}
+
+func TestAuthService_ValidateExplain(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	svc := NewAuthService(nil, cache.NewTokenRepository(), staticKeyProvider{
+		"k": mustVerificationKey(t, &key.PublicKey),
+	}, AuthConfig{})
+
+	exp := time.Now().Add(time.Hour)
+	valid := signToken(t, jwt.SigningMethodES256, "k", key, jwtutil.Claims{
+		UserID:           "7",
+		RegisteredClaims: jwt.RegisteredClaims{ID: "jti-1", ExpiresAt: jwt.NewNumericDate(exp)},
+	})
+	expired := signToken(t, jwt.SigningMethodES256, "k", key, jwtutil.Claims{
+		UserID:           "7",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour))},
+	})
+	blacklisted := signToken(t, jwt.SigningMethodES256, "k", key, jwtutil.Claims{
+		UserID:           "7",
+		RegisteredClaims: jwt.RegisteredClaims{ID: "jti-2", ExpiresAt: jwt.NewNumericDate(exp)},
+	})
+	svc.HandleBlacklistEvent("jti-2", exp.Unix())
+
+	tests := []struct {
+		name           string
+		token          string
+		checkJWT       bool
+		checkBlacklist bool
+		want           []string // check:status
+	}{
+		{"all passed", valid, true, true, []string{"jwt:passed", "blacklist:passed", "revocation:passed"}},
+		{"expired", expired, true, true, []string{"jwt:failed"}},
+		{"blacklisted", blacklisted, true, true, []string{"jwt:passed", "blacklist:failed"}},
+		{"checks disabled", blacklisted, false, false, []string{"jwt:skipped", "blacklist:skipped", "revocation:skipped"}},
+	}
+	for _, tt := range tests {
+		_, steps, err := svc.ValidateExplain(tt.token, tt.checkJWT, tt.checkBlacklist)
+		var got []string
+		for _, step := range steps {
+			got = append(got, step.Check+":"+step.Status)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ValidateExplain() steps = %v, want %v", tt.name, got, tt.want)
+		}
+		if failed := steps[len(steps)-1].Status == StepFailed; failed != (err != nil) {
+			t.Errorf("%s: ValidateExplain() error = %v, last step = %+v", tt.name, err, steps[len(steps)-1])
+		}
+		if err != nil && steps[len(steps)-1].Reason != TokenErrorReasonOf(err) {
+			t.Errorf("%s: step reason = %q, want %q", tt.name, steps[len(steps)-1].Reason, TokenErrorReasonOf(err))
+		}
+	}
+}
+
+func TestAuthService_RevokeAllForUser(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	provider := staticKeyProvider{"k": mustVerificationKey(t, &key.PublicKey)}
+	uow := &fakeUoW{
+		revocations: &fakeRevocationRepo{rows: map[[2]string]time.Time{}},
+		outbox:      &fakeOutboxRepo{},
+	}
+	svc := NewAuthService(uow, cache.NewTokenRepository(), provider, AuthConfig{})
+
+	now := time.Now()
+	token := func(tenantID, userID string, iat *jwt.NumericDate) string {
+		return signToken(t, jwt.SigningMethodES256, "k", key, jwtutil.Claims{
+			TenantID: tenantID,
+			UserID:   userID,
+			RegisteredClaims: jwt.RegisteredClaims{
+				IssuedAt:  iat,
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			},
+		})
+	}
+	oldToken := token("acme", "7", jwt.NewNumericDate(now.Add(-time.Minute)))
+	noIat := token("acme", "7", nil)
+	otherUser := token("acme", "8", jwt.NewNumericDate(now.Add(-time.Minute)))
+	otherTenant := token("globex", "7", jwt.NewNumericDate(now.Add(-time.Minute)))
+
+	cutoff := now.Add(-2 * time.Second)
+	if err := svc.RevokeAllForUser("acme", "7", cutoff); err != nil {
+		t.Fatal(err)
+	}
+	newToken := token("acme", "7", jwt.NewNumericDate(now))
+
+	// Event cutoff ilə eyni tranzaksiyada outbox-a yazılmalıdır
+	if len(uow.outbox.events) != 1 || uow.outbox.events[0].Exchange != "auth.tokens.fanout" {
+		t.Fatalf("outbox = %+v, want one auth.tokens.fanout event", uow.outbox.events)
+	}
+
+	tests := []struct {
+		name       string
+		svc        *AuthService
+		token      string
+		wantReason TokenErrorReason
+	}{
+		{"issued before cutoff", svc, oldToken, ReasonRevoked},
+		{"missing iat", svc, noIat, ReasonRevoked},
+		{"issued after cutoff", svc, newToken, ""},
+		{"other user", svc, otherUser, ""},
+		{"same user id in other tenant", svc, otherTenant, ""},
+	}
+
+	// Yeni instansiya cutoff-ları DB-dən yükləyir
+	restarted := NewAuthService(uow, cache.NewTokenRepository(), provider, AuthConfig{})
+	if err := restarted.LoadRevocations(); err != nil {
+		t.Fatal(err)
+	}
+	tests = append(tests, struct {
+		name       string
+		svc        *AuthService
+		token      string
+		wantReason TokenErrorReason
+	}{"after restart", restarted, oldToken, ReasonRevoked})
+
+	// Digər instansiya event vasitəsilə öyrənir
+	peer := NewAuthService(uow, cache.NewTokenRepository(), provider, AuthConfig{})
+	peer.HandleRevokeAllEvent("acme", "7", cutoff.Unix())
+	tests = append(tests, struct {
+		name       string
+		svc        *AuthService
+		token      string
+		wantReason TokenErrorReason
+	}{"peer via event", peer, oldToken, ReasonRevoked})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.svc.Validate(tt.token, true, true)
+			if got := TokenErrorReasonOf(err); got != tt.wantReason {
+				t.Errorf("Validate() reason = %q, want %q (err = %v)", got, tt.wantReason, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"ms-authz/pkg/condition"
+	"ms-authz/pkg/jwtutil"
+	"time"
+)
+
+// Şərt kontekstində server tərəfindən təyin olunan açarlar – sorğunun kontekstindən götürülmür
+var reservedEnvKeys = map[string]bool{"claims": true, "ip": true, "time": true}
+
+// ConditionEnv şərtlərin qiymətləndirildiyi konteksti qurur: müştərinin kontekstindən yalnız
+// rezerv olunmamış açarlar (məs. resource), claims tokendən, ip və time isə serverdən götürülür.
+// Beləliklə IP və vaxt şərtləri müştəri tərəfindən saxtalaşdırıla bilməz.
+func ConditionEnv(claims *jwtutil.Claims, ctx map[string]any, ip string, now time.Time) condition.Env {
+	env := make(condition.Env, len(ctx)+len(reservedEnvKeys))
+	for k, v := range ctx {
+		if !reservedEnvKeys[k] {
+			env[k] = v
+		}
+	}
+	env["claims"] = claims.Raw
+	env["ip"] = ip
+	env["time"] = now
+	return env
+}
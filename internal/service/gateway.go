@@ -0,0 +1,130 @@
+package service
+
+import (
+	"fmt"
+	"ms-authz/pkg/jwtutil"
+	"ms-authz/pkg/route"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// İcazə verilmiş sorğu ilə upstream-ə ötürülən identity header-ləri
+const (
+	IdentityHeaderUserID   = "x-user-id"
+	IdentityHeaderUserRole = "x-user-role"
+	IdentityHeaderTenantID = "x-tenant-id"
+)
+
+var IdentityHeaders = []string{IdentityHeaderUserID, IdentityHeaderUserRole, IdentityHeaderTenantID}
+
+// GatewayRequest proxy-nin (Envoy, Traefik, nginx) qoruduğu orijinal sorğu
+type GatewayRequest struct {
+	Service       string // route reyestrindəki servis, boşdursa GatewayAuthorizer-in default servisi
+	Method        string
+	Path          string // query string ola bilər, nəzərə alınmır
+	Authorization string // Authorization header-i
+	ClientIP      string
+}
+
+// GatewayDecision proxy üçün qərar: HTTP statusu (200, 401, 403) və icazə verildikdə identity
+type GatewayDecision struct {
+	Status int
+	Error  string
+	Reason TokenErrorReason
+	Rule   route.Rule
+	Claims *jwtutil.Claims // public route-da nil
+
+	Permission string // rədd səbəbi olan permission (varsa)
+}
+
+func (d GatewayDecision) Allowed() bool {
+	return d.Status == http.StatusOK
+}
+
+// Identity upstream-ə əlavə olunacaq header-lər. Boş dəyərli header-lər müştəri tərəfindən
+// saxtalaşdırılmasın deyə silinməlidir.
+func (d GatewayDecision) Identity() map[string]string {
+	headers := make(map[string]string, len(IdentityHeaders))
+	for _, h := range IdentityHeaders {
+		headers[h] = ""
+	}
+	if d.Claims != nil {
+		headers[IdentityHeaderUserID] = d.Claims.UserID
+		headers[IdentityHeaderUserRole] = d.Claims.Role
+		headers[IdentityHeaderTenantID] = d.Claims.TenantID
+	}
+	return headers
+}
+
+// GatewayAuthorizer route reyestri (RBACService.ResolveRoute) əsasında proxy sorğularına qərar verir: metod və path
+// tələb olunan permission-lara çevrilir, token Authorize-dakı kimi (JWT + blacklist) yoxlanılır, permission-lar bütün
+// rollar üzrə həll olunur. Token-siz yalnız default tenant-ın public route-ları keçir; token olduqda route /check-dəki
+// kimi token-in tenant-ının (claims.TenantID) reyestrindən götürülür.
+type GatewayAuthorizer struct {
+	auth    *AuthService
+	rbac    *RBACService
+	service string
+}
+
+func NewGatewayAuthorizer(auth *AuthService, rbac *RBACService, defaultService string) *GatewayAuthorizer {
+	return &GatewayAuthorizer{
+		auth:    auth,
+		rbac:    rbac,
+		service: defaultService,
+	}
+}
+
+// Authorize reyestrdə olmayan sorğuları rədd edir
+func (g *GatewayAuthorizer) Authorize(req GatewayRequest) GatewayDecision {
+	svc := req.Service
+	if svc == "" {
+		svc = g.service
+	}
+	// Match path-ı özü də normallaşdırır; burada yalnız rəddin səbəbini aydın qaytarmaq üçün yoxlanılır
+	if _, err := route.CleanPath(req.Path); err != nil {
+		return GatewayDecision{Status: http.StatusForbidden, Error: err.Error()}
+	}
+	noRoute := strings.TrimSpace(fmt.Sprintf("no route rule for %s %s %s", svc, req.Method, req.Path))
+
+	// Token-dən əvvəl tenant məlum deyil, public route-lar default tenant-dan götürülür
+	rule, ok := g.rbac.ResolveRoute("", svc, req.Method, req.Path)
+	if ok && rule.Public {
+		return GatewayDecision{Status: http.StatusOK, Rule: rule}
+	}
+
+	if !strings.HasPrefix(req.Authorization, "Bearer ") {
+		if !ok {
+			return GatewayDecision{Status: http.StatusForbidden, Error: noRoute}
+		}
+		return GatewayDecision{Status: http.StatusUnauthorized, Rule: rule, Error: "Missing or invalid Authorization header"}
+	}
+	token := strings.TrimPrefix(req.Authorization, "Bearer ")
+
+	claims, err := g.auth.Validate(token, true, true)
+	if err != nil {
+		return GatewayDecision{Status: http.StatusUnauthorized, Rule: rule, Error: err.Error(), Reason: TokenErrorReasonOf(err)}
+	}
+	if claims.UserID != "" && claims.ExpiresAt != nil {
+		g.auth.AddTokenForTracking(jwtutil.TokenID(claims, token), claims.ExpiresAt.Unix(), claims.UserID, claims.Role)
+	}
+
+	// Tokenli sorğu /check-dəki kimi token-in tenant-ının reyestri ilə həll olunur
+	if claims.TenantID != "" {
+		rule, ok = g.rbac.ResolveRoute(claims.TenantID, svc, req.Method, req.Path)
+	}
+	if !ok {
+		return GatewayDecision{Status: http.StatusForbidden, Claims: claims, Error: noRoute}
+	}
+
+	if required := rule.Required(); len(required) > 0 {
+		roles := g.rbac.RolesForUser(claims.TenantID, claims.UserID, claims.AllRoles())
+		env := ConditionEnv(claims, nil, req.ClientIP, time.Now())
+		for _, perm := range required {
+			if d := g.rbac.Decide(claims.TenantID, roles, perm, Resource{}, env); !d.Allowed {
+				return GatewayDecision{Status: http.StatusForbidden, Rule: rule, Claims: claims, Permission: perm, Error: "Permission denied: " + perm}
+			}
+		}
+	}
+	return GatewayDecision{Status: http.StatusOK, Rule: rule, Claims: claims}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"ms-authz/internal/domain/model"
+	"ms-authz/internal/infrastructure/cache"
+	"ms-authz/pkg/jwtutil"
+	"ms-authz/pkg/route"
+)
+
+func TestGatewayAuthorizer_Authorize(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	auth := NewAuthService(nil, cache.NewTokenRepository(), staticKeyProvider{
+		"default": mustVerificationKey(t, &key.PublicKey),
+	}, AuthConfig{})
+
+	roles := newFakeRoleRepo("support")
+	roles.perms[1] = []string{"orders:read"}
+	roles.scoped[1] = []model.RolePermission{
+		{Effect: "allow", Condition: "ip in 10.0.0.0/8", Permission: model.Permission{Name: "reports:read"}},
+	}
+	routes := &fakeRouteRepo{routes: []model.Route{
+		{Service: "api", Method: "GET", Path: "/healthz", Public: true},
+		{Service: "api", Method: "GET", Path: "/api/orders/**", Permissions: []string{"orders:read"}},
+		{Service: "api", Method: "DELETE", Path: "/api/orders/*", Permissions: []string{"orders:delete"}},
+		{Service: "api", Method: "GET", Path: "/api/reports", Permissions: []string{"reports:read"}},
+		{Service: "api", Method: route.AnyMethod, Path: "/api/me"},
+		{Service: "billing", Method: "GET", Path: "/invoices/*", Permissions: []string{"orders:read"}},
+		{TenantID: "acme", Service: "api", Method: "GET", Path: "/api/users", Public: true},
+	}}
+	rbac := NewRBACService(&fakeUoW{roles: roles, routes: routes})
+	gw := NewGatewayAuthorizer(auth, rbac, "api")
+
+	valid := "Bearer " + signToken(t, jwt.SigningMethodES256, "default", key, jwtutil.Claims{
+		UserID:           "42",
+		Role:             "support",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	})
+	acme := "Bearer " + signToken(t, jwt.SigningMethodES256, "default", key, jwtutil.Claims{
+		UserID:           "42",
+		TenantID:         "acme",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	})
+	expired := "Bearer " + signToken(t, jwt.SigningMethodES256, "default", key, jwtutil.Claims{
+		UserID:           "42",
+		Role:             "support",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour))},
+	})
+
+	tests := []struct {
+		name       string
+		req        GatewayRequest
+		wantStatus int
+		wantReason TokenErrorReason
+		wantUserID string
+	}{
+		{"public route", GatewayRequest{Method: "GET", Path: "/healthz"}, http.StatusOK, "", ""},
+		{"permission granted", GatewayRequest{Method: "GET", Path: "/api/orders/7?expand=items", Authorization: valid}, http.StatusOK, "", "42"},
+		{"token only route", GatewayRequest{Method: "POST", Path: "/api/me", Authorization: valid}, http.StatusOK, "", "42"},
+		{"condition on client ip", GatewayRequest{Method: "GET", Path: "/api/reports", Authorization: valid, ClientIP: "10.1.2.3"}, http.StatusOK, "", "42"},
+		{"condition fails for client ip", GatewayRequest{Method: "GET", Path: "/api/reports", Authorization: valid, ClientIP: "192.168.1.1"}, http.StatusForbidden, "", ""},
+		{"permission missing", GatewayRequest{Method: "DELETE", Path: "/api/orders/7", Authorization: valid}, http.StatusForbidden, "", ""},
+		{"no token", GatewayRequest{Method: "GET", Path: "/api/orders/7"}, http.StatusUnauthorized, "", ""},
+		{"expired token", GatewayRequest{Method: "GET", Path: "/api/orders/7", Authorization: expired}, http.StatusUnauthorized, ReasonExpired, ""},
+		{"unmapped route", GatewayRequest{Method: "GET", Path: "/api/users", Authorization: valid}, http.StatusForbidden, "", ""},
+		{"explicit service", GatewayRequest{Service: "billing", Method: "GET", Path: "/invoices/3", Authorization: valid}, http.StatusOK, "", "42"},
+		{"traversal to protected route", GatewayRequest{Method: "GET", Path: "/healthz/%2e%2e/api/orders/7"}, http.StatusUnauthorized, "", ""},
+		{"double encoded traversal", GatewayRequest{Method: "GET", Path: "/healthz/%252e%252e/api/users"}, http.StatusForbidden, "", ""},
+		{"route of another service", GatewayRequest{Method: "GET", Path: "/invoices/3", Authorization: valid}, http.StatusForbidden, "", ""},
+		{"tenant route with tenant token", GatewayRequest{Method: "GET", Path: "/api/users", Authorization: acme}, http.StatusOK, "", "42"},
+		{"tenant route without token", GatewayRequest{Method: "GET", Path: "/api/users"}, http.StatusForbidden, "", ""},
+		{"default route with tenant token", GatewayRequest{Method: "GET", Path: "/api/orders/7", Authorization: acme}, http.StatusForbidden, "", ""},
+		{"default public route with tenant token", GatewayRequest{Method: "GET", Path: "/healthz", Authorization: acme}, http.StatusOK, "", ""},
+	}
+	for _, tt := range tests {
+		d := gw.Authorize(tt.req)
+		if d.Status != tt.wantStatus || d.Reason != tt.wantReason {
+			t.Errorf("%s: Authorize() = %d/%q (%s), want %d/%q", tt.name, d.Status, d.Reason, d.Error, tt.wantStatus, tt.wantReason)
+			continue
+		}
+		if !d.Allowed() {
+			continue
+		}
+		identity := d.Identity()
+		if identity[IdentityHeaderUserID] != tt.wantUserID {
+			t.Errorf("%s: %s = %q, want %q", tt.name, IdentityHeaderUserID, identity[IdentityHeaderUserID], tt.wantUserID)
+		}
+		if _, ok := identity[IdentityHeaderTenantID]; !ok {
+			t.Errorf("%s: identity %v has no %s entry", tt.name, identity, IdentityHeaderTenantID)
+		}
+	}
+}
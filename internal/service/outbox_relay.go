@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"ms-authz/internal/domain/model"
+	"ms-authz/internal/domain/repository"
+	"ms-authz/internal/infrastructure/mq"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	outboxBatchSize = 100
+	outboxRetention = 24 * time.Hour // göndərilmiş event-lər bu müddətdən sonra silinir
+	// Bu qədər uğursuz cəhddən sonra event park edilir ki, həmişə rədd olunan (məs. unroutable) event
+	// sonrakı RBAC və blacklist event-lərini əbədi saxlamasın
+	outboxMaxAttempts = 10
+	// Götürülmüş batch bu müddətdə publish olunmalıdır; relay çökərsə event-lər lease bitdikdən sonra yenidən götürülür
+	outboxLease = 2 * time.Minute
+)
+
+// OutboxRelay outbox cədvəlindəki göndərilməmiş event-ləri RabbitMQ-ya ötürür.
+// Event yalnız broker təsdiqlədikdən sonra sent kimi işarələnir (at-least-once),
+// ona görə consumer-lər eyni event-i təkrar ala bilər.
+type OutboxRelay struct {
+	uow       repository.UnitOfWork
+	publisher mq.Publisher
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewOutboxRelay(uow repository.UnitOfWork, publisher mq.Publisher) *OutboxRelay {
+	return &OutboxRelay{
+		uow:       uow,
+		publisher: publisher,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start outbox-u interval ilə yoxlayan goroutine-i işə salır
+func (r *OutboxRelay) Start(interval time.Duration) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		lastCleanup := time.Now()
+
+		for {
+			select {
+			case <-ticker.C:
+				// Batch dolu idisə gözləmədən növbətini göndəririk
+				for {
+					n, err := r.RelayPending()
+					if err != nil {
+						log.Println("❌ outbox relay failed:", err)
+					}
+					if err != nil || n < outboxBatchSize {
+						break
+					}
+				}
+
+				if time.Since(lastCleanup) > time.Hour {
+					if err := r.uow.OutboxRepo().DeleteSentBefore(time.Now().Add(-outboxRetention)); err != nil {
+						log.Println("❌ outbox cleanup failed:", err)
+					}
+					lastCleanup = time.Now()
+				}
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (r *OutboxRelay) Stop() {
+	close(r.stop)
+	r.wg.Wait()
+}
+
+// RelayPending bir batch göndərilməmiş event-i sıra ilə publish edir və göndərilənlərin sayını qaytarır.
+// Event-lər qısa tranzaksiyada outboxLease müddətinə götürülür, publish tranzaksiyadan kənarda edilir və
+// nəticələr ikinci qısa tranzaksiyada yazılır – yavaş broker sətir kilidlərini və DB bağlantısını saxlamır.
+// Publish uğursuz olarsa sıranı pozmamaq üçün batch dayandırılır, qalanların lease-i götürülür və onlar növbəti
+// dövrədə göndərilir. outboxMaxAttempts-ə çatan event park edilir və batch ondan sonrakı event-lərlə davam edir.
+func (r *OutboxRelay) RelayPending() (int, error) {
+	now := time.Now()
+	lockedUntil := now.Add(outboxLease)
+	var events []model.OutboxEvent
+	err := r.uow.Transaction(func(tx repository.UnitOfWork) error {
+		var err error
+		events, err = tx.OutboxRepo().Claim(outboxBatchSize, now, lockedUntil)
+		return err
+	})
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	// Lease bitdikdən sonra event başqa relay-ə düşə bilər, ona görə publish lease ilə məhdudlaşdırılır
+	ctx, cancel := context.WithDeadline(context.Background(), lockedUntil)
+	defer cancel()
+
+	var sent, parked []outboxResult
+	var failed *outboxResult
+	var released []uint
+	for i, e := range events {
+		var queues []string
+		if e.PassiveQueues != "" {
+			queues = strings.Split(e.PassiveQueues, ",")
+		}
+
+		err := r.publisher.PublishEventContext(ctx, e.Exchange, json.RawMessage(e.Payload), queues)
+		switch {
+		case err == nil:
+			sent = append(sent, outboxResult{id: e.ID, at: time.Now()})
+			continue
+		case ctx.Err() != nil:
+			// Lease bitib – event-in günahı deyil, cəhd sayılmır
+			log.Printf("❌ outbox lease expired before event %d was published: %v", e.ID, err)
+			released = append(released, e.ID)
+		case e.Attempts+1 >= outboxMaxAttempts:
+			log.Printf("❌ outbox event %d parked after %d attempts: %v", e.ID, e.Attempts+1, err)
+			parked = append(parked, outboxResult{id: e.ID, at: time.Now(), err: err})
+			continue
+		default:
+			log.Printf("❌ outbox event %d not published: %v", e.ID, err)
+			failed = &outboxResult{id: e.ID, err: err}
+		}
+		for _, rest := range events[i+1:] {
+			released = append(released, rest.ID)
+		}
+		break
+	}
+
+	err = r.uow.Transaction(func(tx repository.UnitOfWork) error {
+		for _, res := range sent {
+			if err := tx.OutboxRepo().MarkSent(res.id, res.at); err != nil {
+				return err
+			}
+		}
+		for _, res := range parked {
+			if err := tx.OutboxRepo().Park(res.id, res.at, res.err); err != nil {
+				return err
+			}
+		}
+		if failed != nil {
+			if err := tx.OutboxRepo().MarkFailed(failed.id, failed.err); err != nil {
+				return err
+			}
+		}
+		return tx.OutboxRepo().Release(released)
+	})
+	if err != nil {
+		// Publish olunmuş event-lər lease bitdikdən sonra yenidən göndərilir (at-least-once)
+		return 0, err
+	}
+	return len(sent), nil
+}
+
+type outboxResult struct {
+	id  uint
+	at  time.Time
+	err error
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type fakePublisher struct {
+	fail      bool
+	before    func() // hər publish-dən əvvəl çağırılır
+	published []string
+	queues    [][]string
+}
+
+func (p *fakePublisher) PublishEvent(exchange string, payload any, passiveQueues []string) error {
+	return p.PublishEventContext(context.Background(), exchange, payload, passiveQueues)
+}
+
+func (p *fakePublisher) PublishEventContext(ctx context.Context, exchange string, payload any, passiveQueues []string) error {
+	if p.before != nil {
+		p.before()
+	}
+	if p.fail {
+		return errors.New("broker unavailable")
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	p.published = append(p.published, exchange+" "+string(body))
+	p.queues = append(p.queues, passiveQueues)
+	return nil
+}
+
+func TestOutboxRelay_RelayPending(t *testing.T) {
+	uow := &fakeUoW{outbox: &fakeOutboxRepo{}}
+	_ = uow.outbox.Add("rbac.update.fanout", map[string]any{"event": "ROLE_CREATED"}, nil)
+	_ = uow.outbox.Add("auth.tokens.fanout", map[string]any{"event": "TOKEN_BLACKLISTED_ALL"}, []string{"a", "b"})
+
+	publisher := &fakePublisher{fail: true}
+	publisher.before = func() {
+		// Broker gözlənilərkən DB tranzaksiyası açıq qalmamalıdır
+		if uow.inTx {
+			t.Error("event published inside the claim transaction")
+		}
+		for _, e := range uow.outbox.events {
+			if e.SentAt == nil && e.LockedUntil == nil {
+				t.Errorf("event %d published without a lease", e.ID)
+			}
+		}
+	}
+	relay := NewOutboxRelay(uow, publisher)
+
+	// Broker əlçatan deyil – heç nə sent olmur, cəhd qeydə alınır
+	if n, err := relay.RelayPending(); err != nil || n != 0 {
+		t.Fatalf("RelayPending() = %d, %v; want 0, nil", n, err)
+	}
+	if e := uow.outbox.events[0]; e.SentAt != nil || e.Attempts != 1 || e.LastError == "" {
+		t.Errorf("failed event = %+v, want attempt recorded and not sent", e)
+	}
+	if e := uow.outbox.events[1]; e.Attempts != 0 || e.LockedUntil != nil {
+		t.Errorf("event after the failure = %+v, want untouched and released to keep ordering", e)
+	}
+
+	publisher.fail = false
+	if n, err := relay.RelayPending(); err != nil || n != 2 {
+		t.Fatalf("RelayPending() = %d, %v; want 2, nil", n, err)
+	}
+	want := []string{
+		`rbac.update.fanout {"event":"ROLE_CREATED"}`,
+		`auth.tokens.fanout {"event":"TOKEN_BLACKLISTED_ALL"}`,
+	}
+	if !reflect.DeepEqual(publisher.published, want) {
+		t.Errorf("published = %q, want %q", publisher.published, want)
+	}
+	if !reflect.DeepEqual(publisher.queues, [][]string{nil, {"a", "b"}}) {
+		t.Errorf("passive queues = %q", publisher.queues)
+	}
+
+	// Artıq göndərilənlər təkrar göndərilmir
+	if n, _ := relay.RelayPending(); n != 0 {
+		t.Errorf("RelayPending() resent %d events", n)
+	}
+}
+
+// unroutablePublisher yalnız bir exchange-ə göndərişi həmişə rədd edir
+type unroutablePublisher struct {
+	fakePublisher
+	exchange string
+}
+
+func (p *unroutablePublisher) PublishEventContext(ctx context.Context, exchange string, payload any, passiveQueues []string) error {
+	if exchange == p.exchange {
+		return errors.New("message returned as unroutable")
+	}
+	return p.fakePublisher.PublishEventContext(ctx, exchange, payload, passiveQueues)
+}
+
+func TestOutboxRelay_ParksPermanentlyFailingEvent(t *testing.T) {
+	uow := &fakeUoW{outbox: &fakeOutboxRepo{}}
+	_ = uow.outbox.Add("missing.fanout", map[string]any{"event": "ROLE_CREATED"}, nil)
+	_ = uow.outbox.Add("auth.tokens.fanout", map[string]any{"event": "TOKEN_BLACKLISTED"}, nil)
+
+	publisher := &unroutablePublisher{exchange: "missing.fanout"}
+	relay := NewOutboxRelay(uow, publisher)
+
+	for i := 1; i < outboxMaxAttempts; i++ {
+		if n, err := relay.RelayPending(); err != nil || n != 0 {
+			t.Fatalf("attempt %d: RelayPending() = %d, %v; want 0, nil", i, n, err)
+		}
+	}
+	if uow.outbox.events[0].ParkedAt != nil {
+		t.Fatal("event parked before reaching the maximum number of attempts")
+	}
+
+	// Son cəhddə event park edilir və növbəti event gözləmədən göndərilir
+	if n, err := relay.RelayPending(); err != nil || n != 1 {
+		t.Fatalf("RelayPending() = %d, %v; want 1, nil", n, err)
+	}
+	if e := uow.outbox.events[0]; e.ParkedAt == nil || e.SentAt != nil || e.Attempts != outboxMaxAttempts {
+		t.Errorf("failing event = %+v, want parked after %d attempts", e, outboxMaxAttempts)
+	}
+	if uow.outbox.events[1].SentAt == nil {
+		t.Error("event after the parked one was not sent")
+	}
+
+	// Park edilmiş event bir daha göndərilmir
+	if n, _ := relay.RelayPending(); n != 0 {
+		t.Errorf("RelayPending() resent %d events", n)
+	}
+}
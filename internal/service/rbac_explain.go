@@ -0,0 +1,97 @@
+package service
+
+import (
+	"ms-authz/internal/domain/model"
+	"ms-authz/pkg/condition"
+	"slices"
+)
+
+// Təyinatın yoxlanılan permission-a münasibəti
+const (
+	GrantNoMatch        = "no_match"        // pattern permission-a uyğun gəlmir
+	GrantOutOfScope     = "out_of_scope"    // uyğun gəlir, amma resurs təyinatın scope-una düşmür
+	GrantConditionFalse = "condition_false" // şərt ödənmir
+	GrantConditionError = "condition_error" // şərt qiymətləndirilə bilmir (deny tətbiq olunur, allow yox)
+	GrantMatched        = "matched"
+)
+
+// GrantTrace explain rejimində bir təyinatın qiymətləndirilməsi
+type GrantTrace struct {
+	Effect          string
+	Pattern         string
+	ResourceType    string
+	ResourcePattern string
+	Condition       string
+	Outcome         string
+	Error           string // şərtin qiymətləndirmə xətası
+	Applied         bool   // təyinat qərarda iştirak edib
+	Decisive        bool   // qərarı məhz bu təyinat müəyyən edib
+}
+
+// RoleTrace rolun (miras alınanlar daxil) bütün təyinatlarının qiymətləndirilməsi
+type RoleTrace struct {
+	Role   string
+	Known  bool // rol tenant-da mövcuddur
+	Grants []GrantTrace
+}
+
+// Explain Decide ilə eyni qərarı verir və hər rolun hər təyinatının necə qiymətləndirildiyini qaytarır.
+// Yalnız diaqnostika üçündür: bütün pattern-lər tək-tək yoxlanılır.
+func (s *RBACService) Explain(tenantID string, roleNames []string, perm string, res Resource, env condition.Env) (Decision, []RoleTrace) {
+	snap := s.current()
+	decision := snap.decide(tenantID, roleNames, perm, res, env)
+
+	traces := make([]RoleTrace, 0, len(roleNames))
+	for _, name := range roleNames {
+		trace := RoleTrace{Role: name, Grants: []GrantTrace{}}
+		rules, ok := snap.roles[tenantKey(tenantID, name)]
+		if ok {
+			trace.Known = true
+			for _, sr := range rules.scopes {
+				trace.Grants = append(trace.Grants, sr.trace(model.EffectDeny, sr.deny.Patterns(), sr.deny.MatchAll(perm), res, env)...)
+				trace.Grants = append(trace.Grants, sr.trace(model.EffectAllow, sr.allow.Patterns(), sr.allow.MatchAll(perm), res, env)...)
+			}
+			for i := range trace.Grants {
+				g := &trace.Grants[i]
+				g.Decisive = g.Applied && name == decision.Role && g.Effect == decision.Effect && g.Pattern == decision.Rule &&
+					g.ResourceType == decision.ResourceType && g.ResourcePattern == decision.ResourcePattern && g.Condition == decision.Condition
+			}
+		}
+		traces = append(traces, trace)
+	}
+	return decision, traces
+}
+
+func (sr scopedRules) trace(effect string, patterns, matched []string, res Resource, env condition.Env) []GrantTrace {
+	grants := make([]GrantTrace, 0, len(patterns))
+	for _, p := range patterns {
+		g := GrantTrace{
+			Effect:          effect,
+			Pattern:         p,
+			ResourceType:    sr.scope.resourceType,
+			ResourcePattern: sr.scope.resourcePattern,
+			Condition:       sr.scope.condition,
+		}
+		switch {
+		case !slices.Contains(matched, p):
+			g.Outcome = GrantNoMatch
+		case !sr.scope.covers(res):
+			g.Outcome = GrantOutOfScope
+		case sr.cond != nil:
+			ok, err := sr.cond.Eval(env)
+			switch {
+			case err != nil:
+				g.Outcome, g.Error = GrantConditionError, err.Error()
+				g.Applied = effect == model.EffectDeny
+			case !ok:
+				g.Outcome = GrantConditionFalse
+			default:
+				g.Outcome, g.Applied = GrantMatched, true
+			}
+		default:
+			g.Outcome, g.Applied = GrantMatched, true
+		}
+		grants = append(grants, g)
+	}
+	return grants
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"log"
+	"ms-authz/internal/domain/model"
+	"ms-authz/pkg/condition"
+	"ms-authz/pkg/permission"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Resource yoxlanılan obyekt, məs. {Type: "invoice", ID: "tenant/42/invoices/7"}.
+// Sıfır dəyər resurssuz yoxlama deməkdir – onda yalnız scope-suz təyinatlar nəzərə alınır.
+type Resource struct {
+	Type string
+	ID   string
+}
+
+func (r Resource) IsZero() bool {
+	return r.Type == "" && r.ID == ""
+}
+
+// grantScope təyinatın aid olduğu resurslar və şərt. Boş type bütün resurslar deməkdir,
+// boş pattern isə həmin tipin bütün resursları. Boş condition – şərtsiz.
+type grantScope struct {
+	resourceType    string
+	resourcePattern string
+	condition       string
+}
+
+func (g grantScope) unscoped() bool {
+	return g.resourceType == ""
+}
+
+// covers resursun scope-a düşdüyünü yoxlayır. Tip "*" ola bilər, ID path.Match ilə müqayisə olunur
+// ("tenant/42/invoices/*" yalnız bir səviyyəni əhatə edir).
+func (g grantScope) covers(res Resource) bool {
+	if g.unscoped() {
+		return true
+	}
+	if res.IsZero() {
+		return false
+	}
+	if g.resourceType != permission.Wildcard && !strings.EqualFold(g.resourceType, res.Type) {
+		return false
+	}
+	if g.resourcePattern == "" {
+		return true
+	}
+	ok, _ := path.Match(g.resourcePattern, res.ID)
+	return ok
+}
+
+// ValidateResourcePattern resurs ID pattern-inin path.Match sintaksisinə uyğun olduğunu yoxlayır
+func ValidateResourcePattern(pattern string) error {
+	_, err := path.Match(pattern, "")
+	return err
+}
+
+type scopedRules struct {
+	scope grantScope
+	cond  *condition.Expression // scope.condition-un yüklənmə zamanı kompilyasiya olunmuş forması
+	allow *permission.Matcher
+	deny  *permission.Matcher
+}
+
+// holds şərtin env üzərində ödəndiyini yoxlayır. Qiymətləndirmə xətası verən şərt
+// allow üçün ödənməmiş, deny üçün ödənmiş sayılır (fail closed).
+func (sr scopedRules) holds(env condition.Env, effect string) bool {
+	if sr.cond == nil {
+		return true
+	}
+	ok, err := sr.cond.Eval(env)
+	if err != nil {
+		return effect == model.EffectDeny
+	}
+	return ok
+}
+
+// Rolun effektiv (miras alınanlar daxil) təyinatları scope-lara görə qruplaşdırılmış.
+// Scope-suz qaydalar (varsa) həmişə birinci gəlir.
+type roleRules struct {
+	scopes []scopedRules
+}
+
+type grantPatterns struct {
+	allow []string
+	deny  []string
+}
+
+func (p *grantPatterns) add(grant model.RolePermission) {
+	if grant.Effect == model.EffectDeny {
+		p.deny = append(p.deny, grant.Permission.Name)
+	} else {
+		p.allow = append(p.allow, grant.Permission.Name)
+	}
+}
+
+func compileRoleRules(grants map[grantScope]*grantPatterns) *roleRules {
+	scopes := make([]grantScope, 0, len(grants))
+	for scope := range grants {
+		scopes = append(scopes, scope)
+	}
+	sort.Slice(scopes, func(i, j int) bool {
+		if scopes[i].resourceType != scopes[j].resourceType {
+			return scopes[i].resourceType < scopes[j].resourceType
+		}
+		if scopes[i].resourcePattern != scopes[j].resourcePattern {
+			return scopes[i].resourcePattern < scopes[j].resourcePattern
+		}
+		return scopes[i].condition < scopes[j].condition
+	})
+
+	rules := &roleRules{scopes: make([]scopedRules, 0, len(scopes))}
+	for _, scope := range scopes {
+		sr := scopedRules{
+			scope: scope,
+			allow: permission.Compile(grants[scope].allow),
+			deny:  permission.Compile(grants[scope].deny),
+		}
+		if scope.condition != "" {
+			cond, err := condition.Compile(scope.condition)
+			if err != nil {
+				// Şərtlər yazılarkən yoxlanılır; buraya düşən təyinatlar heç vaxt tətbiq olunmur
+				log.Printf("⚠️ skipping grants with invalid condition %q: %v", scope.condition, err)
+				continue
+			}
+			sr.cond = cond
+		}
+		rules.scopes = append(rules.scopes, sr)
+	}
+	return rules
+}
+
+// Decision RBAC yoxlamasının nəticəsi və onu müəyyən edən qayda
+type Decision struct {
+	Allowed         bool
+	Effect          string // allow, deny və ya heç bir qayda uyğun gəlmədikdə boş
+	Rule            string // qalib gələn permission pattern-i
+	Role            string // qaydanın tapıldığı rol
+	ResourceType    string // qalib qayda resursa bağlıdırsa onun scope-u
+	ResourcePattern string
+	Condition       string // qalib qaydanın şərti (varsa)
+}
+
+// Yeni uyğunluq cari qalibi əvəz etməlidirmi: daha spesifik pattern qalib gəlir,
+// bərabər olduqda resursa bağlı təyinat qlobaldan, şərtli təyinat şərtsizdən üstündür.
+func (d Decision) replacedBy(rule string, scope grantScope) bool {
+	if d.Rule == "" {
+		return true
+	}
+	if !strings.EqualFold(rule, d.Rule) {
+		return permission.MoreSpecific(rule, d.Rule)
+	}
+	if d.ResourceType == "" && !scope.unscoped() {
+		return true
+	}
+	return d.Condition == "" && scope.condition != "" && (d.ResourceType != "") == !scope.unscoped()
+}
+
+// PermissionSet bir scope üzrə effektiv allow və deny pattern-ləri
+type PermissionSet struct {
+	ResourceType    string
+	ResourcePattern string
+	Condition       string
+	Allow           []string
+	Deny            []string
+}
@@ -1,74 +1,378 @@
 package service
 
 import (
+	"errors"
 	"log"
 	"ms-authz/internal/domain/model"
 	"ms-authz/internal/domain/repository"
-	"ms-authz/internal/infrastructure/mq"
+	"ms-authz/pkg/condition"
+	"ms-authz/pkg/permission"
+	"ms-authz/pkg/route"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+)
+
+var (
+	ErrRoleNotFound = errors.New("role not found")
+	ErrRoleCycle    = errors.New("role inheritance would create a cycle")
 )
 
 type RBACService struct {
-	uow       repository.UnitOfWork
-	cache     sync.Map // map[roleName][]permissionCode
-	publisher mq.Publisher
+	uow      repository.UnitOfWork
+	loadMu   sync.Mutex // LoadCache-ləri ardıcıllaşdırır: köhnə yükləmə yenisinin üstünə yazıla bilməz
+	snapshot atomic.Pointer[rbacSnapshot]
+}
+
+// rbacSnapshot bir LoadCache-in tam nəticəsidir və bütövlükdə əvəz olunur, yaradıldıqdan sonra dəyişmir.
+// Oxucular rolları, istifadəçi rollarını, kataloqu və route-ları həmişə eyni yükləmədən görür.
+type rbacSnapshot struct {
+	roles     map[string]*roleRules   // tenant|roleName
+	userRoles map[string][]string     // tenant|userID – server-side user_roles təyinatları
+	catalog   map[string][]string     // tenant – wildcard-ların açılması üçün konkret permission-lar
+	routes    map[string]*route.Table // tenant|service – route → permission reyestri
 }
 
-func NewRBACService(uow repository.UnitOfWork, publisher mq.Publisher) *RBACService {
+// Cari snapshot; cache heç yüklənməyibsə boşdur
+func (s *RBACService) current() *rbacSnapshot {
+	if snap := s.snapshot.Load(); snap != nil {
+		return snap
+	}
+	return &rbacSnapshot{}
+}
+
+// Rol adları və istifadəçilər tenant daxilində unikaldır, cache açarı hər ikisini saxlayır
+func tenantKey(tenantID, name string) string {
+	return tenantID + "|" + name
+}
+
+func NewRBACService(uow repository.UnitOfWork) *RBACService {
 	s := &RBACService{
-		uow:       uow,
-		publisher: publisher,
+		uow: uow,
+	}
+	if err := s.LoadCache(); err != nil {
+		log.Println("❌ failed to load RBAC cache:", err)
 	}
-	s.LoadCache()
 	return s
 }
 
-// Sistemdəki bütün rolları və permission-ları yaddaşa yükləyir
-func (s *RBACService) LoadCache() {
-	roles, _ := s.uow.RoleRepo().GetAll()
+// Sistemdəki bütün tenant-ların rollarını və onların effektiv permission-larını yaddaşa yükləyir.
+// Effektiv permission-lar rolun özünə və bütün valideyn rollarına (irsiyyət closure-u) təyin olunanlardır,
+// allow və deny təyinatları, həmçinin resursa bağlı təyinatlar scope-larına görə ayrıca saxlanılır.
+// Yeni snapshot tam qurulduqdan sonra bir dəfəyə əvəz olunur, xəta olduqda köhnəsi qalır;
+// artıq mövcud olmayan (silinmiş və ya adı dəyişmiş) rollar beləliklə cache-dən çıxır.
+func (s *RBACService) LoadCache() error {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	snap := &rbacSnapshot{}
+	var err error
+	if snap.roles, err = s.loadRoles(); err != nil {
+		return err
+	}
+	if snap.catalog, err = s.loadCatalog(); err != nil {
+		return err
+	}
+	if snap.routes, err = s.loadRoutes(); err != nil {
+		return err
+	}
+	if snap.userRoles, err = s.loadUserRoles(); err != nil {
+		return err
+	}
+	s.snapshot.Store(snap)
+	return nil
+}
+
+// Rolları irsiyyət closure-u ilə kompilyasiya edir
+func (s *RBACService) loadRoles() (map[string]*roleRules, error) {
+	roles, err := s.uow.RoleRepo().GetAllWithParents()
+	if err != nil {
+		return nil, err
+	}
+	grants, err := s.uow.RolePermissionRepo().GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	hierarchy := newRoleHierarchy(roles)
+	roleTenants := make(map[uint]string, len(roles))
 	for _, role := range roles {
-		s.loadRole(role)
+		roleTenants[role.ID] = role.TenantID
+	}
+	byRole := make(map[uint][]model.RolePermission)
+	for _, g := range grants {
+		if g.Permission.ID == 0 {
+			continue // permission silinib
+		}
+		if tenant, ok := roleTenants[g.RoleID]; !ok || g.Permission.TenantID != tenant {
+			continue // başqa tenant-ın permission-u heç vaxt tətbiq olunmur
+		}
+		byRole[g.RoleID] = append(byRole[g.RoleID], g)
+	}
+
+	loaded := make(map[string]*roleRules, len(roles))
+	for _, role := range roles {
+		scoped := make(map[grantScope]*grantPatterns)
+		for _, id := range hierarchy.closure(role.ID) {
+			for _, g := range byRole[id] {
+				scope := grantScope{resourceType: g.ResourceType, resourcePattern: g.ResourcePattern, condition: g.Condition}
+				if scoped[scope] == nil {
+					scoped[scope] = &grantPatterns{}
+				}
+				scoped[scope].add(g)
+			}
+		}
+		loaded[tenantKey(role.TenantID, role.Name)] = compileRoleRules(scoped)
+	}
+	return loaded, nil
+}
+
+// Hər tenant-ın konkret (wildcard-sız) permission adlarını yaddaşa yükləyir
+func (s *RBACService) loadCatalog() (map[string][]string, error) {
+	permissions, err := s.uow.PermissionRepo().GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	byTenant := make(map[string][]string)
+	for _, p := range permissions {
+		if strings.Contains(p.Name, permission.Wildcard) {
+			continue // pattern-dir, konkret permission deyil
+		}
+		byTenant[p.TenantID] = append(byTenant[p.TenantID], p.Name)
+	}
+	for _, names := range byTenant {
+		sort.Strings(names)
+	}
+	return byTenant, nil
+}
+
+// Hər tenant və servisin route reyestrini uyğunlaşma sırası ilə kompilyasiya edir.
+// Etibarsız route (əl ilə DB-yə yazılmış) buraxılır – uyğun gəlməyən sorğu rədd edilir.
+func (s *RBACService) loadRoutes() (map[string]*route.Table, error) {
+	routes, err := s.uow.RouteRepo().GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make(map[string][]route.Rule)
+	for _, r := range routes {
+		rule := RouteRule(r)
+		if err := route.Validate(rule); err != nil {
+			log.Printf("⚠️ skipping route %d of service %q: %v", r.ID, r.Service, err)
+			continue
+		}
+		key := tenantKey(r.TenantID, r.Service)
+		rules[key] = append(rules[key], rule)
+	}
+
+	tables := make(map[string]*route.Table, len(rules))
+	for key, list := range rules {
+		table, err := route.Compile(list)
+		if err != nil {
+			return nil, err
+		}
+		tables[key] = table
 	}
+	return tables, nil
+}
+
+// RouteRule reyestrdəki route-u pkg/route qaydasına çevirir
+func RouteRule(r model.Route) route.Rule {
+	return route.Rule{Method: r.Method, Path: r.Path, Permissions: r.Permissions, Public: r.Public}
+}
+
+// ResolveRoute servisin metod və path-ına uyğun gələn ilk reyestr qaydasını qaytarır
+func (s *RBACService) ResolveRoute(tenantID, service, method, path string) (route.Rule, bool) {
+	return s.current().routes[tenantKey(tenantID, service)].Match(method, path)
 }
 
-// RBAC cache-də permission yoxlama
-func (s *RBACService) HasPermission(roleName string, permission string) bool {
-	val, ok := s.cache.Load(roleName)
-	if !ok {
-		return false
+// İstifadəçilərin server-side rollarını (user_roles və köhnə role_id) yaddaşa yükləyir
+func (s *RBACService) loadUserRoles() (map[string][]string, error) {
+	users, err := s.uow.UserRepo().GetAllWithRoles()
+	if err != nil {
+		return nil, err
 	}
-	perms := val.([]string)
-	for _, p := range perms {
-		if strings.EqualFold(p, permission) {
-			return true
+
+	loaded := make(map[string][]string, len(users))
+	for _, u := range users {
+		// Başqa tenant-ın rolu heç vaxt nəzərə alınmır: ad eyni olsa belə bu tenant-ın rolu deyil
+		var names []string
+		if u.RoleID != 0 && u.Role.Name != "" && u.Role.TenantID == u.TenantID {
+			names = append(names, u.Role.Name)
+		}
+		for _, r := range u.Roles {
+			if r.TenantID == u.TenantID {
+				names = append(names, r.Name)
+			}
+		}
+		if len(names) == 0 {
+			continue
 		}
+		loaded[tenantKey(u.TenantID, strconv.FormatUint(uint64(u.ID), 10))] = names
 	}
-	return false
+	return loaded, nil
 }
 
-// Cache-də konkret bir rolu yüklə
-func (s *RBACService) loadRole(role model.Role) {
-	perms, _ := s.uow.RolePermissionRepo().GetPermissionsByRoleID(role.ID)
-	var names []string
-	for _, p := range perms {
-		names = append(names, p.Name)
+// RolesForUser token-dəki rollarla istifadəçiyə server-side təyin olunmuş rolların birləşməsini qaytarır.
+// userID JWT-nin user_id claim-idir və tenantID tenant-ındakı users cədvəlinin ID-si ilə uyğunlaşdırılır.
+func (s *RBACService) RolesForUser(tenantID, userID string, tokenRoles []string) []string {
+	roles := append([]string(nil), tokenRoles...)
+	for _, name := range s.current().userRoles[tenantKey(tenantID, userID)] {
+		if !slices.Contains(roles, name) {
+			roles = append(roles, name)
+		}
+	}
+	return roles
+}
+
+// RBAC cache-də permission yoxlama (wildcard pattern-lər və deny təyinatları daxil, resurssuz)
+func (s *RBACService) HasPermission(tenantID, roleName string, perm string) bool {
+	return s.Decide(tenantID, []string{roleName}, perm, Resource{}, nil).Allowed
+}
+
+// Decide tenantID tenant-ındakı rolların perm üzərində yekun qərarını deny-overrides qaydası ilə verir:
+// hər hansı rolun uyğun deny təyinatı varsa qadağandır, əks halda uyğun allow olduqda icazə verilir.
+// Heç bir təyinat uyğun gəlmirsə qərar default deny-dir (Effect boş qalır).
+// Scope-suz təyinatlar həmişə, resursa bağlı təyinatlar isə yalnız res onların scope-una düşdükdə nəzərə alınır.
+// Şərtli təyinatlar env (token claim-ləri və sorğu konteksti) üzərində şərt ödəndikdə tətbiq olunur.
+// Eyni effektli bir neçə uyğunluqdan ən spesifik pattern qalib sayılır.
+func (s *RBACService) Decide(tenantID string, roleNames []string, perm string, res Resource, env condition.Env) Decision {
+	return s.current().decide(tenantID, roleNames, perm, res, env)
+}
+
+func (snap *rbacSnapshot) decide(tenantID string, roleNames []string, perm string, res Resource, env condition.Env) Decision {
+	var denied, allowed Decision
+	for _, name := range roleNames {
+		rules, ok := snap.roles[tenantKey(tenantID, name)]
+		if !ok {
+			continue
+		}
+		for _, sr := range rules.scopes {
+			if !sr.scope.covers(res) {
+				continue
+			}
+			if rule, ok := sr.deny.Match(perm); ok && denied.replacedBy(rule, sr.scope) && sr.holds(env, model.EffectDeny) {
+				denied = Decision{Allowed: false, Effect: model.EffectDeny, Rule: rule, Role: name,
+					ResourceType: sr.scope.resourceType, ResourcePattern: sr.scope.resourcePattern, Condition: sr.scope.condition}
+			}
+			if rule, ok := sr.allow.Match(perm); ok && allowed.replacedBy(rule, sr.scope) && sr.holds(env, model.EffectAllow) {
+				allowed = Decision{Allowed: true, Effect: model.EffectAllow, Rule: rule, Role: name,
+					ResourceType: sr.scope.resourceType, ResourcePattern: sr.scope.resourcePattern, Condition: sr.scope.condition}
+			}
+		}
+	}
+
+	if denied.Rule != "" {
+		return denied
 	}
-	s.cache.Store(role.Name, names)
+	return allowed
+}
+
+// EffectivePermissions tenantID tenant-ındakı rolların (miras alınanlar daxil) cache-dəki pattern-lərini scope-lara görə birləşdirir.
+// Scope-suz dəst həmişə birinci elementdir.
+func (s *RBACService) EffectivePermissions(tenantID string, roleNames ...string) []PermissionSet {
+	sets := []PermissionSet{{Allow: []string{}, Deny: []string{}}}
+	index := map[grantScope]int{{}: 0}
+	seen := make(map[string]bool)
+	snap := s.current()
+	for _, name := range roleNames {
+		rules, ok := snap.roles[tenantKey(tenantID, name)]
+		if !ok {
+			continue
+		}
+		for _, sr := range rules.scopes {
+			i, ok := index[sr.scope]
+			if !ok {
+				i = len(sets)
+				index[sr.scope] = i
+				sets = append(sets, PermissionSet{
+					ResourceType:    sr.scope.resourceType,
+					ResourcePattern: sr.scope.resourcePattern,
+					Condition:       sr.scope.condition,
+					Allow:           []string{},
+					Deny:            []string{},
+				})
+			}
+			prefix := sr.scope.resourceType + "|" + sr.scope.resourcePattern + "|" + sr.scope.condition + "|"
+			for _, p := range sr.allow.Patterns() {
+				if !seen[prefix+"allow|"+p] {
+					seen[prefix+"allow|"+p] = true
+					sets[i].Allow = append(sets[i].Allow, p)
+				}
+			}
+			for _, p := range sr.deny.Patterns() {
+				if !seen[prefix+"deny|"+p] {
+					seen[prefix+"deny|"+p] = true
+					sets[i].Deny = append(sets[i].Deny, p)
+				}
+			}
+		}
+	}
+	return sets
+}
+
+// GrantedPermissions tenant-ın permission kataloqundan rolların resurssuz icazə verdiyi konkret permission-ları qaytarır.
+// Wildcard-lar kataloq üzrə açılır, deny-lar çıxılır; şərtli təyinatlar env üzərində qiymətləndirilir.
+func (s *RBACService) GrantedPermissions(tenantID string, roleNames []string, env condition.Env) []string {
+	granted := []string{}
+	snap := s.current()
+	for _, name := range snap.catalog[tenantID] {
+		if snap.decide(tenantID, roleNames, name, Resource{}, env).Allowed {
+			granted = append(granted, name)
+		}
+	}
+	return granted
+}
+
+// AddRoleParent roleID-ni parentID-dən miras aldırır. Yeni əlaqə dövr yaradarsa ErrRoleCycle qaytarılır.
+// tx dəyişikliyin tranzaksiyası olmalıdır ki, yoxlama ilə yazma eyni vəziyyəti görsün.
+func (s *RBACService) AddRoleParent(tx repository.UnitOfWork, roleID, parentID uint) error {
+	if roleID == parentID {
+		return ErrRoleCycle
+	}
+
+	roles, err := tx.RoleRepo().GetAllWithParents()
+	if err != nil {
+		return err
+	}
+	hierarchy := newRoleHierarchy(roles)
+	if !hierarchy.exists(roleID) || !hierarchy.exists(parentID) {
+		return ErrRoleNotFound
+	}
+	// parent artıq (dolayısı ilə) roldan miras alırsa, yeni əlaqə dövr yaradar
+	if hierarchy.inherits(parentID, roleID) {
+		return ErrRoleCycle
+	}
+
+	return tx.RoleRepo().AddParent(roleID, parentID)
 }
 
 // CRUD sonrası və ya MQ ilə çağırıla bilər
-func (s *RBACService) ReloadCache() {
+func (s *RBACService) ReloadCache() error {
 	log.Println("Reloading RBAC cache...")
-	s.LoadCache()
+	return s.LoadCache()
 }
 
-// MQ ilə digər instansiyalara xəbər göndərir
-//func (s *RBACService) PublishCacheReload() {
-//	s.PublishCacheEvent("RBAC_CACHE_RELOAD", map[string]any{})
-//}
+// Mutate RBAC dəyişikliyini uow-un tranzaksiyasında icra edir və commit-dən sonra local cache-i dərhal yeniləyir,
+// beləliklə ləğv olunan icazə bu instansiyada outbox və MQ-nu gözləmədən qüvvədən düşür. Dəyişiklik artıq commit
+// olunduğu üçün yenidən yükləmə xətası yalnız log-lanır; digər instansiyalar event ilə yenə də yeniləyir.
+func (s *RBACService) Mutate(uow repository.UnitOfWork, fn func(tx repository.UnitOfWork) error) error {
+	if err := uow.Transaction(fn); err != nil {
+		return err
+	}
+	if err := s.ReloadCache(); err != nil {
+		log.Println("❌ failed to reload RBAC cache after commit:", err)
+	}
+	return nil
+}
 
-func (s *RBACService) PublishCacheEvent(event string, payload map[string]any) {
+// EnqueueCacheEvent digər instansiyalar üçün event-i outbox-a yazır.
+// tx RBAC dəyişikliyinin tranzaksiyası olmalıdır – event yalnız commit olunduqda göndərilir.
+func (s *RBACService) EnqueueCacheEvent(tx repository.UnitOfWork, event string, payload map[string]any) error {
 	message := map[string]any{
 		"event": event,
 	}
@@ -76,5 +380,5 @@ func (s *RBACService) PublishCacheEvent(event string, payload map[string]any) {
 		message[k] = v
 	}
 
-	_ = s.publisher.PublishEvent("rbac.update.fanout", message, []string{})
+	return tx.OutboxRepo().Add("rbac.update.fanout", message, nil)
 }
@@ -0,0 +1,674 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"ms-authz/internal/domain/model"
+	"ms-authz/internal/domain/repository"
+	"ms-authz/pkg/condition"
+	"ms-authz/pkg/jwtutil"
+)
+
+// fakeRoleRepo rolları, onların permission-larını və valideyn əlaqələrini yaddaşda saxlayır
+type fakeRoleRepo struct {
+	repository.RoleRepository
+	roles   []model.Role
+	perms   map[uint][]string
+	denies  map[uint][]string
+	parents map[uint][]uint
+	scoped  map[uint][]model.RolePermission // resursa bağlı təyinatlar, Permission.Name doldurulmalıdır
+}
+
+func newFakeRoleRepo(names ...string) *fakeRoleRepo {
+	r := &fakeRoleRepo{perms: map[uint][]string{}, denies: map[uint][]string{}, parents: map[uint][]uint{}, scoped: map[uint][]model.RolePermission{}}
+	for i, name := range names {
+		r.roles = append(r.roles, model.Role{Model: gorm.Model{ID: uint(i + 1)}, Name: name})
+	}
+	return r
+}
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	users []model.User
+}
+
+// nil receiver – istifadəçisiz test halları üçün
+func (r *fakeUserRepo) GetAllWithRoles() ([]model.User, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return r.users, nil
+}
+
+// fakePermissionRepo permission kataloqunu yaddaşda saxlayır
+type fakePermissionRepo struct {
+	repository.PermissionRepository
+	permissions []model.Permission
+}
+
+func (r *fakePermissionRepo) GetAll() ([]model.Permission, error) {
+	return r.permissions, nil
+}
+
+// fakeRouteRepo route reyestrini yaddaşda saxlayır (uyğunlaşma sırası ilə verilməlidir)
+type fakeRouteRepo struct {
+	repository.RouteRepository
+	routes []model.Route
+}
+
+func (r *fakeRouteRepo) GetAll() ([]model.Route, error) {
+	return r.routes, nil
+}
+
+// fakeRolePermissionRepo fakeRoleRepo-dakı təyinatları RolePermission kimi qaytarır
+type fakeRolePermissionRepo struct {
+	repository.RolePermissionRepository
+	roles *fakeRoleRepo
+}
+
+func (r *fakeRolePermissionRepo) GetAll() ([]model.RolePermission, error) {
+	var grants []model.RolePermission
+	for _, role := range r.roles.roles {
+		for effect, perms := range map[string][]string{model.EffectAllow: r.roles.perms[role.ID], model.EffectDeny: r.roles.denies[role.ID]} {
+			for _, name := range perms {
+				grants = append(grants, model.RolePermission{
+					RoleID:     role.ID,
+					Effect:     effect,
+					Permission: model.Permission{Model: gorm.Model{ID: 1}, TenantID: role.TenantID, Name: name},
+				})
+			}
+		}
+		for _, g := range r.roles.scoped[role.ID] {
+			g.RoleID = role.ID
+			g.Permission.ID = 1
+			if g.Permission.TenantID == "" {
+				g.Permission.TenantID = role.TenantID
+			}
+			grants = append(grants, g)
+		}
+	}
+	return grants, nil
+}
+
+func (r *fakeRoleRepo) GetAllWithParents() ([]model.Role, error) {
+	var roles []model.Role
+	for _, role := range r.roles {
+		for _, id := range r.parents[role.ID] {
+			parent := model.Role{Model: gorm.Model{ID: id}}
+			for _, other := range r.roles {
+				if other.ID == id {
+					parent.TenantID = other.TenantID
+				}
+			}
+			role.Parents = append(role.Parents, parent)
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+func (r *fakeRoleRepo) AddParent(roleID, parentID uint) error {
+	r.parents[roleID] = append(r.parents[roleID], parentID)
+	return nil
+}
+
+func TestRBACService_RoleInheritance(t *testing.T) {
+	// 1 viewer <- 2 support <- 3 admin <- 4 super_admin; 5 auditor <- 4
+	roles := newFakeRoleRepo("viewer", "support", "admin", "super_admin", "auditor")
+	roles.perms[1] = []string{"orders.read"}
+	roles.perms[2] = []string{"tickets.write"}
+	roles.perms[3] = []string{"users.manage", "orders.read"}
+	roles.perms[5] = []string{"audit.read"}
+	uow := &fakeUoW{roles: roles}
+	svc := NewRBACService(uow)
+
+	links := [][2]uint{{2, 1}, {3, 2}, {4, 3}, {4, 5}}
+	for _, l := range links {
+		if err := svc.AddRoleParent(uow, l[0], l[1]); err != nil {
+			t.Fatalf("AddRoleParent(%d, %d) error = %v", l[0], l[1], err)
+		}
+	}
+	if err := svc.ReloadCache(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := svc.EffectivePermissions("", "super_admin")[0].Allow
+	sort.Strings(got)
+	want := []string{"audit.read", "orders.read", "tickets.write", "users.manage"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EffectivePermissions(super_admin) = %v, want %v", got, want)
+	}
+	if !svc.HasPermission("", "support", "orders.read") {
+		t.Error("support must inherit orders.read from viewer")
+	}
+	if svc.HasPermission("", "viewer", "tickets.write") {
+		t.Error("permissions must not flow from child to parent")
+	}
+
+	cycles := []struct {
+		name         string
+		role, parent uint
+		wantErr      error
+	}{
+		{"self", 2, 2, ErrRoleCycle},
+		{"direct", 3, 4, ErrRoleCycle},
+		{"transitive", 1, 4, ErrRoleCycle},
+		{"unknown role", 1, 42, ErrRoleNotFound},
+	}
+	for _, tt := range cycles {
+		if err := svc.AddRoleParent(uow, tt.role, tt.parent); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: AddRoleParent(%d, %d) error = %v, want %v", tt.name, tt.role, tt.parent, err, tt.wantErr)
+		}
+	}
+
+	// Diamond (iki yol eyni valideynə) dövr deyil
+	if err := svc.AddRoleParent(uow, 5, 1); err != nil {
+		t.Errorf("diamond inheritance rejected: %v", err)
+	}
+}
+
+func TestRBACService_WildcardPermissions(t *testing.T) {
+	roles := newFakeRoleRepo("order_admin", "reader", "support")
+	roles.perms[1] = []string{"orders:*"}
+	roles.perms[2] = []string{"*:read"}
+	roles.perms[3] = []string{"tickets:read"}
+	roles.parents[3] = []uint{2}
+	svc := NewRBACService(&fakeUoW{roles: roles})
+
+	tests := []struct {
+		role, perm string
+		wantRule   string
+	}{
+		{"order_admin", "orders:refund", "orders:*"},
+		{"order_admin", "orders:items:delete", "orders:*"},
+		{"order_admin", "users:read", ""},
+		{"reader", "users:read", "*:read"},
+		{"reader", "users:write", ""},
+		{"support", "tickets:read", "tickets:read"}, // öz literal qrantı miras alınan wildcard-dan spesifikdir
+		{"support", "orders:read", "*:read"},
+		{"unknown", "orders:read", ""},
+	}
+	for _, tt := range tests {
+		d := svc.Decide("", []string{tt.role}, tt.perm, Resource{}, nil)
+		if d.Rule != tt.wantRule || d.Allowed != (tt.wantRule != "") || svc.HasPermission("", tt.role, tt.perm) != d.Allowed {
+			t.Errorf("Decide(%q, %q) = %+v; want rule %q", tt.role, tt.perm, d, tt.wantRule)
+		}
+	}
+}
+
+func TestRBACService_DenyOverrides(t *testing.T) {
+	roles := newFakeRoleRepo("support", "restricted_contractor", "contractor_lead")
+	roles.perms[1] = []string{"customers:*", "tickets:*"}
+	roles.perms[2] = []string{"tickets:read"}
+	roles.denies[2] = []string{"customers:pii:*"}
+	roles.perms[3] = []string{"customers:pii:read"}
+	roles.parents[3] = []uint{2} // miras alınan deny öz allow-dan üstündür
+	svc := NewRBACService(&fakeUoW{roles: roles})
+
+	tests := []struct {
+		name  string
+		roles []string
+		perm  string
+		want  Decision
+	}{
+		{"allow only", []string{"support"}, "customers:pii:read",
+			Decision{Allowed: true, Effect: "allow", Rule: "customers:*", Role: "support"}},
+		{"deny overrides allow of another role", []string{"support", "restricted_contractor"}, "customers:pii:read",
+			Decision{Allowed: false, Effect: "deny", Rule: "customers:pii:*", Role: "restricted_contractor"}},
+		{"deny does not affect other permissions", []string{"support", "restricted_contractor"}, "customers:orders:read",
+			Decision{Allowed: true, Effect: "allow", Rule: "customers:*", Role: "support"}},
+		{"most specific allow wins", []string{"support", "restricted_contractor"}, "tickets:read",
+			Decision{Allowed: true, Effect: "allow", Rule: "tickets:read", Role: "restricted_contractor"}},
+		{"inherited deny overrides own allow", []string{"contractor_lead"}, "customers:pii:read",
+			Decision{Allowed: false, Effect: "deny", Rule: "customers:pii:*", Role: "contractor_lead"}},
+		{"no matching rule", []string{"restricted_contractor"}, "billing:read",
+			Decision{}},
+	}
+	for _, tt := range tests {
+		if got := svc.Decide("", tt.roles, tt.perm, Resource{}, nil); got != tt.want {
+			t.Errorf("%s: Decide() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	deny := svc.EffectivePermissions("", "contractor_lead")[0].Deny
+	if !reflect.DeepEqual(deny, []string{"customers:pii:*"}) {
+		t.Errorf("EffectivePermissions(contractor_lead) deny = %v", deny)
+	}
+}
+
+func TestRBACService_MultiRoleUsers(t *testing.T) {
+	roles := newFakeRoleRepo("support", "billing", "restricted_contractor")
+	roles.perms[1] = []string{"tickets:*"}
+	roles.perms[2] = []string{"invoices:read"}
+	roles.denies[3] = []string{"tickets:delete"}
+	users := &fakeUserRepo{users: []model.User{
+		{Model: gorm.Model{ID: 7}, Roles: []model.Role{roles.roles[1]}},
+		{Model: gorm.Model{ID: 8}, RoleID: 3, Role: roles.roles[2], Roles: []model.Role{roles.roles[0]}},
+	}}
+	svc := NewRBACService(&fakeUoW{roles: roles, users: users})
+
+	tests := []struct {
+		name       string
+		userID     string
+		tokenRoles []string
+		perm       string
+		wantRoles  []string
+		allowed    bool
+	}{
+		{"token role only", "1", []string{"support"}, "tickets:read", []string{"support"}, true},
+		{"server-side role added", "7", []string{"support"}, "invoices:read", []string{"support", "billing"}, true},
+		{"duplicate roles merged", "7", []string{"billing"}, "invoices:read", []string{"billing"}, true},
+		{"legacy role_id and user_roles", "8", nil, "tickets:read", []string{"restricted_contractor", "support"}, true},
+		{"deny from any role wins", "8", nil, "tickets:delete", []string{"restricted_contractor", "support"}, false},
+	}
+	for _, tt := range tests {
+		got := svc.RolesForUser("", tt.userID, tt.tokenRoles)
+		if !reflect.DeepEqual(got, tt.wantRoles) {
+			t.Errorf("%s: RolesForUser() = %v, want %v", tt.name, got, tt.wantRoles)
+		}
+		if d := svc.Decide("", got, tt.perm, Resource{}, nil); d.Allowed != tt.allowed {
+			t.Errorf("%s: Decide(%q) = %+v, want allowed = %v", tt.name, tt.perm, d, tt.allowed)
+		}
+	}
+}
+
+func TestRBACService_ResourceScopedGrants(t *testing.T) {
+	roles := newFakeRoleRepo("tenant42_accountant", "auditor")
+	roles.perms[1] = []string{"invoice:read"}
+	roles.scoped[1] = []model.RolePermission{
+		{Effect: "allow", ResourceType: "invoice", ResourcePattern: "tenant/42/invoices/*", Permission: model.Permission{Name: "invoice:edit"}},
+		{Effect: "deny", ResourceType: "invoice", ResourcePattern: "tenant/42/invoices/locked-*", Permission: model.Permission{Name: "invoice:*"}},
+	}
+	roles.scoped[2] = []model.RolePermission{
+		{Effect: "allow", ResourceType: "*", Permission: model.Permission{Name: "*:read"}},
+	}
+	svc := NewRBACService(&fakeUoW{roles: roles})
+
+	tests := []struct {
+		name    string
+		role    string
+		perm    string
+		res     Resource
+		allowed bool
+		scope   string
+	}{
+		{"scoped allow", "tenant42_accountant", "invoice:edit", Resource{"invoice", "tenant/42/invoices/7"}, true, "invoice|tenant/42/invoices/*"},
+		{"other tenant", "tenant42_accountant", "invoice:edit", Resource{"invoice", "tenant/43/invoices/7"}, false, ""},
+		{"nested path not matched", "tenant42_accountant", "invoice:edit", Resource{"invoice", "tenant/42/invoices/7/lines"}, false, ""},
+		{"other resource type", "tenant42_accountant", "invoice:edit", Resource{"order", "tenant/42/invoices/7"}, false, ""},
+		{"no resource", "tenant42_accountant", "invoice:edit", Resource{}, false, ""},
+		{"unscoped grant applies to any resource", "tenant42_accountant", "invoice:read", Resource{"invoice", "tenant/43/invoices/7"}, true, "|"},
+		{"scoped deny overrides unscoped allow", "tenant42_accountant", "invoice:read", Resource{"invoice", "tenant/42/invoices/locked-1"}, false, "invoice|tenant/42/invoices/locked-*"},
+		{"type wildcard without pattern", "auditor", "order:read", Resource{"order", "anything/at/all"}, true, "*|"},
+	}
+	for _, tt := range tests {
+		d := svc.Decide("", []string{tt.role}, tt.perm, tt.res, nil)
+		if d.Allowed != tt.allowed {
+			t.Errorf("%s: Decide() = %+v, want allowed = %v", tt.name, d, tt.allowed)
+		}
+		if tt.scope != "" && d.ResourceType+"|"+d.ResourcePattern != tt.scope {
+			t.Errorf("%s: Decide() scope = %q|%q, want %q", tt.name, d.ResourceType, d.ResourcePattern, tt.scope)
+		}
+	}
+
+	sets := svc.EffectivePermissions("", "tenant42_accountant")
+	if len(sets) != 3 || !reflect.DeepEqual(sets[0].Allow, []string{"invoice:read"}) || sets[1].ResourcePattern != "tenant/42/invoices/*" {
+		t.Errorf("EffectivePermissions(tenant42_accountant) = %+v", sets)
+	}
+}
+
+func TestRBACService_ConditionalGrants(t *testing.T) {
+	roles := newFakeRoleRepo("sales_rep")
+	roles.perms[1] = []string{"deals:delete", "exports:run"}
+	roles.scoped[1] = []model.RolePermission{
+		{Effect: "allow", Condition: "ip in 10.0.0.0/8", Permission: model.Permission{Name: "reports:read"}},
+		{Effect: "allow", Condition: "claims.department == resource.department", Permission: model.Permission{Name: "deals:edit"}},
+		{Effect: "deny", Condition: "time between 22:00-06:00", Permission: model.Permission{Name: "deals:delete"}},
+		{Effect: "deny", Condition: `claims.level > "3"`, Permission: model.Permission{Name: "exports:run"}},
+		{Effect: "allow", Condition: `claims.level > "3"`, Permission: model.Permission{Name: "audit:read"}},
+		{Effect: "allow", Condition: "ip in", Permission: model.Permission{Name: "broken:read"}},
+	}
+	svc := NewRBACService(&fakeUoW{roles: roles})
+
+	env := func(ip string, hour int, department string) condition.Env {
+		return condition.Env{
+			"ip":       ip,
+			"time":     time.Date(2026, 10, 18, hour, 0, 0, 0, time.UTC),
+			"claims":   map[string]any{"department": "sales", "level": float64(2)},
+			"resource": map[string]any{"department": department},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		perm      string
+		env       condition.Env
+		allowed   bool
+		condition string
+	}{
+		{"ip inside range", "reports:read", env("10.1.2.3", 9, ""), true, "ip in 10.0.0.0/8"},
+		{"ip outside range", "reports:read", env("192.168.1.1", 9, ""), false, ""},
+		{"no context", "reports:read", nil, false, ""},
+		{"claim matches resource", "deals:edit", env("10.1.2.3", 9, "sales"), true, "claims.department == resource.department"},
+		{"claim differs from resource", "deals:edit", env("10.1.2.3", 9, "hr"), false, ""},
+		{"conditional deny outside window", "deals:delete", env("10.1.2.3", 9, ""), true, ""},
+		{"conditional deny inside window", "deals:delete", env("10.1.2.3", 23, ""), false, "time between 22:00-06:00"},
+		{"deny with evaluation error applies", "exports:run", env("10.1.2.3", 9, ""), false, `claims.level > "3"`},
+		{"allow with evaluation error does not apply", "audit:read", env("10.1.2.3", 9, ""), false, ""},
+		{"invalid condition is skipped", "broken:read", env("10.1.2.3", 9, ""), false, ""},
+	}
+	for _, tt := range tests {
+		d := svc.Decide("", []string{"sales_rep"}, tt.perm, Resource{}, tt.env)
+		if d.Allowed != tt.allowed || d.Condition != tt.condition {
+			t.Errorf("%s: Decide() = %+v, want allowed = %v, condition = %q", tt.name, d, tt.allowed, tt.condition)
+		}
+	}
+
+	sets := svc.EffectivePermissions("", "sales_rep")
+	if len(sets) != 5 || sets[1].Condition == "" || sets[1].ResourceType != "" {
+		t.Errorf("EffectivePermissions(sales_rep) = %+v", sets)
+	}
+}
+
+func TestRBACService_GrantedPermissions(t *testing.T) {
+	roles := newFakeRoleRepo("support", "auditor")
+	roles.roles = append(roles.roles, model.Role{Model: gorm.Model{ID: 3}, TenantID: "acme", Name: "support"})
+	roles.perms[1] = []string{"tickets:*", "orders:read"}
+	roles.denies[1] = []string{"tickets:delete"}
+	roles.perms[2] = []string{"*:read"}
+	roles.perms[3] = []string{"*"}
+	roles.scoped[1] = []model.RolePermission{
+		{Effect: "allow", Condition: "ip in 10.0.0.0/8", Permission: model.Permission{Name: "reports:export"}},
+	}
+
+	var catalog []model.Permission
+	for _, name := range []string{"tickets:read", "tickets:write", "tickets:delete", "orders:read", "orders:write", "reports:export", "tickets:*"} {
+		catalog = append(catalog, model.Permission{Name: name})
+	}
+	catalog = append(catalog, model.Permission{TenantID: "acme", Name: "billing:read"})
+	svc := NewRBACService(&fakeUoW{roles: roles, permissions: &fakePermissionRepo{permissions: catalog}})
+
+	tests := []struct {
+		name   string
+		tenant string
+		roles  []string
+		env    condition.Env
+		want   []string
+	}{
+		{"wildcards expanded, deny removed", "", []string{"support"}, nil, []string{"orders:read", "tickets:read", "tickets:write"}},
+		{"condition satisfied", "", []string{"support"}, condition.Env{"ip": "10.0.0.1"}, []string{"orders:read", "reports:export", "tickets:read", "tickets:write"}},
+		{"roles merged", "", []string{"support", "auditor"}, nil, []string{"orders:read", "tickets:read", "tickets:write"}},
+		{"read only", "", []string{"auditor"}, nil, []string{"orders:read", "tickets:read"}},
+		{"other tenant catalog", "acme", []string{"support"}, nil, []string{"billing:read"}},
+		{"no roles", "", nil, nil, []string{}},
+	}
+	for _, tt := range tests {
+		if got := svc.GrantedPermissions(tt.tenant, tt.roles, tt.env); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: GrantedPermissions() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRBACService_Explain(t *testing.T) {
+	roles := newFakeRoleRepo("support")
+	roles.perms[1] = []string{"tickets:*", "orders:read"}
+	roles.denies[1] = []string{"tickets:delete"}
+	roles.scoped[1] = []model.RolePermission{
+		{Effect: "allow", ResourceType: "ticket", Permission: model.Permission{Name: "tickets:delete"}},
+		{Effect: "deny", Condition: `claims.level > "3"`, Permission: model.Permission{Name: "tickets:*"}},
+		{Effect: "allow", Condition: "ip in 10.0.0.0/8", Permission: model.Permission{Name: "*"}},
+	}
+	svc := NewRBACService(&fakeUoW{roles: roles})
+
+	env := condition.Env{"ip": "192.168.1.1", "claims": map[string]any{"level": float64(2)}}
+	d, traces := svc.Explain("", []string{"support", "ghost"}, "tickets:delete", Resource{}, env)
+	if d.Allowed || d.Rule != "tickets:delete" || d.Effect != model.EffectDeny {
+		t.Fatalf("Explain() decision = %+v, want deny by tickets:delete", d)
+	}
+	if len(traces) != 2 || !traces[0].Known || traces[1].Known || len(traces[1].Grants) != 0 {
+		t.Fatalf("Explain() traces = %+v", traces)
+	}
+
+	type outcome struct {
+		outcome           string
+		applied, decisive bool
+	}
+	got := make(map[string]outcome)
+	for _, g := range traces[0].Grants {
+		got[g.Effect+" "+g.Pattern+" "+g.ResourceType+" "+g.Condition] = outcome{g.Outcome, g.Applied, g.Decisive}
+	}
+	want := map[string]outcome{
+		"deny tickets:delete  ":              {GrantMatched, true, true},
+		"allow tickets:*  ":                  {GrantMatched, true, false},
+		"allow orders:read  ":                {GrantNoMatch, false, false},
+		"allow tickets:delete ticket ":       {GrantOutOfScope, false, false},
+		`deny tickets:*  claims.level > "3"`: {GrantConditionError, true, false},
+		"allow *  ip in 10.0.0.0/8":          {GrantConditionFalse, false, false},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Explain() grants = %+v, want %+v", got, want)
+	}
+}
+
+func TestRBACService_TenantIsolation(t *testing.T) {
+	roles := newFakeRoleRepo("admin")
+	roles.roles = append(roles.roles,
+		model.Role{Model: gorm.Model{ID: 2}, TenantID: "acme", Name: "admin"},
+		model.Role{Model: gorm.Model{ID: 3}, TenantID: "globex", Name: "admin"},
+	)
+	roles.perms[1] = []string{"*"}
+	roles.perms[2] = []string{"invoices:*"}
+	roles.perms[3] = []string{"orders:read"}
+	users := &fakeUserRepo{users: []model.User{
+		{Model: gorm.Model{ID: 7}, TenantID: "acme", Roles: []model.Role{roles.roles[1]}},
+	}}
+	svc := NewRBACService(&fakeUoW{roles: roles, users: users})
+
+	tests := []struct {
+		tenant, perm string
+		allowed      bool
+	}{
+		{"", "orders:delete", true},
+		{"acme", "invoices:edit", true},
+		{"acme", "orders:read", false},
+		{"globex", "orders:read", true},
+		{"globex", "invoices:edit", false},
+		{"initech", "orders:read", false},
+	}
+	for _, tt := range tests {
+		if got := svc.HasPermission(tt.tenant, "admin", tt.perm); got != tt.allowed {
+			t.Errorf("HasPermission(%q, admin, %q) = %v, want %v", tt.tenant, tt.perm, got, tt.allowed)
+		}
+	}
+
+	if got := svc.RolesForUser("acme", "7", nil); !reflect.DeepEqual(got, []string{"admin"}) {
+		t.Errorf("RolesForUser(acme, 7) = %v", got)
+	}
+	if got := svc.RolesForUser("globex", "7", nil); len(got) != 0 {
+		t.Errorf("RolesForUser(globex, 7) = %v, want no server-side roles from another tenant", got)
+	}
+}
+
+func TestRBACService_IgnoresCrossTenantEdges(t *testing.T) {
+	roles := newFakeRoleRepo("viewer")
+	roles.roles = append(roles.roles, model.Role{Model: gorm.Model{ID: 2}, TenantID: "acme", Name: "admin"})
+	roles.perms[2] = []string{"*"}
+	roles.parents[1] = []uint{2} // başqa tenant-ın rolu valideyn kimi
+	roles.scoped[1] = []model.RolePermission{
+		{Effect: model.EffectAllow, Permission: model.Permission{TenantID: "acme", Name: "invoices:read"}},
+	}
+	svc := NewRBACService(&fakeUoW{roles: roles})
+
+	for _, perm := range []string{"orders:delete", "invoices:read"} {
+		if svc.HasPermission("", "viewer", perm) {
+			t.Errorf("HasPermission(viewer, %q) = true through an edge to another tenant", perm)
+		}
+	}
+	if !svc.HasPermission("acme", "admin", "orders:delete") {
+		t.Error("HasPermission(acme, admin) = false, own grants must still apply")
+	}
+}
+
+func TestRBACService_ResolveRoute(t *testing.T) {
+	routes := &fakeRouteRepo{routes: []model.Route{
+		{Model: gorm.Model{ID: 1}, Service: "users", Method: "DELETE", Path: "/users/*", Permissions: []string{"DELETE_USER"}, Priority: 10},
+		{Model: gorm.Model{ID: 2}, Service: "users", Method: "*", Path: "/users/**", Permissions: []string{"users:read", "users:list"}},
+		{Model: gorm.Model{ID: 3}, Service: "users", Method: "GET", Path: "/healthz", Public: true},
+		{Model: gorm.Model{ID: 4}, Service: "users", Method: "GET", Path: "broken"},
+		{Model: gorm.Model{ID: 5}, TenantID: "acme", Service: "users", Method: "GET", Path: "/users/*", Permissions: []string{"acme:users:read"}},
+	}}
+	svc := NewRBACService(&fakeUoW{roles: newFakeRoleRepo(), routes: routes})
+
+	tests := []struct {
+		tenant, service, method, path string
+		want                          []string // tələb olunan permission-lar, "public" və ya nil (uyğun gəlmir)
+	}{
+		{"", "users", "DELETE", "/users/7", []string{"DELETE_USER"}},
+		{"", "users", "GET", "/users/7?expand=roles", []string{"users:read", "users:list"}},
+		{"", "users", "GET", "/healthz", []string{"public"}},
+		{"", "users", "GET", "broken", nil},
+		{"", "orders", "GET", "/users/7", nil},
+		{"acme", "users", "GET", "/users/7", []string{"acme:users:read"}},
+		{"acme", "users", "DELETE", "/users/7", nil},
+	}
+	for _, tt := range tests {
+		rule, ok := svc.ResolveRoute(tt.tenant, tt.service, tt.method, tt.path)
+		var got []string
+		switch {
+		case !ok:
+		case rule.Public:
+			got = []string{"public"}
+		default:
+			got = rule.Required()
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ResolveRoute(%q, %q, %s %s) = %v, want %v", tt.tenant, tt.service, tt.method, tt.path, got, tt.want)
+		}
+	}
+
+	// Silinmiş route-lar yenidən yükləmədə cache-dən çıxarılır
+	routes.routes = routes.routes[:1]
+	if err := svc.ReloadCache(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := svc.ResolveRoute("acme", "users", "GET", "/users/7"); ok {
+		t.Error("ResolveRoute(acme) matched after its routes were deleted")
+	}
+}
+
+func TestConditionEnv_IgnoresSpoofedKeys(t *testing.T) {
+	roles := newFakeRoleRepo("sales_rep")
+	roles.scoped[1] = []model.RolePermission{
+		{Effect: "allow", Condition: "ip in 10.0.0.0/8", Permission: model.Permission{Name: "reports:read"}},
+		{Effect: "allow", Condition: "time between 08:00-18:00", Permission: model.Permission{Name: "deals:edit"}},
+		{Effect: "allow", Condition: `claims.department == "sales"`, Permission: model.Permission{Name: "audit:read"}},
+		{Effect: "allow", Condition: `resource.department == "sales"`, Permission: model.Permission{Name: "exports:run"}},
+	}
+	svc := NewRBACService(&fakeUoW{roles: roles})
+
+	claims := &jwtutil.Claims{Raw: map[string]any{"department": "hr"}}
+	spoofed := map[string]any{
+		"ip":       "10.1.2.3",
+		"time":     time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+		"claims":   map[string]any{"department": "sales"},
+		"resource": map[string]any{"department": "sales"},
+	}
+	env := ConditionEnv(claims, spoofed, "192.168.1.1", time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		perm    string
+		allowed bool
+	}{
+		{"reports:read", false}, // ip serverdən
+		{"deals:edit", false},   // time serverdən
+		{"audit:read", false},   // claims tokendən
+		{"exports:run", true},   // resource müştəridən
+	}
+	for _, tt := range tests {
+		if d := svc.Decide("", []string{"sales_rep"}, tt.perm, Resource{}, env); d.Allowed != tt.allowed {
+			t.Errorf("Decide(%s) with spoofed context = %v, want %v", tt.perm, d.Allowed, tt.allowed)
+		}
+	}
+}
+
+func TestRBACService_UserRolesIgnoreOtherTenants(t *testing.T) {
+	roles := newFakeRoleRepo("admin")
+	roles.roles = append(roles.roles, model.Role{Model: gorm.Model{ID: 2}, TenantID: "acme", Name: "admin"})
+	roles.perms[1] = []string{"*"}
+	roles.perms[2] = []string{"*"}
+	users := &fakeUserRepo{users: []model.User{
+		// role_id və user_roles başqa (default) tenant-ın admin roluna işarə edir
+		{Model: gorm.Model{ID: 7}, TenantID: "acme", RoleID: 1, Role: roles.roles[0], Roles: []model.Role{roles.roles[0]}},
+		{Model: gorm.Model{ID: 8}, TenantID: "acme", RoleID: 2, Role: roles.roles[1]},
+	}}
+	svc := NewRBACService(&fakeUoW{roles: roles, users: users})
+
+	if got := svc.RolesForUser("acme", "7", nil); len(got) != 0 {
+		t.Errorf("RolesForUser(acme, 7) = %v, want no roles from another tenant", got)
+	}
+	if got := svc.RolesForUser("acme", "8", nil); !reflect.DeepEqual(got, []string{"admin"}) {
+		t.Errorf("RolesForUser(acme, 8) = %v, want [admin]", got)
+	}
+}
+
+// go test -race ilə: yenidən yükləmələr paralel oxucularla və bir-biri ilə yarışmamalıdır
+func TestRBACService_MutateReloadsCache(t *testing.T) {
+	roles := newFakeRoleRepo("support")
+	roles.perms[1] = []string{"orders:read"}
+	svc := NewRBACService(&fakeUoW{roles: roles})
+
+	// Ləğv olunan icazə commit-dən dərhal sonra bu instansiyada qüvvədən düşür
+	err := svc.Mutate(&fakeUoW{roles: roles}, func(tx repository.UnitOfWork) error {
+		roles.perms[1] = nil
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.HasPermission("", "support", "orders:read") {
+		t.Error("revoked permission still effective after Mutate")
+	}
+
+	// Uğursuz tranzaksiyadan sonra cache yenilənmir
+	fail := errors.New("rollback")
+	err = svc.Mutate(&fakeUoW{roles: roles}, func(tx repository.UnitOfWork) error {
+		roles.perms[1] = []string{"orders:read"}
+		return fail
+	})
+	if !errors.Is(err, fail) {
+		t.Fatalf("Mutate() error = %v, want %v", err, fail)
+	}
+	if svc.HasPermission("", "support", "orders:read") {
+		t.Error("cache reloaded after a failed transaction")
+	}
+}
+
+func TestRBACService_ConcurrentReload(t *testing.T) {
+	roles := newFakeRoleRepo("support")
+	roles.perms[1] = []string{"tickets:*"}
+	svc := NewRBACService(&fakeUoW{roles: roles})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := svc.ReloadCache(); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if !svc.HasPermission("", "support", "tickets:read") {
+				t.Error("HasPermission(support, tickets:read) = false during reload")
+			}
+		}()
+	}
+	wg.Wait()
+}
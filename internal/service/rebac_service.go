@@ -0,0 +1,108 @@
+package service
+
+import (
+	"ms-authz/internal/domain/model"
+	"ms-authz/internal/domain/repository"
+	"ms-authz/pkg/rebac"
+)
+
+// ReBACService relationship tuple-ları (Zanzibar üslubunda) üzərində yoxlamalar aparır.
+// Tuple-lar cache-lənmir – hər yoxlama tenant-ın tuple-larını birbaşa DB-dən oxuyur.
+type ReBACService struct {
+	uow    repository.UnitOfWork
+	config *rebac.Config
+}
+
+func NewReBACService(uow repository.UnitOfWork, config *rebac.Config) *ReBACService {
+	if config == nil {
+		config = &rebac.Config{}
+	}
+	return &ReBACService{uow: uow, config: config}
+}
+
+// tupleReader RelationTupleRepository-ni rebac.TupleReader-ə uyğunlaşdırır
+type tupleReader struct {
+	repo repository.RelationTupleRepository
+}
+
+func (r tupleReader) Read(object rebac.Object, relation string) ([]rebac.Tuple, error) {
+	rows, err := r.repo.Read(object.Namespace, object.ID, relation)
+	if err != nil {
+		return nil, err
+	}
+	tuples := make([]rebac.Tuple, 0, len(rows))
+	for _, row := range rows {
+		tuples = append(tuples, tupleFromModel(row))
+	}
+	return tuples, nil
+}
+
+func (r tupleReader) ObjectIDs(namespace string) ([]string, error) {
+	return r.repo.ObjectIDs(namespace)
+}
+
+func tupleFromModel(row model.RelationTuple) rebac.Tuple {
+	return rebac.Tuple{
+		Object:   rebac.Object{Namespace: row.Namespace, ID: row.ObjectID},
+		Relation: row.Relation,
+		Subject: rebac.Subject{
+			Object:   rebac.Object{Namespace: row.SubjectNamespace, ID: row.SubjectID},
+			Relation: row.SubjectRelation,
+		},
+	}
+}
+
+func tupleToModel(t rebac.Tuple) *model.RelationTuple {
+	return &model.RelationTuple{
+		Namespace:        t.Object.Namespace,
+		ObjectID:         t.Object.ID,
+		Relation:         t.Relation,
+		SubjectNamespace: t.Subject.Namespace,
+		SubjectID:        t.Subject.ID,
+		SubjectRelation:  t.Subject.Relation,
+	}
+}
+
+func (s *ReBACService) engine(tenantID string) *rebac.Engine {
+	return rebac.NewEngine(s.config, tupleReader{repo: s.uow.ForTenant(tenantID).RelationTupleRepo()})
+}
+
+// Check subject-in tenantID tenant-ında object üzərində relation-a malik olduğunu yoxlayır
+func (s *ReBACService) Check(tenantID string, object rebac.Object, relation string, subject rebac.Subject) (bool, error) {
+	return s.engine(tenantID).Check(object, relation, subject)
+}
+
+// Expand object#relation-ın userset ağacını qaytarır
+func (s *ReBACService) Expand(tenantID string, object rebac.Object, relation string) (*rebac.Node, error) {
+	return s.engine(tenantID).Expand(object, relation)
+}
+
+// ListObjects namespace-də subject-in relation-a malik olduğu obyektləri qaytarır
+func (s *ReBACService) ListObjects(tenantID, namespace, relation string, subject rebac.Subject) ([]string, error) {
+	return s.engine(tenantID).ListObjects(namespace, relation, subject)
+}
+
+// Tuples obyektin birbaşa yazılmış tuple-larını qaytarır (relation boşdursa hamısını)
+func (s *ReBACService) Tuples(tenantID string, object rebac.Object, relation string) ([]rebac.Tuple, error) {
+	rows, err := s.uow.ForTenant(tenantID).RelationTupleRepo().Read(object.Namespace, object.ID, relation)
+	if err != nil {
+		return nil, err
+	}
+	tuples := make([]rebac.Tuple, 0, len(rows))
+	for _, row := range rows {
+		tuples = append(tuples, tupleFromModel(row))
+	}
+	return tuples, nil
+}
+
+// WriteTuple tuple-ı konfiqurasiyaya görə yoxlayıb yazır
+func (s *ReBACService) WriteTuple(tenantID string, t rebac.Tuple) error {
+	if err := s.config.ValidateTuple(t); err != nil {
+		return err
+	}
+	return s.uow.ForTenant(tenantID).RelationTupleRepo().Write(tupleToModel(t))
+}
+
+func (s *ReBACService) DeleteTuple(tenantID string, t rebac.Tuple) error {
+	return s.uow.ForTenant(tenantID).RelationTupleRepo().Delete(tupleToModel(t))
+}
@@ -0,0 +1,55 @@
+package service
+
+import "ms-authz/internal/domain/model"
+
+// roleHierarchy rol ID-si -> birbaşa valideyn rol ID-ləri
+type roleHierarchy map[uint][]uint
+
+func newRoleHierarchy(roles []model.Role) roleHierarchy {
+	h := make(roleHierarchy, len(roles))
+	for _, role := range roles {
+		parents := make([]uint, 0, len(role.Parents))
+		for _, p := range role.Parents {
+			if p.TenantID != role.TenantID {
+				continue // irsiyyət tenant sərhədini keçmir
+			}
+			parents = append(parents, p.ID)
+		}
+		h[role.ID] = parents
+	}
+	return h
+}
+
+func (h roleHierarchy) exists(roleID uint) bool {
+	_, ok := h[roleID]
+	return ok
+}
+
+// closure rolun özünü və bütün əcdadlarını qaytarır. DB-də dövr olsa belə sonsuz dövrəyə düşmür.
+func (h roleHierarchy) closure(roleID uint) []uint {
+	visited := map[uint]bool{roleID: true}
+	result := []uint{roleID}
+	stack := []uint{roleID}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, parent := range h[id] {
+			if !visited[parent] {
+				visited[parent] = true
+				result = append(result, parent)
+				stack = append(stack, parent)
+			}
+		}
+	}
+	return result
+}
+
+// inherits roleID-nin (dolayısı ilə) ancestorID-dən miras alıb-almadığını yoxlayır
+func (h roleHierarchy) inherits(roleID, ancestorID uint) bool {
+	for _, id := range h.closure(roleID) {
+		if id == ancestorID {
+			return true
+		}
+	}
+	return false
+}
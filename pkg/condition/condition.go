@@ -0,0 +1,331 @@
+// Package condition grant-lara bağlanan kiçik, CEL-ə bənzər şərt dilidir.
+//
+//	ip in 10.0.0.0/8
+//	time between 08:00-18:00
+//	claims.department == resource.department && !(claims.level < 3)
+//	claims.country in ["AZ", "GE"]
+//
+// Dəyişənlər Env-dən nöqtəli yolla oxunur; olmayan sahə null-dur. Rəqəmlə başlayan dırnaqsız söz
+// (CIDR, vaxt aralığı) string literal sayılır, digər string-lər dırnaq içində yazılır.
+//
+// Operatorlar: == != < <= > >= && || ! in between.
+// "in" sağ tərəfdə siyahı (elementə bərabərlik), map (açar) və ya CIDR (IP-nin şəbəkəyə daxil olması) qəbul edir.
+// "between" vaxtı (RFC3339 və ya time.Time) "HH:MM-HH:MM" gün aralığı ilə müqayisə edir; aralıq gecə yarısını keçə bilər.
+package condition
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSyntax = errors.New("invalid condition syntax")
+	ErrEval   = errors.New("condition evaluation failed")
+)
+
+// Env şərtin qiymətləndirildiyi atributlar, məs. {"ip": "10.1.2.3", "claims": {...}, "resource": {...}}
+type Env map[string]any
+
+// Expression kompilyasiya olunmuş şərt. Dəyişməzdir, paralel istifadə üçün təhlükəsizdir.
+type Expression struct {
+	source string
+	root   node
+}
+
+// Compile şərti bir dəfə parse edir
+func Compile(source string) (*Expression, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Eval şərti env üzərində qiymətləndirir. Nəticə bool olmalıdır, tip uyğunsuzluqları ErrEval qaytarır.
+func (e *Expression) Eval(env Env) (bool, error) {
+	v, err := eval(e.root, env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: result is %T, not bool", ErrEval, v)
+	}
+	return b, nil
+}
+
+func eval(n node, env Env) (any, error) {
+	switch n := n.(type) {
+	case literal:
+		return n.value, nil
+	case path:
+		return lookup(env, n.parts), nil
+	case list:
+		items := make([]any, 0, len(n.items))
+		for _, item := range n.items {
+			v, err := eval(item, env)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+	case unary:
+		v, err := evalBool(n.operand, env)
+		if err != nil {
+			return nil, err
+		}
+		return !v, nil
+	case binary:
+		return evalBinary(n, env)
+	}
+	return nil, fmt.Errorf("%w: unknown node %T", ErrEval, n)
+}
+
+func evalBool(n node, env Env) (bool, error) {
+	v, err := eval(n, env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: expected bool, got %T", ErrEval, v)
+	}
+	return b, nil
+}
+
+func evalBinary(n binary, env Env) (any, error) {
+	// && və || qısa dövrə ilə
+	switch n.op {
+	case "&&", "||":
+		left, err := evalBool(n.left, env)
+		if err != nil {
+			return nil, err
+		}
+		if (n.op == "&&" && !left) || (n.op == "||" && left) {
+			return left, nil
+		}
+		return evalBool(n.right, env)
+	}
+
+	left, err := eval(n.left, env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := eval(n.right, env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		return compare(n.op, left, right)
+	case "in":
+		return contains(left, right)
+	case "between":
+		return between(left, right)
+	}
+	return nil, fmt.Errorf("%w: unknown operator %q", ErrEval, n.op)
+}
+
+func lookup(env Env, parts []string) any {
+	var cur any = map[string]any(env)
+	for _, part := range parts {
+		switch m := cur.(type) {
+		case map[string]any:
+			cur = m[part]
+		case Env:
+			cur = m[part]
+		default:
+			return nil
+		}
+	}
+	return normalize(cur)
+}
+
+// JSON-dan gələn dəyərləri müqayisə üçün vahid tiplərə gətirir
+func normalize(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case []string:
+		items := make([]any, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		return items
+	}
+	return v
+}
+
+func equal(a, b any) bool {
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case bool, float64:
+		return a == b
+	case string:
+		bs, ok := b.(string)
+		return ok && a == bs
+	case time.Time:
+		bt, ok := toTime(b)
+		return ok && a.Equal(bt)
+	}
+	return false
+}
+
+func compare(op string, a, b any) (bool, error) {
+	var c int
+	switch a := a.(type) {
+	case float64:
+		bf, ok := b.(float64)
+		if !ok {
+			return false, fmt.Errorf("%w: cannot compare number with %T", ErrEval, b)
+		}
+		c = cmpOrdered(a, bf)
+	case string:
+		bs, ok := b.(string)
+		if !ok {
+			return false, fmt.Errorf("%w: cannot compare string with %T", ErrEval, b)
+		}
+		c = strings.Compare(a, bs)
+	case time.Time:
+		bt, ok := toTime(b)
+		if !ok {
+			return false, fmt.Errorf("%w: cannot compare time with %T", ErrEval, b)
+		}
+		c = a.Compare(bt)
+	case nil:
+		return false, nil // olmayan atribut heç nəyə bərabər və ondan böyük/kiçik deyil
+	default:
+		return false, fmt.Errorf("%w: %T is not comparable", ErrEval, a)
+	}
+
+	switch op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+func cmpOrdered(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func contains(item, collection any) (bool, error) {
+	switch coll := collection.(type) {
+	case nil:
+		return false, nil
+	case []any:
+		for _, el := range coll {
+			if ok, _ := contains(item, el); ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]any:
+		key, ok := item.(string)
+		if !ok {
+			return false, nil
+		}
+		_, found := coll[key]
+		return found, nil
+	case string:
+		if prefix, err := netip.ParsePrefix(coll); err == nil {
+			ip, ok := item.(string)
+			if !ok {
+				return false, nil
+			}
+			addr, err := netip.ParseAddr(ip)
+			return err == nil && prefix.Contains(addr.Unmap()), nil
+		}
+		return equal(item, coll), nil
+	}
+	return equal(item, collection), nil
+}
+
+// between vaxtın gün ərzindəki dəqiqəsini "HH:MM-HH:MM" aralığı ilə müqayisə edir (başlanğıc daxil, son xaric)
+func between(value, window any) (bool, error) {
+	w, ok := window.(string)
+	if !ok {
+		return false, fmt.Errorf("%w: between expects \"HH:MM-HH:MM\", got %T", ErrEval, window)
+	}
+	from, to, ok := strings.Cut(w, "-")
+	if !ok {
+		return false, fmt.Errorf("%w: between expects \"HH:MM-HH:MM\", got %q", ErrEval, w)
+	}
+	start, err := minuteOfDay(from)
+	if err != nil {
+		return false, err
+	}
+	end, err := minuteOfDay(to)
+	if err != nil {
+		return false, err
+	}
+
+	if value == nil {
+		return false, nil
+	}
+	t, ok := toTime(value)
+	if !ok {
+		return false, fmt.Errorf("%w: between expects a time, got %v", ErrEval, value)
+	}
+	m := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return m >= start && m < end, nil
+	}
+	return m >= start || m < end, nil // gecə yarısını keçən aralıq, məs. 22:00-06:00
+}
+
+func minuteOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid time of day %q", ErrEval, s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func toTime(v any) (time.Time, bool) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
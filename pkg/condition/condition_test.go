@@ -0,0 +1,96 @@
+package condition
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExpression_Eval(t *testing.T) {
+	env := Env{
+		"ip":   "10.20.30.40",
+		"time": time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC),
+		"claims": map[string]any{
+			"department": "sales",
+			"level":      float64(4),
+			"groups":     []any{"eng", "ops"},
+		},
+		"resource": map[string]any{"department": "sales", "owner": "alice"},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`ip in 10.0.0.0/8`, true},
+		{`ip in 192.168.0.0/16`, false},
+		{`ip in ["192.168.0.0/16", "10.20.0.0/16"]`, true},
+		{`time between 08:00-18:00`, true},
+		{`time between "18:00-08:00"`, false},
+		{`time between 22:00-10:00`, true}, // gecə yarısını keçən aralıq
+		{`claims.department == resource.department`, true},
+		{`claims.department != resource.department`, false},
+		{`claims.level >= 3 && claims.level < 5`, true},
+		{`!(claims.level > 3)`, false},
+		{`"eng" in claims.groups`, true},
+		{`claims.department in ["hr", "finance"]`, false},
+		{`"owner" in resource`, true},
+		{`claims.missing == null`, true},
+		{`claims.missing == "x" || resource.owner == 'alice'`, true},
+		{`claims.missing > 3`, false},
+		{`claims.missing in claims.other`, false},
+		{`true && (false || ip in 10.0.0.0/8)`, true},
+	}
+	for _, tt := range tests {
+		expr, err := Compile(tt.expr)
+		if err != nil {
+			t.Fatalf("Compile(%q) error = %v", tt.expr, err)
+		}
+		got, err := expr.Eval(env)
+		if err != nil {
+			t.Fatalf("Eval(%q) error = %v", tt.expr, err)
+		}
+		if got != tt.want {
+			t.Errorf("Eval(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestExpression_EvalErrors(t *testing.T) {
+	env := Env{"claims": map[string]any{"level": float64(4), "name": "bob"}, "time": "not a time"}
+	tests := []string{
+		`claims.level`,             // bool deyil
+		`claims.level > "3"`,       // tip uyğunsuzluğu
+		`claims.name && true`,      // string məntiqi operatorda
+		`time between 08:00-18:00`, // vaxt deyil
+		`claims.level between 25:00-26:00`,
+	}
+	for _, src := range tests {
+		expr, err := Compile(src)
+		if err != nil {
+			t.Fatalf("Compile(%q) error = %v", src, err)
+		}
+		if _, err := expr.Eval(env); !errors.Is(err, ErrEval) {
+			t.Errorf("Eval(%q) error = %v, want ErrEval", src, err)
+		}
+	}
+}
+
+func TestCompile_SyntaxErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`ip in`,
+		`claims.level >`,
+		`(true`,
+		`[1, 2`,
+		`claims. == 1`,
+		`"unterminated`,
+		`a = b`,
+		`true true`,
+	}
+	for _, src := range tests {
+		if _, err := Compile(src); !errors.Is(err, ErrSyntax) {
+			t.Errorf("Compile(%q) error = %v, want ErrSyntax", src, err)
+		}
+	}
+}
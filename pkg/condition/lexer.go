@@ -0,0 +1,126 @@
+package condition
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp      // == != < <= > >= && || !
+	tokPunct   // ( ) [ ] , .
+	tokKeyword // in between true false null
+)
+
+type token struct {
+	kind tokenKind
+	text string // operator/identifier mətni və ya string literalın dəyəri
+	num  float64
+	pos  int
+}
+
+var keywords = map[string]bool{"in": true, "between": true, "true": true, "false": true, "null": true}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			s, n, err := lexString(src[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%w at %d: %v", ErrSyntax, i, err)
+			}
+			tokens = append(tokens, token{kind: tokString, text: s, pos: i})
+			i += n
+		case c >= '0' && c <= '9':
+			// Rəqəmlə başlayan söz ya ədəddir, ya da dırnaqsız literal: 10.0.0.0/8, 08:00-18:00
+			j := i
+			for j < len(src) && isRawChar(rune(src[j])) {
+				j++
+			}
+			text := src[i:j]
+			if f, err := strconv.ParseFloat(text, 64); err == nil {
+				tokens = append(tokens, token{kind: tokNumber, num: f, text: text, pos: i})
+			} else {
+				tokens = append(tokens, token{kind: tokString, text: text, pos: i})
+			}
+			i = j
+		case c == '_' || unicode.IsLetter(c):
+			j := i
+			for j < len(src) && (src[j] == '_' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			word := src[i:j]
+			kind := tokIdent
+			if keywords[word] {
+				kind = tokKeyword
+			}
+			tokens = append(tokens, token{kind: kind, text: word, pos: i})
+			i = j
+		default:
+			if op := lexOp(src[i:]); op != "" {
+				tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+				i += len(op)
+				continue
+			}
+			if strings.ContainsRune("()[],.", c) {
+				tokens = append(tokens, token{kind: tokPunct, text: string(c), pos: i})
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("%w at %d: unexpected %q", ErrSyntax, i, c)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+func isRawChar(c rune) bool {
+	return c == '.' || c == ':' || c == '/' || c == '-' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+func lexOp(s string) string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!"} {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// lexString dırnaqlı literalı oxuyur, dəyəri və istehlak olunan uzunluğu qaytarır
+func lexString(s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case quote:
+			return b.String(), i + 1, nil
+		case '\\':
+			if i+1 >= len(s) {
+				return "", 0, fmt.Errorf("unterminated string")
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
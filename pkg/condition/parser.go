@@ -0,0 +1,197 @@
+package condition
+
+import "fmt"
+
+// AST düyünləri
+type node interface{}
+
+type literal struct{ value any }
+
+type path struct{ parts []string } // claims.department
+
+type list struct{ items []node }
+
+type unary struct {
+	op      string
+	operand node
+}
+
+type binary struct {
+	op          string
+	left, right node
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Qrammatika (prioritet aşağıdan yuxarı):
+//
+//	expr    := and ("||" and)*
+//	and     := not ("&&" not)*
+//	not     := "!" not | compare
+//	compare := primary (("==" | "!=" | "<" | "<=" | ">" | ">=" | "in" | "between") primary)?
+//	primary := literal | path | "(" expr ")" | "[" [expr ("," expr)*] "]"
+func parse(tokens []token) (node, error) {
+	p := &parser{tokens: tokens}
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) is(kind tokenKind, text string) bool {
+	t := p.peek()
+	return t.kind == kind && t.text == text
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	if !p.is(kind, text) {
+		t := p.peek()
+		return p.errorf(t, "expected %q", text)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return fmt.Errorf("%w at %d: %s", ErrSyntax, t.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(tokOp, "||") {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.is(tokOp, "&&") {
+		p.next()
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) not() (node, error) {
+	if p.is(tokOp, "!") {
+		p.next()
+		operand, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return unary{op: "!", operand: operand}, nil
+	}
+	return p.compare()
+}
+
+var compareOps = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+func (p *parser) compare() (node, error) {
+	left, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if (t.kind == tokOp && compareOps[t.text]) || (t.kind == tokKeyword && (t.text == "in" || t.text == "between")) {
+		p.next()
+		right, err := p.primary()
+		if err != nil {
+			return nil, err
+		}
+		return binary{op: t.text, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		return literal{value: t.text}, nil
+	case tokNumber:
+		return literal{value: t.num}, nil
+	case tokKeyword:
+		switch t.text {
+		case "true":
+			return literal{value: true}, nil
+		case "false":
+			return literal{value: false}, nil
+		case "null":
+			return literal{value: nil}, nil
+		}
+	case tokIdent:
+		parts := []string{t.text}
+		for p.is(tokPunct, ".") {
+			p.next()
+			id := p.next()
+			if id.kind != tokIdent && id.kind != tokKeyword {
+				return nil, p.errorf(id, "expected field name after %q", ".")
+			}
+			parts = append(parts, id.text)
+		}
+		return path{parts: parts}, nil
+	case tokPunct:
+		switch t.text {
+		case "(":
+			n, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(tokPunct, ")")
+		case "[":
+			var items []node
+			for !p.is(tokPunct, "]") {
+				if len(items) > 0 {
+					if err := p.expect(tokPunct, ","); err != nil {
+						return nil, err
+					}
+				}
+				item, err := p.or()
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			p.next()
+			return list{items: items}, nil
+		}
+	case tokEOF:
+		return nil, p.errorf(t, "unexpected end of expression")
+	}
+	return nil, p.errorf(t, "unexpected %q", t.text)
+}
@@ -0,0 +1,301 @@
+package jwtutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JWKS sənədindəki tək açar (RFC 7517)
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// JWKSKeyProvider açarları JWKS sənədindən (lokal fayl və ya HTTP URL) oxuyur.
+// Açarlar fon rejimində refreshInterval ilə yenilənir, naməlum kid gəldikdə isə
+// minRefreshInterval-dan tez olmamaq şərtilə dərhal yenidən yüklənir.
+type JWKSKeyProvider struct {
+	source             string
+	client             *http.Client
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]*VerificationKey
+	lastFetch time.Time
+
+	fetchMu sync.Mutex // eyni anda yalnız bir yükləmə
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// source: "https://idp/.well-known/jwks.json", "file:///etc/jwks.json" və ya sadəcə fayl yolu
+func NewJWKSKeyProvider(source string, refreshInterval, minRefreshInterval time.Duration) *JWKSKeyProvider {
+	p := &JWKSKeyProvider{
+		source:             source,
+		client:             &http.Client{Timeout: 10 * time.Second},
+		refreshInterval:    refreshInterval,
+		minRefreshInterval: minRefreshInterval,
+		keys:               make(map[string]*VerificationKey),
+		stop:               make(chan struct{}),
+	}
+
+	if err := p.Refresh(); err != nil {
+		// IdP müvəqqəti əlçatmaz ola bilər – naməlum kid gələndə yenidən cəhd olunacaq
+		log.Printf("⚠️ initial JWKS load from %s failed: %v", source, err)
+	}
+
+	if refreshInterval > 0 {
+		go p.refreshLoop()
+	}
+	return p
+}
+
+func (p *JWKSKeyProvider) GetPublicKey(kid string) (*VerificationKey, error) {
+	if key, ok := p.lookup(kid); ok {
+		return key, nil
+	}
+
+	// Naməlum kid – açarlar rotasiya olunmuş ola bilər
+	if err := p.refreshIfStale(); err != nil {
+		return nil, fmt.Errorf("unable to refresh JWKS: %w", err)
+	}
+
+	if key, ok := p.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown kid %q", kid)
+}
+
+// Refresh JWKS sənədini dərhal yenidən yükləyir
+func (p *JWKSKeyProvider) Refresh() error {
+	p.fetchMu.Lock()
+	defer p.fetchMu.Unlock()
+	return p.fetch()
+}
+
+// Stop fon yeniləməsini dayandırır
+func (p *JWKSKeyProvider) Stop() {
+	p.once.Do(func() { close(p.stop) })
+}
+
+func (p *JWKSKeyProvider) lookup(kid string) (*VerificationKey, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// Rate limit: son yükləmədən minRefreshInterval keçməyibsə, yenidən yükləmirik
+func (p *JWKSKeyProvider) refreshIfStale() error {
+	p.fetchMu.Lock()
+	defer p.fetchMu.Unlock()
+
+	p.mu.RLock()
+	last := p.lastFetch
+	p.mu.RUnlock()
+
+	if !last.IsZero() && time.Since(last) < p.minRefreshInterval {
+		return nil
+	}
+	return p.fetch()
+}
+
+func (p *JWKSKeyProvider) refreshLoop() {
+	ticker := time.NewTicker(p.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.Refresh(); err != nil {
+				log.Printf("⚠️ JWKS refresh from %s failed: %v", p.source, err)
+			}
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// fetchMu altında çağırılmalıdır
+func (p *JWKSKeyProvider) fetch() error {
+	// Uğursuz cəhd də rate limit-ə daxildir, əks halda IdP çökəndə hər sorğu onu vuracaq
+	p.mu.Lock()
+	p.lastFetch = time.Now()
+	p.mu.Unlock()
+
+	data, err := p.read()
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *JWKSKeyProvider) read() ([]byte, error) {
+	if !strings.HasPrefix(p.source, "http://") && !strings.HasPrefix(p.source, "https://") {
+		return os.ReadFile(strings.TrimPrefix(p.source, "file://"))
+	}
+
+	resp, err := p.client.Get(p.source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected JWKS response status: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+func parseJWKS(data []byte) (map[string]*VerificationKey, error) {
+	var set jwkSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS document: %w", err)
+	}
+
+	keys := make(map[string]*VerificationKey)
+	for _, k := range set.Keys {
+		if k.Kid == "" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		key, err := parseJWK(k)
+		if err != nil {
+			log.Printf("⚠️ skipping JWKS key %s: %v", k.Kid, err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS document contains no usable keys")
+	}
+	return keys, nil
+}
+
+// "alg" göstərilibsə, açar yalnız həmin alqoritmlə istifadə oluna bilər
+func parseJWK(k jwk) (*VerificationKey, error) {
+	var (
+		pub crypto.PublicKey
+		err error
+	)
+	switch k.Kty {
+	case "RSA":
+		pub, err = parseRSAJWK(k)
+	case "EC":
+		pub, err = parseECJWK(k)
+	case "OKP":
+		pub, err = parseOKPJWK(k)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if k.Alg != "" {
+		return NewVerificationKey(pub, k.Alg)
+	}
+	return NewVerificationKey(pub)
+}
+
+func parseRSAJWK(k jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+	if len(n) == 0 || len(e) == 0 {
+		return nil, errors.New("missing modulus or exponent")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+func parseECJWK(k jwk) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("invalid x coordinate: %w", err)
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("invalid y coordinate: %w", err)
+	}
+
+	pub := &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+	if !curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, errors.New("point is not on curve")
+	}
+	return pub, nil
+}
+
+func parseOKPJWK(k jwk) (ed25519.PublicKey, error) {
+	if k.Crv != "Ed25519" {
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("invalid x coordinate: %w", err)
+	}
+	if len(x) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid Ed25519 key size")
+	}
+	return ed25519.PublicKey(x), nil
+}
@@ -0,0 +1,158 @@
+package jwtutil
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func rsaJWK(t *testing.T, kid string) (jwk, *rsa.PublicKey) {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &priv.PublicKey
+	return jwk{
+		Kid: kid,
+		Kty: "RSA",
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}, pub
+}
+
+type jwksServer struct {
+	mu   sync.Mutex
+	set  jwkSet
+	hits int32
+}
+
+func (s *jwksServer) setKeys(keys ...jwk) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set = jwkSet{Keys: keys}
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&s.hits, 1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = json.NewEncoder(w).Encode(s.set)
+}
+
+func TestJWKSKeyProvider_GetPublicKey(t *testing.T) {
+	k1, pub1 := rsaJWK(t, "k1")
+	srv := &jwksServer{}
+	srv.setKeys(k1)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	p := NewJWKSKeyProvider(ts.URL, 0, time.Hour)
+	defer p.Stop()
+
+	got, err := p.GetPublicKey("k1")
+	if err != nil {
+		t.Fatalf("GetPublicKey() error = %v", err)
+	}
+	if rsaKey, ok := got.Key.(*rsa.PublicKey); !ok || rsaKey.N.Cmp(pub1.N) != 0 || rsaKey.E != pub1.E {
+		t.Errorf("GetPublicKey() returned a different key")
+	}
+	if hits := atomic.LoadInt32(&srv.hits); hits != 1 {
+		t.Errorf("expected 1 JWKS fetch, got %d", hits)
+	}
+}
+
+func TestJWKSKeyProvider_RefreshOnUnknownKid(t *testing.T) {
+	k1, _ := rsaJWK(t, "k1")
+	k2, pub2 := rsaJWK(t, "k2")
+	srv := &jwksServer{}
+	srv.setKeys(k1)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	p := NewJWKSKeyProvider(ts.URL, 0, 0)
+	defer p.Stop()
+
+	// Açar rotasiyası: IdP yeni açar dərc edir
+	srv.setKeys(k1, k2)
+
+	got, err := p.GetPublicKey("k2")
+	if err != nil {
+		t.Fatalf("GetPublicKey() error = %v", err)
+	}
+	if !pub2.Equal(got.Key) {
+		t.Errorf("GetPublicKey() returned a different key")
+	}
+}
+
+func TestJWKSKeyProvider_RateLimitsUnknownKid(t *testing.T) {
+	k1, _ := rsaJWK(t, "k1")
+	srv := &jwksServer{}
+	srv.setKeys(k1)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	p := NewJWKSKeyProvider(ts.URL, 0, time.Hour)
+	defer p.Stop()
+
+	for i := 0; i < 5; i++ {
+		if _, err := p.GetPublicKey("missing"); err == nil {
+			t.Fatal("expected error for unknown kid")
+		}
+	}
+	if hits := atomic.LoadInt32(&srv.hits); hits != 1 {
+		t.Errorf("expected unknown kid lookups to be rate limited, got %d fetches", hits)
+	}
+}
+
+func TestJWKSKeyProvider_PeriodicRefresh(t *testing.T) {
+	k1, _ := rsaJWK(t, "k1")
+	srv := &jwksServer{}
+	srv.setKeys(k1)
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	p := NewJWKSKeyProvider(ts.URL, 20*time.Millisecond, time.Hour)
+	defer p.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt32(&srv.hits) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected periodic refreshes, got %d fetches", atomic.LoadInt32(&srv.hits))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestJWKSKeyProvider_File(t *testing.T) {
+	k1, pub1 := rsaJWK(t, "k1")
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	data, _ := json.Marshal(jwkSet{Keys: []jwk{k1}})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, source := range []string{path, "file://" + path} {
+		p := NewJWKSKeyProvider(source, 0, time.Hour)
+		got, err := p.GetPublicKey("k1")
+		p.Stop()
+		if err != nil {
+			t.Fatalf("GetPublicKey(%s) error = %v", source, err)
+		}
+		if !pub1.Equal(got.Key) {
+			t.Errorf("GetPublicKey(%s) returned a different key", source)
+		}
+	}
+}
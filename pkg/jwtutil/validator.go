@@ -1,9 +1,15 @@
 package jwtutil
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/pem"
 	"errors"
@@ -16,13 +22,113 @@ import (
 )
 
 type Claims struct {
-	UserID string `json:"user_id"`
-	Role   string `json:"role"`
+	UserID   string   `json:"user_id"`
+	Role     string   `json:"role"`
+	Roles    []string `json:"roles,omitempty"`
+	TenantID string   `json:"tenant_id,omitempty"` // rolların aid olduğu tenant, boşdursa default tenant
 	jwt.RegisteredClaims
+
+	// Raw tokenin bütün claim-ləri (xüsusi claim-lər daxil), ABAC şərtləri üçün
+	Raw map[string]any `json:"-"`
+}
+
+func (c *Claims) UnmarshalJSON(data []byte) error {
+	type plain Claims
+	if err := json.Unmarshal(data, (*plain)(c)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &c.Raw)
+}
+
+// AllRoles role və roles claim-lərinin birləşməsini (təkrarsız, sıra saxlanılmaqla) qaytarır
+func (c *Claims) AllRoles() []string {
+	var roles []string
+	seen := make(map[string]bool, len(c.Roles)+1)
+	for _, r := range append([]string{c.Role}, c.Roles...) {
+		if r != "" && !seen[r] {
+			seen[r] = true
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
+
+// TokenID blacklist üçün tokenin identifikatoru: jti claim-i,
+// yoxdursa tokenin SHA-256 hash-i. Xam token heç yerdə saxlanılmır.
+func TokenID(claims *Claims, tokenStr string) string {
+	if claims != nil && claims.ID != "" {
+		return claims.ID
+	}
+	sum := sha256.Sum256([]byte(tokenStr))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// VerificationKey imza yoxlama açarı və bu açarla icazə verilən alqoritmlər
+type VerificationKey struct {
+	Key        crypto.PublicKey
+	Algorithms []string
 }
 
 type PublicKeyProvider interface {
-	GetPublicKey(kid string) (*rsa.PublicKey, error)
+	GetPublicKey(kid string) (*VerificationKey, error)
+}
+
+// NewVerificationKey açarın tipinə uyğun alqoritm siyahısını qurur.
+// algs verilibsə, siyahı onlarla daraldılır (məs. JWKS-dəki "alg" sahəsi).
+func NewVerificationKey(key crypto.PublicKey, algs ...string) (*VerificationKey, error) {
+	supported, err := algorithmsForKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := supported
+	if len(algs) > 0 {
+		allowed = nil
+		for _, alg := range algs {
+			if containsAlg(supported, alg) {
+				allowed = append(allowed, alg)
+			}
+		}
+		if len(allowed) == 0 {
+			return nil, fmt.Errorf("algorithms %v are not compatible with %T", algs, key)
+		}
+	}
+
+	return &VerificationKey{Key: key, Algorithms: allowed}, nil
+}
+
+// Allows alqoritmin bu açar üçün icazəli olub-olmadığını yoxlayır
+func (k *VerificationKey) Allows(alg string) bool {
+	return containsAlg(k.Algorithms, alg)
+}
+
+func algorithmsForKey(key crypto.PublicKey) ([]string, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}, nil
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return []string{"ES256"}, nil
+		case elliptic.P384():
+			return []string{"ES384"}, nil
+		case elliptic.P521():
+			return []string{"ES512"}, nil
+		}
+		return nil, fmt.Errorf("unsupported ECDSA curve %s", k.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return []string{"EdDSA"}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", key)
+}
+
+func containsAlg(algs []string, alg string) bool {
+	for _, a := range algs {
+		if a == alg {
+			return true
+		}
+	}
+	return false
 }
 
 func ParseTokenHeader(tokenStr string) (*Claims, string, error) {
@@ -51,9 +157,12 @@ func ParseTokenHeader(tokenStr string) (*Claims, string, error) {
 	return &Claims{}, header.Kid, nil
 }
 
-func VerifyToken(tokenStr string, pubKey *rsa.PublicKey) (*Claims, error) {
+func VerifyToken(tokenStr string, pubKey *VerificationKey) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (interface{}, error) {
-		return pubKey, nil
+		if !pubKey.Allows(t.Method.Alg()) {
+			return nil, fmt.Errorf("signing algorithm %s is not allowed for this key", t.Method.Alg())
+		}
+		return pubKey.Key, nil
 	})
 	if err != nil {
 		return nil, err
@@ -67,7 +176,7 @@ func VerifyToken(tokenStr string, pubKey *rsa.PublicKey) (*Claims, error) {
 
 type fileKeyProvider struct {
 	basePath string // Məsələn: /keys/public/
-	cache    map[string]*rsa.PublicKey
+	cache    map[string]*VerificationKey
 	mu       sync.RWMutex
 }
 
@@ -75,11 +184,11 @@ type fileKeyProvider struct {
 func NewFileKeyProvider(basePath string) PublicKeyProvider {
 	return &fileKeyProvider{
 		basePath: basePath,
-		cache:    make(map[string]*rsa.PublicKey),
+		cache:    make(map[string]*VerificationKey),
 	}
 }
 
-func (f *fileKeyProvider) GetPublicKey(kid string) (*rsa.PublicKey, error) {
+func (f *fileKeyProvider) GetPublicKey(kid string) (*VerificationKey, error) {
 	f.mu.RLock()
 	if key, ok := f.cache[kid]; ok {
 		f.mu.RUnlock()
@@ -103,17 +212,17 @@ func (f *fileKeyProvider) GetPublicKey(kid string) (*rsa.PublicKey, error) {
 		return nil, fmt.Errorf("unable to parse key: %w", err)
 	}
 
-	rsaKey, ok := pub.(*rsa.PublicKey)
-	if !ok {
-		return nil, errors.New("not RSA public key")
+	key, err := NewVerificationKey(pub)
+	if err != nil {
+		return nil, err
 	}
 
 	// Cache-ə yaz
 	f.mu.Lock()
-	f.cache[kid] = rsaKey
+	f.cache[kid] = key
 	f.mu.Unlock()
 
-	return rsaKey, nil
+	return key, nil
 }
 
 func (f *fileKeyProvider) PreloadKeys() {
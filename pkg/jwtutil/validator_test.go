@@ -0,0 +1,142 @@
+package jwtutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func signTestToken(t *testing.T, method jwt.SigningMethod, key crypto.PrivateKey) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, Claims{UserID: "42", Role: "admin"})
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestVerifyToken_Algorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	edPub, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name    string
+		method  jwt.SigningMethod
+		signKey crypto.PrivateKey
+		pubKey  crypto.PublicKey
+		algs    []string
+		wantErr bool
+	}{
+		{"RS256", jwt.SigningMethodRS256, rsaKey, &rsaKey.PublicKey, nil, false},
+		{"ES256", jwt.SigningMethodES256, p256, &p256.PublicKey, nil, false},
+		{"ES384", jwt.SigningMethodES384, p384, &p384.PublicKey, nil, false},
+		{"EdDSA", jwt.SigningMethodEdDSA, edPriv, edPub, nil, false},
+		{"RS512 not in allow-list", jwt.SigningMethodRS512, rsaKey, &rsaKey.PublicKey, []string{"RS256"}, true},
+		{"ES256 token for P-384 key", jwt.SigningMethodES256, p256, &p384.PublicKey, nil, true},
+		{"HS256 with RSA public key", jwt.SigningMethodHS256, x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey), &rsaKey.PublicKey, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := NewVerificationKey(tt.pubKey, tt.algs...)
+			if err != nil {
+				t.Fatalf("NewVerificationKey() error = %v", err)
+			}
+			claims, err := VerifyToken(signTestToken(t, tt.method, tt.signKey), key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && claims.UserID != "42" {
+				t.Errorf("VerifyToken() user_id = %q, want 42", claims.UserID)
+			}
+		})
+	}
+}
+
+func TestNewVerificationKey_IncompatibleAlgorithm(t *testing.T) {
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := NewVerificationKey(&p256.PublicKey, "RS256"); err == nil {
+		t.Error("expected error for RS256 on an ECDSA key")
+	}
+}
+
+func TestFileKeyProvider_ECDSA(t *testing.T) {
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, err := x509.MarshalPKIXPublicKey(&p256.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, "ec1.pem"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := NewFileKeyProvider(dir).GetPublicKey("ec1")
+	if err != nil {
+		t.Fatalf("GetPublicKey() error = %v", err)
+	}
+	if !key.Allows("ES256") || key.Allows("RS256") {
+		t.Errorf("unexpected algorithm allow-list %v", key.Algorithms)
+	}
+}
+
+func TestClaims_AllRoles(t *testing.T) {
+	tests := []struct {
+		claims Claims
+		want   []string
+	}{
+		{Claims{Role: "admin"}, []string{"admin"}},
+		{Claims{Roles: []string{"support", "billing"}}, []string{"support", "billing"}},
+		{Claims{Role: "support", Roles: []string{"billing", "support", ""}}, []string{"support", "billing"}},
+		{Claims{}, nil},
+	}
+	for _, tt := range tests {
+		if got := tt.claims.AllRoles(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AllRoles(%+v) = %v, want %v", tt.claims, got, tt.want)
+		}
+	}
+}
+
+func TestClaims_UnmarshalKeepsRaw(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"user_id": "42", "role": "admin", "department": "sales",
+	})
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vk, err := NewVerificationKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := VerifyToken(signed, vk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != "42" || claims.Role != "admin" {
+		t.Errorf("claims = %+v, want user 42 with role admin", claims)
+	}
+	if got := claims.Raw["department"]; got != "sales" {
+		t.Errorf("Raw[department] = %v, want sales", got)
+	}
+}
@@ -0,0 +1,202 @@
+package permission
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Permission adları ":" ilə ayrılmış seqmentlərdən ibarətdir, məs. "orders:items:read".
+// Qrant olunan pattern-də "*" tam bir seqmenti əvəz edir:
+//
+//	orders:read   yalnız "orders:read"
+//	*:read        "orders:read", "users:read" (tam iki seqment)
+//	orders:*      "orders:read", "orders:items:read" – sonuncu "*" bir və ya daha çox seqmentə uyğun gəlir
+//	*             hər şey
+//
+// Müqayisə böyük/kiçik hərfə həssas deyil.
+const (
+	Separator = ":"
+	Wildcard  = "*"
+)
+
+var ErrInvalidPattern = errors.New("invalid permission pattern")
+
+// Validate pattern-in düzgün olduğunu yoxlayır: boş seqment olmamalı,
+// "*" yalnız tam seqment kimi işlənməlidir ("orders:re*" qadağandır).
+func Validate(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("%w: empty", ErrInvalidPattern)
+	}
+	for _, seg := range strings.Split(pattern, Separator) {
+		if seg == "" {
+			return fmt.Errorf("%w: %q has an empty segment", ErrInvalidPattern, pattern)
+		}
+		if seg != Wildcard && strings.Contains(seg, Wildcard) {
+			return fmt.Errorf("%w: %q uses %q inside a segment", ErrInvalidPattern, pattern, Wildcard)
+		}
+	}
+	return nil
+}
+
+type node struct {
+	children map[string]*node
+	wildcard *node
+	pattern  string // bu node-da bitən pattern (orijinal yazılışla), yoxdursa boş
+}
+
+func newNode() *node {
+	return &node{children: make(map[string]*node)}
+}
+
+// Matcher pattern-lərdən qurulmuş seqment trie-si. Yaradıldıqdan sonra dəyişmir,
+// ona görə paralel oxunuş üçün təhlükəsizdir.
+type Matcher struct {
+	root     *node
+	patterns []string
+}
+
+// Compile pattern-lərdən Matcher qurur. Düzgün olmayan pattern-lər ötürülür
+// (onlar heç vaxt uyğun gəlmir) – yoxlama yazma zamanı Validate ilə aparılır.
+func Compile(patterns []string) *Matcher {
+	m := &Matcher{root: newNode()}
+	seen := make(map[string]bool, len(patterns))
+	for _, p := range patterns {
+		key := strings.ToLower(p)
+		if seen[key] || Validate(p) != nil {
+			continue
+		}
+		seen[key] = true
+		m.patterns = append(m.patterns, p)
+
+		n := m.root
+		for _, seg := range strings.Split(key, Separator) {
+			if seg == Wildcard {
+				if n.wildcard == nil {
+					n.wildcard = newNode()
+				}
+				n = n.wildcard
+				continue
+			}
+			child, ok := n.children[seg]
+			if !ok {
+				child = newNode()
+				n.children[seg] = child
+			}
+			n = child
+		}
+		n.pattern = p
+	}
+	return m
+}
+
+// Patterns Matcher-in qurulduğu (təkrarsız) pattern-ləri qaytarır
+func (m *Matcher) Patterns() []string {
+	return append([]string(nil), m.patterns...)
+}
+
+// Match permission-a uyğun gələn ən spesifik pattern-i qaytarır.
+//
+// Prioritet: soldan sağa ilk fərqli seqmentdə literal "*"-dan üstündür,
+// bərabər olduqda daha uzun pattern qalib gəlir. Beləliklə
+// "orders:items:read" > "orders:items:*" > "orders:*" > "*:read" > "*".
+func (m *Matcher) Match(permission string) (string, bool) {
+	if m == nil || permission == "" {
+		return "", false
+	}
+	segs := strings.Split(strings.ToLower(permission), Separator)
+
+	var best string
+	var bestRank []int
+	m.root.match(segs, nil, func(pattern string, rank []int) {
+		if best == "" || moreSpecific(rank, bestRank) {
+			best, bestRank = pattern, rank
+		}
+	})
+	return best, best != ""
+}
+
+// MatchAll permission-a uyğun gələn bütün pattern-ləri spesifiklik sırası ilə (ən spesifik birinci) qaytarır
+func (m *Matcher) MatchAll(permission string) []string {
+	if m == nil || permission == "" {
+		return nil
+	}
+	segs := strings.Split(strings.ToLower(permission), Separator)
+
+	type ranked struct {
+		pattern string
+		rank    []int
+	}
+	var found []ranked
+	m.root.match(segs, nil, func(pattern string, rank []int) {
+		found = append(found, ranked{pattern, rank})
+	})
+	sort.SliceStable(found, func(i, j int) bool { return moreSpecific(found[i].rank, found[j].rank) })
+
+	patterns := make([]string, len(found))
+	for i, f := range found {
+		patterns[i] = f.pattern
+	}
+	return patterns
+}
+
+// Allows permission-un hər hansı pattern-ə uyğun gəldiyini yoxlayır
+func (m *Matcher) Allows(permission string) bool {
+	_, ok := m.Match(permission)
+	return ok
+}
+
+// rank hər seqment üçün 2 (literal) və ya 1 (wildcard)
+func (n *node) match(segs []string, rank []int, found func(pattern string, rank []int)) {
+	if len(segs) == 0 {
+		if n.pattern != "" {
+			found(n.pattern, rank)
+		}
+		return
+	}
+
+	if child, ok := n.children[segs[0]]; ok {
+		child.match(segs[1:], appendRank(rank, 2), found)
+	}
+	if n.wildcard != nil {
+		w := n.wildcard
+		// Sonuncu "*" qalan bütün seqmentləri əhatə edir
+		if w.pattern != "" && len(segs) > 1 {
+			found(w.pattern, appendRank(rank, 1))
+		}
+		w.match(segs[1:], appendRank(rank, 1), found)
+	}
+}
+
+func appendRank(rank []int, v int) []int {
+	out := make([]int, len(rank), len(rank)+1)
+	copy(out, rank)
+	return append(out, v)
+}
+
+func moreSpecific(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] > b[i]
+		}
+	}
+	return len(a) > len(b)
+}
+
+// MoreSpecific a pattern-inin b-dən daha spesifik olduğunu Match ilə eyni qaydaya görə yoxlayır
+func MoreSpecific(a, b string) bool {
+	return moreSpecific(rankOf(a), rankOf(b))
+}
+
+func rankOf(pattern string) []int {
+	segs := strings.Split(pattern, Separator)
+	rank := make([]int, len(segs))
+	for i, seg := range segs {
+		rank[i] = 2
+		if seg == Wildcard {
+			rank[i] = 1
+		}
+	}
+	return rank
+}
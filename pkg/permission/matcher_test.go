@@ -0,0 +1,109 @@
+package permission
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		pattern string
+		valid   bool
+	}{
+		{"orders:read", true},
+		{"orders:*", true},
+		{"*:read", true},
+		{"*", true},
+		{"users.manage", true},
+		{"", false},
+		{"orders::read", false},
+		{"orders:", false},
+		{"orders:re*", false},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.pattern)
+		if (err == nil) != tt.valid {
+			t.Errorf("Validate(%q) error = %v, want valid = %v", tt.pattern, err, tt.valid)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidPattern) {
+			t.Errorf("Validate(%q) error must wrap ErrInvalidPattern", tt.pattern)
+		}
+	}
+}
+
+func TestMatcher_Match(t *testing.T) {
+	m := Compile([]string{
+		"orders:read",
+		"orders:*",
+		"orders:items:*",
+		"*:read",
+		"Billing:Invoices:Export",
+		"bad:pat*",
+	})
+
+	tests := []struct {
+		permission string
+		want       string
+	}{
+		{"orders:read", "orders:read"},                         // literal ən spesifikdir
+		{"orders:write", "orders:*"},                           // sonuncu wildcard
+		{"orders:items:read", "orders:items:*"},                // daha uzun literal prefiks qalib gəlir
+		{"orders:items:lines:delete", "orders:items:*"},        // sonuncu "*" bir neçə seqmentə uyğundur
+		{"users:read", "*:read"},                               // wildcard seqment
+		{"users:profile:read", ""},                             // ortadakı "*" yalnız bir seqmentdir
+		{"orders", ""},                                         // "orders:*" ən azı bir seqment tələb edir
+		{"BILLING:invoices:export", "Billing:Invoices:Export"}, // hərf həssaslığı yoxdur
+		{"bad:pattern", ""},                                    // düzgün olmayan pattern nəzərə alınmır
+		{"users:write", ""},
+	}
+	for _, tt := range tests {
+		got, ok := m.Match(tt.permission)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("Match(%q) = %q, %v; want %q", tt.permission, got, ok, tt.want)
+		}
+	}
+}
+
+func TestMatcher_MatchAll(t *testing.T) {
+	m := Compile([]string{"*", "orders:*", "*:read", "orders:read", "users:*"})
+
+	tests := []struct {
+		permission string
+		want       []string
+	}{
+		{"orders:read", []string{"orders:read", "orders:*", "*:read", "*"}},
+		{"orders:items:read", []string{"orders:*", "*"}},
+		{"users:write", []string{"users:*", "*"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := m.MatchAll(tt.permission); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("MatchAll(%q) = %v, want %v", tt.permission, got, tt.want)
+		}
+	}
+}
+
+func TestMatcher_Precedence(t *testing.T) {
+	// Soldakı literal sağdakından üstündür
+	m := Compile([]string{"*:read", "orders:*"})
+	if got, _ := m.Match("orders:read"); got != "orders:*" {
+		t.Errorf("Match() = %q, want %q", got, "orders:*")
+	}
+
+	all := Compile([]string{"*"})
+	for _, p := range []string{"orders", "orders:read", "a:b:c:d"} {
+		if !all.Allows(p) {
+			t.Errorf("%q must match %q", "*", p)
+		}
+	}
+
+	if !MoreSpecific("orders:*", "*:read") || MoreSpecific("*", "orders:*") {
+		t.Error("MoreSpecific() must follow Match precedence")
+	}
+
+	var empty *Matcher
+	if empty.Allows("orders:read") {
+		t.Error("nil matcher must not allow anything")
+	}
+}
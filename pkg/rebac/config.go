@@ -0,0 +1,152 @@
+package rebac
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidConfig    = errors.New("invalid relation config")
+	ErrUnknownNamespace = errors.New("unknown namespace")
+	ErrUnknownRelation  = errors.New("unknown relation")
+)
+
+// Config namespace-lərin və onların relation-larının təsviri:
+//
+//	{"namespaces": {
+//	  "user": {},
+//	  "folder": {"relations": {
+//	    "owner":  {},
+//	    "editor": {"union": [{"this": true}, {"computed_userset": "owner"}]}
+//	  }},
+//	  "document": {"relations": {
+//	    "parent": {},
+//	    "viewer": {"union": [{"this": true}, {"tuple_to_userset": {"tupleset": "parent", "computed_userset": "editor"}}]}
+//	  }}
+//	}}
+//
+// Burada document:1#viewer həm birbaşa yazılmış viewer-lər, həm də sənədin olduğu folder-in editor-ları deməkdir.
+type Config struct {
+	Namespaces map[string]Namespace `json:"namespaces"`
+}
+
+type Namespace struct {
+	Relations map[string]Relation `json:"relations"`
+}
+
+// Relation userset rewrite-larının birləşməsidir (union). Boş siyahı yalnız birbaşa tuple-lar ("this") deməkdir.
+type Relation struct {
+	Union []Rewrite `json:"union"`
+}
+
+// Rewrite sahələrindən yalnız biri verilməlidir
+type Rewrite struct {
+	// This relation-a birbaşa yazılmış tuple-lar
+	This bool `json:"this,omitempty"`
+	// ComputedUserset eyni obyektin başqa relation-ı, məs. editor-lar həm də viewer-dir
+	ComputedUserset string `json:"computed_userset,omitempty"`
+	// TupleToUserset tupleset relation-ı ilə bağlı obyektlərin relation-ı, məs. parent folder-in viewer-ləri
+	TupleToUserset *TupleToUserset `json:"tuple_to_userset,omitempty"`
+}
+
+type TupleToUserset struct {
+	Tupleset        string `json:"tupleset"`
+	ComputedUserset string `json:"computed_userset"`
+}
+
+func (r Relation) rewrites() []Rewrite {
+	if len(r.Union) == 0 {
+		return []Rewrite{{This: true}}
+	}
+	return r.Union
+}
+
+// Direct relation-a tuple yazıla bildiyini bildirir
+func (r Relation) Direct() bool {
+	for _, rw := range r.rewrites() {
+		if rw.This {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseConfig JSON konfiqurasiyanı oxuyur və yoxlayır
+func ParseConfig(data []byte) (*Config, error) {
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate rewrite-ların mövcud relation-lara istinad etdiyini yoxlayır.
+// tuple_to_userset-in computed_userset-i hədəf obyektin namespace-ində axtarılır, ona görə icra zamanı yoxlanılır.
+func (c *Config) Validate() error {
+	for nsName, ns := range c.Namespaces {
+		for relName, rel := range ns.Relations {
+			for _, rw := range rel.Union {
+				set := 0
+				if rw.This {
+					set++
+				}
+				if rw.ComputedUserset != "" {
+					set++
+					if _, ok := ns.Relations[rw.ComputedUserset]; !ok {
+						return fmt.Errorf("%w: %s#%s computes unknown relation %q", ErrInvalidConfig, nsName, relName, rw.ComputedUserset)
+					}
+				}
+				if rw.TupleToUserset != nil {
+					set++
+					if _, ok := ns.Relations[rw.TupleToUserset.Tupleset]; !ok {
+						return fmt.Errorf("%w: %s#%s uses unknown tupleset %q", ErrInvalidConfig, nsName, relName, rw.TupleToUserset.Tupleset)
+					}
+					if rw.TupleToUserset.ComputedUserset == "" {
+						return fmt.Errorf("%w: %s#%s tuple_to_userset needs computed_userset", ErrInvalidConfig, nsName, relName)
+					}
+				}
+				if set != 1 {
+					return fmt.Errorf("%w: %s#%s rewrite must set exactly one of this, computed_userset, tuple_to_userset", ErrInvalidConfig, nsName, relName)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Relation namespace-dəki relation təsvirini qaytarır
+func (c *Config) Relation(namespace, relation string) (Relation, error) {
+	ns, ok := c.Namespaces[namespace]
+	if !ok {
+		return Relation{}, fmt.Errorf("%w: %q", ErrUnknownNamespace, namespace)
+	}
+	rel, ok := ns.Relations[relation]
+	if !ok {
+		return Relation{}, fmt.Errorf("%w: %s#%s", ErrUnknownRelation, namespace, relation)
+	}
+	return rel, nil
+}
+
+// ValidateTuple tuple-ın yazıla biləcəyini yoxlayır: relation birbaşa tuple qəbul etməli,
+// subject-in namespace-i (userset-dirsə relation-ı da) konfiqurasiyada olmalıdır.
+func (c *Config) ValidateTuple(t Tuple) error {
+	rel, err := c.Relation(t.Object.Namespace, t.Relation)
+	if err != nil {
+		return err
+	}
+	if !rel.Direct() {
+		return fmt.Errorf("%w: %s#%s is computed and cannot be written", ErrInvalidTuple, t.Object.Namespace, t.Relation)
+	}
+	if t.Subject.IsUserset() {
+		_, err = c.Relation(t.Subject.Namespace, t.Subject.Relation)
+		return err
+	}
+	if _, ok := c.Namespaces[t.Subject.Namespace]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownNamespace, t.Subject.Namespace)
+	}
+	return nil
+}
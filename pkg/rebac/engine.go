@@ -0,0 +1,204 @@
+package rebac
+
+import (
+	"errors"
+	"sort"
+)
+
+var ErrDepthExceeded = errors.New("relation graph is too deep")
+
+// DefaultMaxDepth bir yoxlamada izlənən rewrite və userset keçidlərinin maksimum sayı
+const DefaultMaxDepth = 25
+
+// TupleReader birbaşa yazılmış tuple-ları oxuyur
+type TupleReader interface {
+	// Read obyektin relation üzrə bütün tuple-larını qaytarır
+	Read(object Object, relation string) ([]Tuple, error)
+	// ObjectIDs namespace-də ən azı bir tuple-ı olan obyektlərin ID-lərini qaytarır
+	ObjectIDs(namespace string) ([]string, error)
+}
+
+// Engine Config-dəki rewrite qaydalarına görə tuple-lar üzərində check, expand və list-objects aparır
+type Engine struct {
+	config   *Config
+	reader   TupleReader
+	maxDepth int
+}
+
+func NewEngine(config *Config, reader TupleReader) *Engine {
+	return &Engine{config: config, reader: reader, maxDepth: DefaultMaxDepth}
+}
+
+// Check subject-in object üzərində relation-a malik olduğunu yoxlayır
+func (e *Engine) Check(object Object, relation string, subject Subject) (bool, error) {
+	if _, err := e.config.Relation(object.Namespace, relation); err != nil {
+		return false, err
+	}
+	return e.check(object, relation, subject, 0, make(map[string]bool))
+}
+
+// visited dövrlərə qarşıdır: union-da eyni object#relation ikinci dəfə yeni nəticə vermir
+func (e *Engine) check(object Object, relation string, subject Subject, depth int, visited map[string]bool) (bool, error) {
+	if depth > e.maxDepth {
+		return false, ErrDepthExceeded
+	}
+	if subject.IsUserset() && subject.Object == object && subject.Relation == relation {
+		return true, nil
+	}
+	key := object.String() + "#" + relation
+	if visited[key] {
+		return false, nil
+	}
+	visited[key] = true
+
+	rel, err := e.config.Relation(object.Namespace, relation)
+	if err != nil {
+		return false, err
+	}
+
+	for _, rw := range rel.rewrites() {
+		var ok bool
+		switch {
+		case rw.This:
+			tuples, err := e.reader.Read(object, relation)
+			if err != nil {
+				return false, err
+			}
+			for _, t := range tuples {
+				if t.Subject == subject {
+					return true, nil
+				}
+				if !t.Subject.IsUserset() {
+					continue
+				}
+				if ok, err = e.check(t.Subject.Object, t.Subject.Relation, subject, depth+1, visited); err != nil || ok {
+					return ok, err
+				}
+			}
+		case rw.ComputedUserset != "":
+			ok, err = e.check(object, rw.ComputedUserset, subject, depth+1, visited)
+		case rw.TupleToUserset != nil:
+			ok, err = e.checkTupleToUserset(object, *rw.TupleToUserset, subject, depth, visited)
+		}
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func (e *Engine) checkTupleToUserset(object Object, ttu TupleToUserset, subject Subject, depth int, visited map[string]bool) (bool, error) {
+	tuples, err := e.reader.Read(object, ttu.Tupleset)
+	if err != nil {
+		return false, err
+	}
+	for _, t := range tuples {
+		// Hədəf namespace-də belə relation yoxdursa tuple nəzərə alınmır
+		if _, err := e.config.Relation(t.Subject.Namespace, ttu.ComputedUserset); err != nil {
+			continue
+		}
+		if ok, err := e.check(t.Subject.Object, ttu.ComputedUserset, subject, depth+1, visited); err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// Node Expand-in qaytardığı userset ağacının düyünü
+type Node struct {
+	Kind     string   `json:"kind"` // union, this, computed_userset, tuple_to_userset
+	Object   string   `json:"object"`
+	Relation string   `json:"relation"`
+	Subjects []string `json:"subjects,omitempty"` // this: birbaşa yazılmış subject-lər (userset-lər açılmır)
+	Children []*Node  `json:"children,omitempty"`
+}
+
+// Expand object#relation-ın rewrite qaydalarını ağac şəklində açır.
+// Birbaşa tuple-lardakı userset-lər (məs. group:eng#member) yarpaq kimi qalır – onları ayrıca expand etmək olar.
+func (e *Engine) Expand(object Object, relation string) (*Node, error) {
+	return e.expand(object, relation, 0, make(map[string]bool))
+}
+
+func (e *Engine) expand(object Object, relation string, depth int, visited map[string]bool) (*Node, error) {
+	if depth > e.maxDepth {
+		return nil, ErrDepthExceeded
+	}
+	rel, err := e.config.Relation(object.Namespace, relation)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &Node{Kind: "union", Object: object.String(), Relation: relation}
+	key := root.Object + "#" + relation
+	if visited[key] {
+		return root, nil
+	}
+	visited[key] = true
+	defer delete(visited, key)
+
+	for _, rw := range rel.rewrites() {
+		switch {
+		case rw.This:
+			tuples, err := e.reader.Read(object, relation)
+			if err != nil {
+				return nil, err
+			}
+			node := &Node{Kind: "this", Object: root.Object, Relation: relation, Subjects: []string{}}
+			for _, t := range tuples {
+				node.Subjects = append(node.Subjects, t.Subject.String())
+			}
+			root.Children = append(root.Children, node)
+		case rw.ComputedUserset != "":
+			child, err := e.expand(object, rw.ComputedUserset, depth+1, visited)
+			if err != nil {
+				return nil, err
+			}
+			root.Children = append(root.Children, &Node{
+				Kind: "computed_userset", Object: root.Object, Relation: rw.ComputedUserset, Children: []*Node{child},
+			})
+		case rw.TupleToUserset != nil:
+			ttu := rw.TupleToUserset
+			tuples, err := e.reader.Read(object, ttu.Tupleset)
+			if err != nil {
+				return nil, err
+			}
+			node := &Node{Kind: "tuple_to_userset", Object: root.Object, Relation: ttu.Tupleset}
+			for _, t := range tuples {
+				if _, err := e.config.Relation(t.Subject.Namespace, ttu.ComputedUserset); err != nil {
+					continue
+				}
+				child, err := e.expand(t.Subject.Object, ttu.ComputedUserset, depth+1, visited)
+				if err != nil {
+					return nil, err
+				}
+				node.Children = append(node.Children, child)
+			}
+			root.Children = append(root.Children, node)
+		}
+	}
+	return root, nil
+}
+
+// ListObjects namespace-də subject-in relation-a malik olduğu obyektlərin ID-lərini (sıralanmış) qaytarır
+func (e *Engine) ListObjects(namespace, relation string, subject Subject) ([]string, error) {
+	if _, err := e.config.Relation(namespace, relation); err != nil {
+		return nil, err
+	}
+	ids, err := e.reader.ObjectIDs(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []string{}
+	for _, id := range ids {
+		ok, err := e.Check(Object{Namespace: namespace, ID: id}, relation, subject)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, id)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
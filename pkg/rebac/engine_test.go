@@ -0,0 +1,238 @@
+package rebac
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const testConfig = `{"namespaces": {
+  "user": {},
+  "group": {"relations": {"member": {}}},
+  "folder": {"relations": {
+    "parent": {},
+    "owner":  {},
+    "editor": {"union": [{"this": true}, {"computed_userset": "owner"}]},
+    "viewer": {"union": [{"this": true}, {"computed_userset": "editor"},
+                         {"tuple_to_userset": {"tupleset": "parent", "computed_userset": "viewer"}}]}
+  }},
+  "document": {"relations": {
+    "parent": {},
+    "editor": {"union": [{"this": true}, {"tuple_to_userset": {"tupleset": "parent", "computed_userset": "editor"}}]},
+    "viewer": {"union": [{"this": true}, {"computed_userset": "editor"},
+                         {"tuple_to_userset": {"tupleset": "parent", "computed_userset": "viewer"}}]}
+  }}
+}}`
+
+// memoryReader tuple-ları yaddaşda saxlayır
+type memoryReader []Tuple
+
+func (m memoryReader) Read(object Object, relation string) ([]Tuple, error) {
+	var out []Tuple
+	for _, t := range m {
+		if t.Object == object && t.Relation == relation {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (m memoryReader) ObjectIDs(namespace string) ([]string, error) {
+	seen := map[string]bool{}
+	var ids []string
+	for _, t := range m {
+		if t.Object.Namespace == namespace && !seen[t.Object.ID] {
+			seen[t.Object.ID] = true
+			ids = append(ids, t.Object.ID)
+		}
+	}
+	return ids, nil
+}
+
+func mustTuples(t *testing.T, specs ...[3]string) memoryReader {
+	t.Helper()
+	var tuples memoryReader
+	for _, s := range specs {
+		obj, err := ParseObject(s[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sub, err := ParseSubject(s[2])
+		if err != nil {
+			t.Fatal(err)
+		}
+		tuples = append(tuples, Tuple{Object: obj, Relation: s[1], Subject: sub})
+	}
+	return tuples
+}
+
+func newTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	cfg, err := ParseConfig([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tuples := mustTuples(t,
+		[3]string{"document:123", "parent", "folder:9"},
+		[3]string{"document:456", "parent", "folder:10"},
+		[3]string{"folder:10", "parent", "folder:9"},
+		[3]string{"folder:9", "editor", "user:alice"},
+		[3]string{"folder:9", "owner", "user:olga"},
+		[3]string{"folder:9", "viewer", "group:eng#member"},
+		[3]string{"group:eng", "member", "user:bob"},
+		[3]string{"group:eng", "member", "group:ops#member"},
+		[3]string{"group:ops", "member", "user:carol"},
+		[3]string{"group:ops", "member", "group:eng#member"}, // dövr
+		[3]string{"document:789", "viewer", "user:dave"},
+	)
+	return NewEngine(cfg, tuples)
+}
+
+func TestEngine_Check(t *testing.T) {
+	e := newTestEngine(t)
+	tests := []struct {
+		object, relation, subject string
+		want                      bool
+	}{
+		{"document:123", "viewer", "user:alice", true}, // folder editor -> document editor -> viewer
+		{"document:123", "editor", "user:alice", true}, // tuple_to_userset
+		{"document:123", "editor", "user:olga", true},  // folder owner -> folder editor -> document editor
+		{"document:123", "editor", "user:bob", false},  // qrup yalnız viewer-dir
+		{"folder:9", "editor", "user:olga", true},      // computed_userset owner -> editor
+		{"document:123", "viewer", "user:bob", true},   // folder viewer userset group:eng#member
+		{"document:123", "viewer", "user:carol", true}, // iç-içə qrup
+		{"document:456", "viewer", "user:carol", true}, // iki səviyyəli folder parent
+		{"document:123", "viewer", "user:dave", false},
+		{"document:789", "viewer", "user:dave", true},
+		{"document:789", "editor", "user:dave", false},
+		{"group:eng", "member", "user:mallory", false},   // dövr sonsuz axtarışa səbəb olmur
+		{"folder:9", "viewer", "group:eng#member", true}, // userset subject
+	}
+	for _, tt := range tests {
+		obj, _ := ParseObject(tt.object)
+		sub, _ := ParseSubject(tt.subject)
+		got, err := e.Check(obj, tt.relation, sub)
+		if err != nil {
+			t.Fatalf("Check(%s#%s@%s) error = %v", tt.object, tt.relation, tt.subject, err)
+		}
+		if got != tt.want {
+			t.Errorf("Check(%s#%s@%s) = %v, want %v", tt.object, tt.relation, tt.subject, got, tt.want)
+		}
+	}
+
+	if _, err := e.Check(Object{"document", "1"}, "owner", Subject{Object: Object{"user", "a"}}); !errors.Is(err, ErrUnknownRelation) {
+		t.Errorf("Check() unknown relation error = %v", err)
+	}
+}
+
+func TestEngine_ListObjects(t *testing.T) {
+	e := newTestEngine(t)
+	tests := []struct {
+		subject string
+		want    []string
+	}{
+		{"user:alice", []string{"123", "456"}},
+		{"user:carol", []string{"123", "456"}},
+		{"user:dave", []string{"789"}},
+		{"user:nobody", []string{}},
+	}
+	for _, tt := range tests {
+		sub, _ := ParseSubject(tt.subject)
+		got, err := e.ListObjects("document", "viewer", sub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ListObjects(document, viewer, %s) = %v, want %v", tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestEngine_Expand(t *testing.T) {
+	e := newTestEngine(t)
+	tree, err := e.Expand(Object{"folder", "9"}, "viewer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Kind != "union" || len(tree.Children) != 3 {
+		t.Fatalf("Expand() = %+v, want union of 3 rewrites", tree)
+	}
+	if got := tree.Children[0].Subjects; !reflect.DeepEqual(got, []string{"group:eng#member"}) {
+		t.Errorf("this subjects = %v", got)
+	}
+	editor := tree.Children[1].Children[0] // computed_userset editor -> union
+	if got := editor.Children[0].Subjects; !reflect.DeepEqual(got, []string{"user:alice"}) {
+		t.Errorf("editor subjects = %v", got)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  string
+	}{
+		{"unknown computed relation", `{"namespaces": {"doc": {"relations": {"viewer": {"union": [{"computed_userset": "editor"}]}}}}}`},
+		{"unknown tupleset", `{"namespaces": {"doc": {"relations": {"viewer": {"union": [{"tuple_to_userset": {"tupleset": "parent", "computed_userset": "viewer"}}]}}}}}`},
+		{"empty rewrite", `{"namespaces": {"doc": {"relations": {"viewer": {"union": [{}]}}}}}`},
+		{"two rewrites in one", `{"namespaces": {"doc": {"relations": {"owner": {}, "viewer": {"union": [{"this": true, "computed_userset": "owner"}]}}}}}`},
+	}
+	for _, tt := range tests {
+		if _, err := ParseConfig([]byte(tt.cfg)); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: ParseConfig() error = %v, want ErrInvalidConfig", tt.name, err)
+		}
+	}
+}
+
+func TestConfig_ValidateTuple(t *testing.T) {
+	cfg, err := ParseConfig([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tuple   [3]string
+		wantErr error
+	}{
+		{[3]string{"document:1", "viewer", "user:alice"}, nil},
+		{[3]string{"folder:1", "viewer", "group:eng#member"}, nil},
+		{[3]string{"document:1", "owner", "user:alice"}, ErrUnknownRelation},
+		{[3]string{"invoice:1", "viewer", "user:alice"}, ErrUnknownNamespace},
+		{[3]string{"document:1", "viewer", "robot:r2"}, ErrUnknownNamespace},
+		{[3]string{"folder:1", "viewer", "group:eng#admin"}, ErrUnknownRelation},
+	}
+	for _, tt := range tests {
+		tuple := mustTuples(t, tt.tuple)[0]
+		if err := cfg.ValidateTuple(tuple); !errors.Is(err, tt.wantErr) {
+			t.Errorf("ValidateTuple(%s) error = %v, want %v", tuple, err, tt.wantErr)
+		}
+	}
+
+	computed := Config{Namespaces: map[string]Namespace{
+		"user": {},
+		"doc":  {Relations: map[string]Relation{"owner": {}, "editor": {Union: []Rewrite{{ComputedUserset: "owner"}}}}},
+	}}
+	tuple := mustTuples(t, [3]string{"doc:1", "editor", "user:a"})[0]
+	if err := computed.ValidateTuple(tuple); !errors.Is(err, ErrInvalidTuple) {
+		t.Errorf("ValidateTuple(computed relation) error = %v, want ErrInvalidTuple", err)
+	}
+}
+
+func TestParseSubject(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Subject
+		wantErr bool
+	}{
+		{"user:alice", Subject{Object: Object{"user", "alice"}}, false},
+		{"group:eng#member", Subject{Object: Object{"group", "eng"}, Relation: "member"}, false},
+		{"file:tenant/42:a.txt", Subject{Object: Object{"file", "tenant/42:a.txt"}}, false},
+		{"alice", Subject{}, true},
+		{"group:eng#", Subject{}, true},
+		{":eng", Subject{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSubject(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSubject(%q) = %+v, %v", tt.in, got, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package rebac
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidTuple = errors.New("invalid relation tuple")
+
+// Object "namespace:id" formasında obyekt, məs. "document:123"
+type Object struct {
+	Namespace string
+	ID        string
+}
+
+func (o Object) String() string {
+	return o.Namespace + ":" + o.ID
+}
+
+// Subject ya birbaşa obyekt ("user:alice"), ya da userset-dir ("group:eng#member" – eng qrupunun bütün üzvləri)
+type Subject struct {
+	Object
+	Relation string
+}
+
+func (s Subject) IsUserset() bool {
+	return s.Relation != ""
+}
+
+func (s Subject) String() string {
+	if s.IsUserset() {
+		return s.Object.String() + "#" + s.Relation
+	}
+	return s.Object.String()
+}
+
+// Tuple "object#relation@subject" əlaqəsi, məs. document:123#parent@folder:9
+type Tuple struct {
+	Object   Object
+	Relation string
+	Subject  Subject
+}
+
+func (t Tuple) String() string {
+	return t.Object.String() + "#" + t.Relation + "@" + t.Subject.String()
+}
+
+// ParseObject "namespace:id" sətrini oxuyur. ID-nin özündə ":" ola bilər, "#" isə ola bilməz.
+func ParseObject(s string) (Object, error) {
+	ns, id, ok := strings.Cut(s, ":")
+	if !ok || ns == "" || id == "" || strings.Contains(s, "#") {
+		return Object{}, fmt.Errorf("%w: object %q must be namespace:id", ErrInvalidTuple, s)
+	}
+	return Object{Namespace: ns, ID: id}, nil
+}
+
+// ParseSubject "namespace:id" və ya "namespace:id#relation" sətrini oxuyur
+func ParseSubject(s string) (Subject, error) {
+	obj, rel, hasRel := strings.Cut(s, "#")
+	if hasRel && rel == "" {
+		return Subject{}, fmt.Errorf("%w: subject %q has an empty relation", ErrInvalidTuple, s)
+	}
+	o, err := ParseObject(obj)
+	if err != nil {
+		return Subject{}, fmt.Errorf("%w: subject %q must be namespace:id or namespace:id#relation", ErrInvalidTuple, s)
+	}
+	return Subject{Object: o, Relation: rel}, nil
+}
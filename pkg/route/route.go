@@ -0,0 +1,184 @@
+// Package route HTTP metod və path-ı tələb olunan permission-a uyğunlaşdırır.
+//
+// Path pattern-i "/" ilə ayrılmış seqmentlərdir: "*" tam bir seqmenti, sonuncu "**" isə
+// qalan bütün seqmentləri (sıfır və ya daha çox) əvəz edir:
+//
+//	/api/orders         yalnız "/api/orders"
+//	/api/orders/*       "/api/orders/7", amma "/api/orders/7/items" yox
+//	/api/orders/**      "/api/orders", "/api/orders/7/items"
+//
+// Qaydalar verildiyi sıra ilə yoxlanılır, ilk uyğun gələn qalib gəlir.
+package route
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+var (
+	ErrInvalidRule = errors.New("invalid route rule")
+	ErrInvalidPath = errors.New("invalid request path")
+)
+
+const (
+	AnyMethod   = "*"
+	anySegment  = "*"
+	anySegments = "**"
+)
+
+// Rule bir route-un tələb etdiyi icazə(lər).
+// Public route token tələb etmir; heç bir permission verilməyibsə etibarlı token kifayətdir.
+type Rule struct {
+	Method      string   `json:"method"` // GET, POST, ... və ya "*" (boş – "*")
+	Path        string   `json:"path"`
+	Permission  string   `json:"permission,omitempty"`
+	Permissions []string `json:"permissions,omitempty"` // Permission-a əlavə olaraq hamısı tələb olunur
+	Public      bool     `json:"public,omitempty"`
+}
+
+// Required qaydanın tələb etdiyi bütün permission-lar (Permission birinci)
+func (r Rule) Required() []string {
+	if r.Permission == "" {
+		return r.Permissions
+	}
+	return append([]string{r.Permission}, r.Permissions...)
+}
+
+type compiledRule struct {
+	Rule
+	segments []string
+}
+
+// Table kompilyasiya olunmuş qaydalar. Dəyişməzdir, paralel istifadə üçün təhlükəsizdir.
+type Table struct {
+	rules []compiledRule
+}
+
+// Compile qaydaları yoxlayır və Table qurur
+func Compile(rules []Rule) (*Table, error) {
+	t := &Table{rules: make([]compiledRule, 0, len(rules))}
+	for i, r := range rules {
+		if err := Validate(r); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		r.Method = normalizeMethod(r.Method)
+		t.rules = append(t.rules, compiledRule{Rule: r, segments: split(r.Path)})
+	}
+	return t, nil
+}
+
+// Validate qaydanın path pattern-ini və permission/public uyğunluğunu yoxlayır
+func Validate(r Rule) error {
+	if !strings.HasPrefix(r.Path, "/") {
+		return fmt.Errorf("%w: path %q must start with /", ErrInvalidRule, r.Path)
+	}
+	segs := split(r.Path)
+	for i, seg := range segs {
+		if seg == anySegments && i != len(segs)-1 {
+			return fmt.Errorf("%w: %q may only be the last segment of %q", ErrInvalidRule, anySegments, r.Path)
+		}
+		if seg != anySegment && seg != anySegments && strings.Contains(seg, "*") {
+			return fmt.Errorf("%w: %q uses * inside a segment", ErrInvalidRule, r.Path)
+		}
+	}
+	for _, perm := range r.Permissions {
+		if strings.TrimSpace(perm) == "" {
+			return fmt.Errorf("%w: %q has an empty permission", ErrInvalidRule, r.Path)
+		}
+	}
+	if r.Public && len(r.Required()) > 0 {
+		return fmt.Errorf("%w: public route %q cannot require a permission", ErrInvalidRule, r.Path)
+	}
+	return nil
+}
+
+// Rules Table-in qurulduğu qaydaları sıra ilə qaytarır
+func (t *Table) Rules() []Rule {
+	rules := make([]Rule, len(t.rules))
+	for i, r := range t.rules {
+		rules[i] = r.Rule
+	}
+	return rules
+}
+
+// Match metod və path-a uyğun gələn ilk qaydanı qaytarır. Path əvvəlcə CleanPath ilə normallaşdırılır,
+// normallaşdırıla bilməyən path heç bir qaydaya uyğun gəlmir.
+func (t *Table) Match(method, rawPath string) (Rule, bool) {
+	if t == nil {
+		return Rule{}, false
+	}
+	p, err := CleanPath(rawPath)
+	if err != nil {
+		return Rule{}, false
+	}
+	method = normalizeMethod(method)
+	segs := split(p)
+	for _, r := range t.rules {
+		if (r.Method == AnyMethod || r.Method == method) && matchSegments(r.segments, segs) {
+			return r.Rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// CleanPath sorğunun path-ını upstream-in görəcəyi formaya gətirir: query string atılır, percent-encoding açılır
+// və path.Clean tətbiq olunur, əks halda "/public/../admin" public qaydaya uyğun gəlib upstream-də "/admin" kimi
+// açılardı. Bundan sonra da ".." seqmenti qalan (və ya ikinci dekodda yaranan, məs. %252e%252e) path rədd edilir;
+// adında ".." olan fayl ("/files/report..v2") seqment olmadığı üçün keçir.
+func CleanPath(rawPath string) (string, error) {
+	if i := strings.IndexAny(rawPath, "?#"); i >= 0 {
+		rawPath = rawPath[:i]
+	}
+	decoded, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+	cleaned := path.Clean("/" + decoded)
+	if again, err := url.PathUnescape(cleaned); err == nil && hasDotDotSegment(again) || hasDotDotSegment(cleaned) {
+		return "", fmt.Errorf("%w: %q contains ..", ErrInvalidPath, rawPath)
+	}
+	return cleaned, nil
+}
+
+func hasDotDotSegment(p string) bool {
+	for _, seg := range strings.Split(p, "/") {
+		if seg == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, segs []string) bool {
+	for i, p := range pattern {
+		if p == anySegments {
+			return true
+		}
+		if i >= len(segs) || (p != anySegment && p != segs[i]) {
+			return false
+		}
+	}
+	return len(pattern) == len(segs)
+}
+
+func normalizeMethod(method string) string {
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method == "" {
+		return AnyMethod
+	}
+	return method
+}
+
+// Boş seqmentlər (təkrarlanan və ya sondakı "/") nəzərə alınmır
+func split(path string) []string {
+	var segs []string
+	for _, seg := range strings.Split(path, "/") {
+		if seg != "" {
+			segs = append(segs, seg)
+		}
+	}
+	return segs
+}
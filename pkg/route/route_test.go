@@ -0,0 +1,128 @@
+package route
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTable_Match(t *testing.T) {
+	table, err := Compile([]Rule{
+		{Method: "GET", Path: "/healthz", Public: true},
+		{Method: "GET", Path: "/api/orders/*", Permission: "orders:read"},
+		{Method: "delete", Path: "/api/orders/*", Permission: "orders:delete"},
+		{Method: "*", Path: "/api/orders/**", Permission: "orders:*"},
+		{Path: "/api/me"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method, path string
+		want         string // permission, "public", "token" və ya "" (uyğun qayda yoxdur)
+	}{
+		{"GET", "/healthz", "public"},
+		{"POST", "/healthz", ""},
+		{"GET", "/api/orders/7", "orders:read"},
+		{"GET", "/api/orders/7?expand=items", "orders:read"},
+		{"DELETE", "/api/orders/7", "orders:delete"},
+		{"PUT", "/api/orders/7", "orders:*"},
+		{"GET", "/api/orders/7/items", "orders:*"},
+		{"GET", "/api/orders", "orders:*"},
+		{"GET", "//api//me/", "token"},
+		{"GET", "/api/users", ""},
+	}
+	for _, tt := range tests {
+		r, ok := table.Match(tt.method, tt.path)
+		got := r.Permission
+		switch {
+		case !ok:
+			got = ""
+		case r.Public:
+			got = "public"
+		case r.Permission == "":
+			got = "token"
+		}
+		if got != tt.want {
+			t.Errorf("Match(%s %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	tests := []Rule{
+		{Path: "api/orders"},
+		{Path: "/api/**/items"},
+		{Path: "/api/ord*"},
+		{Path: "/public", Public: true, Permission: "x:read"},
+		{Path: "/public", Public: true, Permissions: []string{"x:read"}},
+		{Path: "/api/orders", Permissions: []string{"orders:read", " "}},
+	}
+	for _, r := range tests {
+		if _, err := Compile([]Rule{r}); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("Compile(%+v) error = %v, want ErrInvalidRule", r, err)
+		}
+	}
+}
+
+func TestRule_Required(t *testing.T) {
+	tests := []struct {
+		rule Rule
+		want []string
+	}{
+		{Rule{Path: "/api/me"}, nil},
+		{Rule{Path: "/a", Permission: "orders:read"}, []string{"orders:read"}},
+		{Rule{Path: "/a", Permissions: []string{"orders:read", "billing:read"}}, []string{"orders:read", "billing:read"}},
+		{Rule{Path: "/a", Permission: "orders:read", Permissions: []string{"billing:read"}}, []string{"orders:read", "billing:read"}},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Required(); !slices.Equal(got, tt.want) {
+			t.Errorf("%+v.Required() = %v, want %v", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestTable_MatchTraversal(t *testing.T) {
+	table, err := Compile([]Rule{
+		{Method: "GET", Path: "/public/**", Public: true},
+		{Method: "GET", Path: "/admin/**", Permission: "admin:read"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string // permission, "public" və ya "" (rədd)
+	}{
+		{"/public/docs/../index.html", "public"},
+		{"/public/../admin/users", "admin:read"},
+		{"/public/%2e%2e/admin/users", "admin:read"},
+		{"/public/%2E%2E%2Fadmin/users", "admin:read"},
+		{"/public/..%2fadmin/users?x=1", "admin:read"},
+		{"/public/%252e%252e/admin/users", ""},
+		{"/public/%2e%2e%252fadmin/users", ""},
+		{"/public/a..b", "public"},
+		{"/public/files/report..v2", "public"},
+		{"/public/files/report%2e%2ev2", "public"},
+		{"/public/%zz", ""},
+	}
+	for _, tt := range tests {
+		r, ok := table.Match("GET", tt.path)
+		got := r.Permission
+		switch {
+		case !ok:
+			got = ""
+		case r.Public:
+			got = "public"
+		}
+		if got != tt.want {
+			t.Errorf("Match(GET %s) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	if _, err := CleanPath("/public/%2e%2e%252f"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("CleanPath() error = %v, want ErrInvalidPath", err)
+	}
+}